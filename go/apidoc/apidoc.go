@@ -0,0 +1,184 @@
+// Package apidoc 记录路由的方法和参数说明，并以OPTIONS响应提供给客户端
+// 作者: KO
+// 创建时间: 2026-10-15
+// 修改时间: 2026-10-15
+package apidoc
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+// Param 端点的一个参数
+type Param struct {
+	Name        string `json:"name"`               // 参数名
+	In          string `json:"in"`                 // 参数位置（path、query、form、header、body）
+	Required    bool   `json:"required,omitempty"` // 是否必需
+	Description string `json:"description"`        // 参数说明
+}
+
+// Endpoint 路由上一个方法的说明
+type Endpoint struct {
+	Method      string  `json:"method"`           // HTTP方法
+	Description string  `json:"description"`      // 端点说明
+	Params      []Param `json:"params,omitempty"` // 参数
+}
+
+// Description OPTIONS响应的内容
+type Description struct {
+	Path      string     `json:"path"`      // 路由路径
+	Methods   []string   `json:"methods"`   // 允许的方法（与Allow响应头一致）
+	Endpoints []Endpoint `json:"endpoints"` // 各方法的说明
+}
+
+// Registry 路由说明的注册表，键为完整的路由路径（如/api/s3/download/:key）
+type Registry struct {
+	endpoints map[string][]Endpoint
+}
+
+// NewRegistry 创建空的路由说明注册表
+// 返回值:
+//
+//	*Registry: 注册表
+func NewRegistry() *Registry {
+	return &Registry{endpoints: make(map[string][]Endpoint)}
+}
+
+// Handle 在分组上注册路由，并记录该路由的说明
+// 参数:
+//
+//	g: 路由分组
+//	method: HTTP方法
+//	path: 相对于分组的路径
+//	h: 处理函数
+//	description: 端点说明
+//	params: 参数说明
+//
+// 返回值:
+//
+//	*echo.Route: 注册的路由
+func (r *Registry) Handle(g *echo.Group, method, path string, h echo.HandlerFunc, description string, params ...Param) *echo.Route {
+	route := g.Add(method, path, h)
+	r.endpoints[route.Path] = append(r.endpoints[route.Path], Endpoint{
+		Method:      method,
+		Description: description,
+		Params:      params,
+	})
+	return route
+}
+
+// Middleware 处理非CORS预检的OPTIONS请求
+// 路由匹配但没有注册OPTIONS处理函数时，由路由器给出的允许方法生成Allow响应头，
+// 并以JSON返回该路由各方法的说明；CORS预检请求（带Access-Control-Request-Method）和未登记的路由不受影响
+// 返回值:
+//
+//	echo.MiddlewareFunc: Echo中间件
+func (r *Registry) Middleware() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if !IsDiscoveryRequest(c.Request()) {
+				return next(c)
+			}
+
+			allow, _ := c.Get(echo.ContextKeyHeaderAllow).(string)
+			endpoints, ok := r.endpoints[c.Path()]
+			if allow == "" || !ok {
+				return next(c)
+			}
+
+			c.Response().Header().Set(echo.HeaderAllow, allow)
+			return c.JSON(http.StatusOK, Description{
+				Path:      c.Path(),
+				Methods:   strings.Split(allow, ", "),
+				Endpoints: endpoints,
+			})
+		}
+	}
+}
+
+// IsDiscoveryRequest 判断请求是否为用于能力发现的OPTIONS请求（而不是CORS预检）
+// 参数:
+//
+//	req: HTTP请求
+//
+// 返回值:
+//
+//	bool: 是否为能力发现请求
+func IsDiscoveryRequest(req *http.Request) bool {
+	return req.Method == http.MethodOptions && req.Header.Get(echo.HeaderAccessControlRequestMethod) == ""
+}
+
+// PathParam 路径参数（总是必需）
+// 参数:
+//
+//	name: 参数名
+//	description: 参数说明
+//
+// 返回值:
+//
+//	Param: 参数
+func PathParam(name, description string) Param {
+	return Param{Name: name, In: "path", Required: true, Description: description}
+}
+
+// QueryParam 查询参数
+// 参数:
+//
+//	name: 参数名
+//	description: 参数说明
+//
+// 返回值:
+//
+//	Param: 参数
+func QueryParam(name, description string) Param {
+	return Param{Name: name, In: "query", Description: description}
+}
+
+// FormParam 表单字段
+// 参数:
+//
+//	name: 字段名
+//	description: 字段说明
+//
+// 返回值:
+//
+//	Param: 参数
+func FormParam(name, description string) Param {
+	return Param{Name: name, In: "form", Description: description}
+}
+
+// HeaderParam 请求头
+// 参数:
+//
+//	name: 请求头名称
+//	description: 请求头说明
+//
+// 返回值:
+//
+//	Param: 参数
+func HeaderParam(name, description string) Param {
+	return Param{Name: name, In: "header", Description: description}
+}
+
+// BodyParam 请求体
+// 参数:
+//
+//	description: 请求体说明
+//
+// 返回值:
+//
+//	Param: 参数
+func BodyParam(description string) Param {
+	return Param{Name: "body", In: "body", Required: true, Description: description}
+}
+
+// Require 将参数标记为必需
+// 返回值:
+//
+//	Param: 标记为必需的参数
+func (p Param) Require() Param {
+	p.Required = true
+	return p
+}
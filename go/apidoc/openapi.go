@@ -0,0 +1,225 @@
+// Package apidoc 记录路由的方法和参数说明，并以OPTIONS响应提供给客户端
+// 作者: KO
+// 创建时间: 2026-10-15
+// 修改时间: 2026-10-15
+package apidoc
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+// wildcardParam OpenAPI文档中Echo通配符路径参数（*）使用的名称
+const wildcardParam = "path"
+
+// OpenAPIDocument OpenAPI 3文档
+type OpenAPIDocument struct {
+	OpenAPI    string                          `json:"openapi"`    // OpenAPI版本
+	Info       OpenAPIInfo                     `json:"info"`       // API信息
+	Paths      map[string]map[string]Operation `json:"paths"`      // 路径 -> 小写的HTTP方法 -> 操作
+	Components Components                      `json:"components"` // 共用的组件
+}
+
+// OpenAPIInfo API信息
+type OpenAPIInfo struct {
+	Title   string `json:"title"`   // 标题
+	Version string `json:"version"` // 版本
+}
+
+// Operation 路径上一个方法的说明
+type Operation struct {
+	Summary     string              `json:"summary"`               // 端点说明
+	Parameters  []OpenAPIParameter  `json:"parameters,omitempty"`  // 路径、查询和请求头参数
+	RequestBody *RequestBody        `json:"requestBody,omitempty"` // 请求体（表单字段或JSON）
+	Responses   map[string]Response `json:"responses"`             // 响应
+}
+
+// OpenAPIParameter 路径、查询或请求头参数
+type OpenAPIParameter struct {
+	Name        string `json:"name"`        // 参数名
+	In          string `json:"in"`          // 参数位置（path、query、header）
+	Required    bool   `json:"required"`    // 是否必需
+	Description string `json:"description"` // 参数说明
+	Schema      Schema `json:"schema"`      // 参数类型
+}
+
+// RequestBody 请求体
+type RequestBody struct {
+	Description string               `json:"description,omitempty"` // 请求体说明
+	Required    bool                 `json:"required"`              // 是否必需
+	Content     map[string]MediaType `json:"content"`               // 内容类型 -> 结构
+}
+
+// Response 响应
+type Response struct {
+	Description string               `json:"description"`       // 响应说明
+	Content     map[string]MediaType `json:"content,omitempty"` // 内容类型 -> 结构
+}
+
+// MediaType 某个内容类型的结构
+type MediaType struct {
+	Schema Schema `json:"schema"` // 结构
+}
+
+// Schema JSON Schema的子集
+type Schema struct {
+	Ref         string            `json:"$ref,omitempty"`        // 引用的组件
+	Type        string            `json:"type,omitempty"`        // 类型
+	Format      string            `json:"format,omitempty"`      // 格式
+	Description string            `json:"description,omitempty"` // 说明
+	Properties  map[string]Schema `json:"properties,omitempty"`  // 对象的属性
+	Required    []string          `json:"required,omitempty"`    // 必需的属性
+}
+
+// Components 共用的组件
+type Components struct {
+	Schemas map[string]Schema `json:"schemas"` // 结构定义
+}
+
+// errorSchema 所有错误响应共用的结构
+var errorSchema = Schema{
+	Type: "object",
+	Properties: map[string]Schema{
+		"error":    {Type: "string", Description: "Human-readable error message"},
+		"unlockAt": {Type: "string", Format: "date-time", Description: "Only for 403 responses on immutable objects: when the object can be overwritten or deleted"},
+	},
+	Required: []string{"error"},
+}
+
+// OpenAPI 由注册表中登记的路由生成OpenAPI 3文档
+// 文档与OPTIONS响应使用同一份路由说明，新增端点时通过Handle登记即可同步出现在文档中。
+// 路由说明不包含响应结构，成功响应描述为任意JSON，错误响应统一引用Error结构（HTTP状态码区分错误类别）
+// 参数:
+//
+//	title: API标题
+//	version: API版本
+//
+// 返回值:
+//
+//	*OpenAPIDocument: OpenAPI文档
+func (r *Registry) OpenAPI(title, version string) *OpenAPIDocument {
+	doc := &OpenAPIDocument{
+		OpenAPI: "3.0.3",
+		Info:    OpenAPIInfo{Title: title, Version: version},
+		Paths:   make(map[string]map[string]Operation, len(r.endpoints)),
+		Components: Components{
+			Schemas: map[string]Schema{"Error": errorSchema},
+		},
+	}
+
+	for path, endpoints := range r.endpoints {
+		operations := make(map[string]Operation, len(endpoints))
+		for _, endpoint := range endpoints {
+			operations[strings.ToLower(endpoint.Method)] = operation(endpoint)
+		}
+		doc.Paths[openAPIPath(path)] = operations
+	}
+	return doc
+}
+
+// OpenAPIHandler 返回以JSON提供OpenAPI文档的处理函数
+// 文档在每次请求时生成，因此包含处理函数注册之后登记的路由
+// 参数:
+//
+//	title: API标题
+//	version: API版本
+//
+// 返回值:
+//
+//	echo.HandlerFunc: 处理函数
+func (r *Registry) OpenAPIHandler(title, version string) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		return c.JSON(http.StatusOK, r.OpenAPI(title, version))
+	}
+}
+
+// openAPIPath 将Echo的路由路径转换为OpenAPI的路径模板
+// 参数:
+//
+//	path: Echo路由路径（如/api/s3/download/:key、/api/s3/stat/*）
+//
+// 返回值:
+//
+//	string: OpenAPI路径（如/api/s3/download/{key}、/api/s3/stat/{path}）
+func openAPIPath(path string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		switch {
+		case strings.HasPrefix(segment, ":"):
+			segments[i] = "{" + segment[1:] + "}"
+		case segment == "*":
+			segments[i] = "{" + wildcardParam + "}"
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+// operation 将端点说明转换为OpenAPI操作
+// 表单字段合并为multipart/form-data请求体（file字段为二进制），body参数作为JSON请求体
+// 参数:
+//
+//	endpoint: 端点说明
+//
+// 返回值:
+//
+//	Operation: OpenAPI操作
+func operation(endpoint Endpoint) Operation {
+	op := Operation{
+		Summary: endpoint.Description,
+		Responses: map[string]Response{
+			"2XX": {
+				Description: "Success",
+				Content:     map[string]MediaType{"application/json": {Schema: Schema{}}},
+			},
+			"default": {
+				Description: "Error",
+				Content:     map[string]MediaType{"application/json": {Schema: Schema{Ref: "#/components/schemas/Error"}}},
+			},
+		},
+	}
+
+	var form *Schema
+	for _, param := range endpoint.Params {
+		switch param.In {
+		case "path", "query", "header":
+			name := param.Name
+			if param.In == "path" && name == "*" {
+				name = wildcardParam
+			}
+			op.Parameters = append(op.Parameters, OpenAPIParameter{
+				Name:        name,
+				In:          param.In,
+				Required:    param.Required,
+				Description: param.Description,
+				Schema:      Schema{Type: "string"},
+			})
+		case "form":
+			if form == nil {
+				form = &Schema{Type: "object", Properties: map[string]Schema{}}
+			}
+			field := Schema{Type: "string", Description: param.Description}
+			if param.Name == "file" {
+				field.Format = "binary"
+			}
+			form.Properties[param.Name] = field
+			if param.Required {
+				form.Required = append(form.Required, param.Name)
+			}
+		case "body":
+			op.RequestBody = &RequestBody{
+				Description: param.Description,
+				Required:    param.Required,
+				Content:     map[string]MediaType{"application/json": {Schema: Schema{}}},
+			}
+		}
+	}
+	if form != nil {
+		op.RequestBody = &RequestBody{
+			Required: len(form.Required) > 0,
+			Content:  map[string]MediaType{"multipart/form-data": {Schema: *form}},
+		}
+	}
+	return op
+}
@@ -0,0 +1,216 @@
+// Package compress 提供按响应内容决定是否压缩的gzip中间件
+// 作者: KO
+// 创建时间: 2026-10-15
+// 修改时间: 2026-10-15
+package compress
+
+import (
+	"bufio"
+	"compress/gzip"
+	"mime"
+	"net"
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+// gzipScheme gzip内容编码名称
+const gzipScheme = "gzip"
+
+// Exclusions 不压缩的内容类型和扩展名
+type Exclusions struct {
+	contentTypes []string // 内容类型，支持image/*形式的通配
+	extensions   []string // 请求路径的扩展名（不含点，小写）
+}
+
+// NewExclusions 创建压缩排除列表
+// 参数:
+//
+//	contentTypes: 不压缩的内容类型（支持image/*形式的通配）
+//	extensions: 不压缩的请求路径扩展名（可带或不带点）
+//
+// 返回值:
+//
+//	*Exclusions: 压缩排除列表
+func NewExclusions(contentTypes, extensions []string) *Exclusions {
+	ex := &Exclusions{}
+	for _, contentType := range contentTypes {
+		ex.contentTypes = append(ex.contentTypes, strings.ToLower(contentType))
+	}
+	for _, ext := range extensions {
+		ex.extensions = append(ex.extensions, strings.ToLower(strings.TrimPrefix(ext, ".")))
+	}
+	return ex
+}
+
+// excludesPath 判断请求路径的扩展名是否在排除列表中
+// 参数:
+//
+//	urlPath: 请求路径
+//
+// 返回值:
+//
+//	bool: 是否排除
+func (ex *Exclusions) excludesPath(urlPath string) bool {
+	ext := strings.ToLower(strings.TrimPrefix(path.Ext(urlPath), "."))
+	if ext == "" {
+		return false
+	}
+	for _, excluded := range ex.extensions {
+		if ext == excluded {
+			return true
+		}
+	}
+	return false
+}
+
+// excludesContentType 判断响应的内容类型是否在排除列表中
+// 参数:
+//
+//	contentType: 响应的Content-Type
+//
+// 返回值:
+//
+//	bool: 是否排除
+func (ex *Exclusions) excludesContentType(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	for _, excluded := range ex.contentTypes {
+		if mediaType == excluded {
+			return true
+		}
+		if prefix, ok := strings.CutSuffix(excluded, "/*"); ok && strings.HasPrefix(mediaType, prefix+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// Middleware 返回gzip压缩中间件
+// 与echo自带的Gzip中间件不同，是否压缩在响应头写出时才决定：
+// 请求路径扩展名或响应内容类型在排除列表中、响应已带有Content-Encoding（如以gzip编码存储的对象）、
+// 或者是206部分内容响应时，原样输出，避免重复压缩已压缩的数据
+// 参数:
+//
+//	ex: 压缩排除列表
+//
+// 返回值:
+//
+//	echo.MiddlewareFunc: 中间件
+func Middleware(ex *Exclusions) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			req := c.Request()
+			res := c.Response()
+			res.Header().Add(echo.HeaderVary, echo.HeaderAcceptEncoding)
+
+			if req.Method == http.MethodHead ||
+				req.Header.Get(echo.HeaderUpgrade) != "" ||
+				!strings.Contains(req.Header.Get(echo.HeaderAcceptEncoding), gzipScheme) ||
+				ex.excludesPath(req.URL.Path) {
+				return next(c)
+			}
+
+			w := &gzipResponseWriter{ResponseWriter: res.Writer, exclusions: ex}
+			res.Writer = w
+			defer func() {
+				w.close()
+				res.Writer = w.ResponseWriter
+			}()
+
+			return next(c)
+		}
+	}
+}
+
+// gzipResponseWriter 在写出响应头时决定是否压缩的ResponseWriter
+type gzipResponseWriter struct {
+	http.ResponseWriter
+	exclusions *Exclusions  // 压缩排除列表
+	decided    bool         // 是否已决定压缩方式
+	gz         *gzip.Writer // 压缩写入器（不压缩时为nil）
+}
+
+// decide 根据状态码和已设置的响应头决定是否压缩
+// 参数:
+//
+//	code: 响应状态码
+func (w *gzipResponseWriter) decide(code int) {
+	if w.decided {
+		return
+	}
+	w.decided = true
+
+	header := w.Header()
+	if code < http.StatusOK || code == http.StatusNoContent || code == http.StatusPartialContent || code == http.StatusNotModified ||
+		header.Get(echo.HeaderContentEncoding) != "" ||
+		w.exclusions.excludesContentType(header.Get(echo.HeaderContentType)) {
+		return
+	}
+
+	header.Set(echo.HeaderContentEncoding, gzipScheme)
+	header.Del(echo.HeaderContentLength)
+	w.gz = gzip.NewWriter(w.ResponseWriter)
+}
+
+// WriteHeader 写出响应头
+// 参数:
+//
+//	code: 响应状态码
+func (w *gzipResponseWriter) WriteHeader(code int) {
+	w.decide(code)
+	w.ResponseWriter.WriteHeader(code)
+}
+
+// Write 写出响应体
+// 参数:
+//
+//	b: 响应内容
+//
+// 返回值:
+//
+//	int: 写入的字节数
+//	error: 错误信息
+func (w *gzipResponseWriter) Write(b []byte) (int, error) {
+	if !w.decided {
+		if w.Header().Get(echo.HeaderContentType) == "" {
+			w.Header().Set(echo.HeaderContentType, http.DetectContentType(b))
+		}
+		w.decide(http.StatusOK)
+	}
+	if w.gz != nil {
+		return w.gz.Write(b)
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// Flush 刷新已压缩的数据到客户端
+func (w *gzipResponseWriter) Flush() {
+	if w.gz != nil {
+		w.gz.Flush()
+	}
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Hijack 接管底层连接（用于WebSocket）
+// 返回值:
+//
+//	net.Conn: 底层连接
+//	*bufio.ReadWriter: 读写缓冲
+//	error: 错误信息
+func (w *gzipResponseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return w.ResponseWriter.(http.Hijacker).Hijack()
+}
+
+// close 结束压缩流
+func (w *gzipResponseWriter) close() {
+	if w.gz != nil {
+		w.gz.Close()
+	}
+}
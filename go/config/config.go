@@ -1,46 +1,293 @@
 // Package config 提供配置相关功能
 // 作者: KO
 // 创建时间: 2026-01-27
-// 修改时间: 2026-01-27
+// 修改时间: 2026-10-15
 package config
 
 import (
+	"errors"
+	"reflect"
+	"time"
+
 	"github.com/spf13/viper"
 )
 
 // S3Config 存储S3客户端配置
 type S3Config struct {
+	Port            string        `mapstructure:"port"`             // HTTP服务监听端口（环境变量PORT优先）
+	ShutdownTimeout time.Duration `mapstructure:"shutdown_timeout"` // 收到SIGINT/SIGTERM后等待进行中的请求完成的最长时间
+
+	// RequestTimeout 单次S3请求等待响应头的最长时间，同时作为不传输对象内容的操作（HEAD、列举、删除等）
+	// 包括重试在内的整体超时；GetObject、PutObject等传输内容的操作耗时与大小成正比，只受前者限制。0表示不限制
+	RequestTimeout time.Duration `mapstructure:"request_timeout"`
+	ConnectTimeout time.Duration `mapstructure:"connect_timeout"` // 建立到S3端点的TCP连接和TLS握手的最长时间，0表示使用系统默认值
+
+	LogFormat string `mapstructure:"log_format"` // 日志格式（text、json）
+	LogLevel  string `mapstructure:"log_level"`  // 最低日志级别（debug、info、warn、error）
+
 	Endpoint        string `mapstructure:"endpoint"`          // S3服务端点
 	Region          string `mapstructure:"region"`            // 区域
 	Bucket          string `mapstructure:"bucket"`            // 默认存储桶
 	AccessKeyID     string `mapstructure:"access_key_id"`     // 访问密钥ID
 	SecretAccessKey string `mapstructure:"secret_access_key"` // 秘密访问密钥
+	UseIAMRole      bool   `mapstructure:"use_iam_role"`      // 忽略访问密钥，始终使用SDK默认凭证链（环境变量、共享配置、IRSA、实例角色），用于EKS/EC2部署
+	AssumeRoleArn   string `mapstructure:"assume_role_arn"`   // 通过STS AssumeRole扮演的角色ARN（为空时直接使用访问密钥或默认凭证链）
+	RoleSessionName string `mapstructure:"role_session_name"` // AssumeRole的会话名称（为空时使用s3service）
+	ExternalID      string `mapstructure:"external_id"`       // AssumeRole的外部ID（跨账户角色要求时配置）
 	UsePathStyle    bool   `mapstructure:"use_path_style"`    // 是否使用路径风格访问
+	BackendFlavor   string `mapstructure:"backend_flavor"`    // 后端类型（aws、minio、ceph、generic），用于选择各后端的行为差异处理
+	PublicURLBase   string `mapstructure:"public_url_base"`   // 对象公开URL的前缀（如https://s3.example.com），上传响应据此返回对象URL
+
+	FailoverEndpoint        string `mapstructure:"failover_endpoint"`          // 读取故障转移的备用S3端点（如副本集群，使用相同的存储桶名称），为空时不转移
+	FailoverAccessKeyID     string `mapstructure:"failover_access_key_id"`     // 备用端点的访问密钥ID（为空时使用主端点的凭证）
+	FailoverSecretAccessKey string `mapstructure:"failover_secret_access_key"` // 备用端点的秘密访问密钥
+
+	// HashPrefixBytes 大于0时，在存储的键前加上键的SHA256哈希的前N个字节（十六进制）和/，
+	// 如a/b.txt存储为55/a/b.txt，将按日期等顺序写入的键分散到不同的S3分区。
+	// 哈希前缀在写入时透明添加、读取和列举时透明去掉，但会改变存储桶中的键布局，
+	// 且带前缀的列举需要扫描整个存储桶；必须在开始写入数据之前设置，之后不应再修改
+	HashPrefixBytes int `mapstructure:"hash_prefix_bytes"`
+
+	Buckets []string `mapstructure:"buckets"` // 额外的已知存储桶（无ListBuckets权限时与默认存储桶一起作为回退列表）
+
+	DownloadRedirect          bool          `mapstructure:"download_redirect"`           // 是否对大对象返回预签名URL重定向
+	DownloadRedirectThreshold int64         `mapstructure:"download_redirect_threshold"` // 重定向阈值（字节），不小于该大小的对象重定向，否则直接代理
+	DownloadRedirectExpiry    time.Duration `mapstructure:"download_redirect_expiry"`    // 重定向预签名URL的有效期
+	DownloadRateLimit         int64         `mapstructure:"download_rate_limit"`         // 单个下载请求的最大速率（字节/秒，0表示不限制），请求的maxRate只能进一步降低
+
+	MaxBatchSize           int `mapstructure:"max_batch_size"`           // 批量操作单次请求允许的最大键数量
+	WorkerConcurrency      int `mapstructure:"worker_concurrency"`       // 并发访问S3的后台任务/批量操作的最大并发数
+	MaxUpstreamConcurrency int `mapstructure:"max_upstream_concurrency"` // 全部S3调用的全局最大并发数（0表示不限制），超出时等待空闲或请求取消
+
+	MetadataIndexEnabled  bool          `mapstructure:"metadata_index_enabled"`  // 是否启用默认存储桶的元数据内存索引
+	MetadataIndexInterval time.Duration `mapstructure:"metadata_index_interval"` // 元数据索引全量重建间隔
+
+	NDJSONRolloverBytes    int64         `mapstructure:"ndjson_rollover_bytes"`    // NDJSON追加写入时单个对象的最大字节数
+	NDJSONRolloverInterval time.Duration `mapstructure:"ndjson_rollover_interval"` // NDJSON追加写入时单个对象覆盖的最长时间
+
+	MultipartPartSize  int64 `mapstructure:"multipart_part_size"` // 分段上传的分段大小（字节，最小5MB）
+	MultipartThreshold int64 `mapstructure:"multipart_threshold"` // upload-multipart接口中超过该大小（字节）的文件使用分段上传
+
+	ChunkedMemoryLimit int64 `mapstructure:"chunked_memory_limit"` // 长度未知（chunked）或不可Seek（原始请求体）的上传在内存中缓冲的最大字节数，超出部分写入临时文件
+
+	PartSessionTTL time.Duration `mapstructure:"part_session_ttl"` // 按偏移写入的分段上传会话在最后一次活动后保留的时间，超时后中止并丢弃已上传的分段（0表示不过期）
+
+	HeadBytesMax int64 `mapstructure:"head_bytes_max"` // head-bytes接口单次允许读取的最大字节数
+
+	WebSocketAllowedOrigins []string `mapstructure:"websocket_allowed_origins"` // 除同源页面外允许建立WebSocket上传连接的来源（如https://app.example.com），*表示任意来源
+
+	StagingPrefix string `mapstructure:"staging_prefix"` // 暂存对象的键前缀
+
+	MetricsBucketAllowlist []string `mapstructure:"metrics_bucket_allowlist"` // 指标中保留原名的存储桶（默认存储桶始终保留），其余标记为other
+
+	// CaseInsensitiveKeys 启用后，下载/删除/检查存在/获取元数据时若精确键不存在，
+	// 会列举对象并按大小写不敏感的方式匹配；键以字母开头时需要扫描整个存储桶，对象多时代价很高
+	CaseInsensitiveKeys bool   `mapstructure:"case_insensitive_keys"`
+	KeyCase             string `mapstructure:"key_case"` // 上传时键的大小写规范化方式（lower、upper，为空时保持原样）
+
+	IntegrityCheckEnabled    bool          `mapstructure:"integrity_check_enabled"`     // 是否启用后台对象完整性抽样校验
+	IntegrityCheckInterval   time.Duration `mapstructure:"integrity_check_interval"`    // 完整性校验间隔
+	IntegrityCheckSampleRate float64       `mapstructure:"integrity_check_sample_rate"` // 每轮校验的对象抽样比例（0~1）
+	IntegrityCheckPrefix     string        `mapstructure:"integrity_check_prefix"`      // 完整性校验的键前缀（为空时校验整个默认存储桶）
+
+	UntrustedContentTypes []string          `mapstructure:"untrusted_content_types"` // 上传时不可信、需按扩展名纠正的内容类型
+	ContentTypeOverrides  map[string]string `mapstructure:"content_type_overrides"`  // 扩展名（不含点）到内容类型的映射，优先于系统MIME类型表
+	ContentTypePrefixes   map[string]string `mapstructure:"content_type_prefixes"`   // 键前缀（如images/）到内容类型的映射，上传未声明可信类型时优先于扩展名推断，最长前缀优先
+
+	BucketRegionPolicy  string `mapstructure:"bucket_region_policy"`  // 创建存储桶的区域与客户端区域不一致时的处理方式（reject、warn）
+	AllowRegionOverride bool   `mapstructure:"allow_region_override"` // 是否允许按请求指定与客户端不同的区域（启用时不做区域校验）
+
+	ListRegexMaxLength int `mapstructure:"list_regex_max_length"` // 列表正则过滤模式的最大长度
+
+	ThumbnailPrefix          string `mapstructure:"thumbnail_prefix"`            // 缓存缩略图的键前缀
+	ThumbnailMaxWidth        int    `mapstructure:"thumbnail_max_width"`         // 缩略图的最大宽度（像素）
+	ThumbnailMaxSourcePixels int64  `mapstructure:"thumbnail_max_source_pixels"` // 可生成缩略图的源图片最大像素数
+	ThumbnailMaxSourceBytes  int64  `mapstructure:"thumbnail_max_source_bytes"`  // 可生成缩略图的源图片最大字节数，源图片需要完整读入内存
+
+	BucketQuotaBytes int64            `mapstructure:"bucket_quota_bytes"` // 每个存储桶的存储配额（字节，0表示不限制）
+	BucketQuotas     map[string]int64 `mapstructure:"bucket_quotas"`      // 按存储桶配置的配额，优先于bucket_quota_bytes
+	BucketUsageTTL   time.Duration    `mapstructure:"bucket_usage_ttl"`   // 配额检查使用的存储桶用量缓存时间
+
+	GzipEnabled             bool     `mapstructure:"gzip_enabled"`               // 是否启用响应gzip压缩
+	GzipExcludeContentTypes []string `mapstructure:"gzip_exclude_content_types"` // 不压缩的响应内容类型（支持image/*形式的通配）
+	GzipExcludeExtensions   []string `mapstructure:"gzip_exclude_extensions"`    // 不压缩的请求路径扩展名
+
+	MetadataTemplateCacheControl map[string]string `mapstructure:"metadata_template_cache_control"` // 元数据模板：扩展名（不含点）到Cache-Control的映射，default项用于其他扩展名
+	MetadataTemplateMetadata     map[string]string `mapstructure:"metadata_template_metadata"`      // 元数据模板：覆盖到用户元数据上的项，值中的{{now}}替换为执行时间
+
+	MaxRetries int           `mapstructure:"max_retries"` // S3请求失败后的最大重试次数（不含首次请求），负数表示使用SDK默认值
+	MaxBackoff time.Duration `mapstructure:"max_backoff"` // 重试的指数退避中单次等待的最长时间
+
+	ForceDownload         bool     `mapstructure:"force_download"`          // 是否所有下载都以附件形式返回（托管不可信内容时防止浏览器内联渲染）
+	ForceDownloadOverride bool     `mapstructure:"force_download_override"` // 启用force_download时，是否将高风险内容类型改为application/octet-stream
+	RiskyContentTypes     []string `mapstructure:"risky_content_types"`     // 可能被浏览器执行脚本的高风险内容类型
+
+	UploadTransformsEnabled bool                `mapstructure:"upload_transforms_enabled"` // 是否在上传时执行内容转换
+	UploadTransforms        map[string][]string `mapstructure:"upload_transforms"`         // 内容类型到依次执行的转换名称列表的映射（内置strip-exif、minify-json）
+
+	FilenameCharset string `mapstructure:"filename_charset"` // 遗留客户端上传文件名使用的字符集（如shift_jis、iso-8859-1），为空时不转码
+
+	ReportGzip   bool   `mapstructure:"report_gzip"`   // 存储为对象的清单报告是否以gzip压缩（.json.gz）
+	ReportBucket string `mapstructure:"report_bucket"` // 存储清单报告的存储桶（为空时使用默认存储桶）
+	ReportPrefix string `mapstructure:"report_prefix"` // 存储清单报告的键前缀
+
+	ReportTimeout       time.Duration `mapstructure:"report_timeout"`        // 单个报告生成和上传的最长时间，超时后中止（0表示不限制）
+	ReportMaxConcurrent int           `mapstructure:"report_max_concurrent"` // 同时在后台生成的最大报告数，超出时拒绝新的报告（0表示不限制）
+
+	ReadinessInterval time.Duration `mapstructure:"readiness_interval"` // 启动时S3就绪探测的间隔，未就绪时也作为503响应的Retry-After
+	HealthCacheTTL    time.Duration `mapstructure:"health_cache_ttl"`   // 深度健康检查结果的缓存时间，期间的探测复用最近一次结果，0表示每次都访问S3
+
+	// ImmutabilityWindow 大于0时，对象在最后修改后的这段时间内拒绝通过本服务覆盖或删除（返回403和解锁时间）。
+	// 这是建议性规则，只对经过本服务的请求生效，直接访问S3或使用预签名URL不受限制；
+	// 需要强制的WORM保证时应使用存储桶的对象锁定
+	ImmutabilityWindow time.Duration `mapstructure:"immutability_window"`
+
+	RequestIDMetadata bool `mapstructure:"request_id_metadata"` // 是否在上传的对象上以x-amz-meta-request-id记录创建它的请求ID（客户端已提供同名元数据时不覆盖）
+
+	DefaultMetadata map[string]string `mapstructure:"default_metadata"` // 每次上传都附加的默认用户元数据（如environment: prod），请求中的同名元数据优先
+
+	// DebugRecordCalls 仅用于集成测试，不要在生产环境启用：记录每次S3调用及其参数（最近1000次），
+	// 并通过/api/s3/debug/calls接口公开，供测试断言上传确实以预期的内容类型和元数据调用了PutObject
+	DebugRecordCalls bool `mapstructure:"debug_record_calls"`
 }
 
 // LoadConfig 从配置文件加载S3配置
+// path为空时依次在当前目录、/etc/s3service/和$HOME/.s3service/中查找config.yaml，都不存在时只使用环境变量和默认值；
+// 显式指定的配置文件不存在时返回错误
+// 参数:
+//
+//	path: 配置文件路径（为空时按上述位置查找）
+//
 // 返回值:
 //
 //	*S3Config: S3配置信息
 //	error: 错误信息
-func LoadConfig() (*S3Config, error) {
-	viper.SetConfigFile("./config.yaml")
+func LoadConfig(path string) (*S3Config, error) {
+	if path != "" {
+		viper.SetConfigFile(path)
+	} else {
+		viper.SetConfigName("config")
+		viper.AddConfigPath(".")
+		viper.AddConfigPath("/etc/s3service/")
+		viper.AddConfigPath("$HOME/.s3service/")
+	}
 	viper.SetConfigType("yaml")
 
 	// 设置默认值
+	viper.SetDefault("port", "8080")
+	viper.SetDefault("shutdown_timeout", 30*time.Second)
+	viper.SetDefault("request_timeout", 30*time.Second)
+	viper.SetDefault("connect_timeout", 5*time.Second)
+	viper.SetDefault("log_format", "text")
+	viper.SetDefault("log_level", "info")
 	viper.SetDefault("endpoint", "http://localhost:9000")
 	viper.SetDefault("region", "us-east-1")
 	viper.SetDefault("bucket", "test")
+	viper.SetDefault("use_iam_role", false)
 	viper.SetDefault("use_path_style", true)
+	viper.SetDefault("backend_flavor", "generic")
+	viper.SetDefault("hash_prefix_bytes", 0)
+	viper.SetDefault("download_redirect", false)
+	viper.SetDefault("download_redirect_threshold", 8*1024*1024)
+	viper.SetDefault("download_redirect_expiry", 15*time.Minute)
+	viper.SetDefault("download_rate_limit", 0)
+	viper.SetDefault("max_batch_size", 1000)
+	viper.SetDefault("worker_concurrency", 8)
+	viper.SetDefault("max_upstream_concurrency", 0)
+	viper.SetDefault("metadata_index_enabled", false)
+	viper.SetDefault("metadata_index_interval", 10*time.Minute)
+	viper.SetDefault("ndjson_rollover_bytes", 64*1024*1024)
+	viper.SetDefault("ndjson_rollover_interval", 5*time.Minute)
+	viper.SetDefault("multipart_part_size", 5*1024*1024)
+	viper.SetDefault("chunked_memory_limit", 8*1024*1024)
+	viper.SetDefault("part_session_ttl", 24*time.Hour)
+	viper.SetDefault("multipart_threshold", 64*1024*1024)
+	viper.SetDefault("head_bytes_max", 64*1024)
+	viper.SetDefault("staging_prefix", ".staging/")
+	viper.SetDefault("integrity_check_enabled", false)
+	viper.SetDefault("integrity_check_interval", time.Hour)
+	viper.SetDefault("integrity_check_sample_rate", 0.01)
+	viper.SetDefault("bucket_region_policy", "reject")
+	viper.SetDefault("list_regex_max_length", 256)
+	viper.SetDefault("thumbnail_prefix", "thumbnails/")
+	viper.SetDefault("thumbnail_max_width", 1024)
+	viper.SetDefault("thumbnail_max_source_pixels", 40*1000*1000)
+	viper.SetDefault("thumbnail_max_source_bytes", 50*1024*1024)
+	viper.SetDefault("bucket_quota_bytes", 0)
+	viper.SetDefault("bucket_usage_ttl", time.Minute)
+	viper.SetDefault("gzip_enabled", false)
+	viper.SetDefault("gzip_exclude_content_types", []string{
+		"application/octet-stream", "image/*", "video/*", "audio/*",
+		"application/zip", "application/gzip", "application/x-gzip", "application/x-bzip2", "application/x-xz", "application/x-7z-compressed",
+	})
+	viper.SetDefault("gzip_exclude_extensions", []string{"jpg", "jpeg", "png", "gif", "webp", "zip", "gz", "tgz", "bz2", "xz", "7z", "mp3", "mp4", "webm"})
+	viper.SetDefault("max_retries", 2)
+	viper.SetDefault("max_backoff", 20*time.Second)
+	viper.SetDefault("force_download", false)
+	viper.SetDefault("force_download_override", true)
+	viper.SetDefault("risky_content_types", []string{
+		"text/html", "application/xhtml+xml", "image/svg+xml", "text/xml", "application/xml", "text/javascript", "application/javascript",
+	})
+	viper.SetDefault("upload_transforms_enabled", false)
+	viper.SetDefault("upload_transforms", map[string][]string{
+		"image/jpeg":       {"strip-exif"},
+		"image/png":        {"strip-exif"},
+		"application/json": {"minify-json"},
+	})
+	viper.SetDefault("untrusted_content_types", []string{"application/octet-stream", "binary/octet-stream"})
+	viper.SetDefault("report_gzip", true)
+	viper.SetDefault("report_prefix", "reports/")
+	viper.SetDefault("report_timeout", 30*time.Minute)
+	viper.SetDefault("report_max_concurrent", 2)
+	viper.SetDefault("readiness_interval", 2*time.Second)
+	viper.SetDefault("health_cache_ttl", 5*time.Second)
+	viper.SetDefault("immutability_window", 0)
+	viper.SetDefault("request_id_metadata", false)
+	viper.SetDefault("debug_record_calls", false)
 
-	if err := viper.ReadInConfig(); err != nil {
+	// 环境变量优先于配置文件：每个配置项对应S3_加大写键名的环境变量（如S3_ACCESS_KEY_ID）
+	viper.SetEnvPrefix("S3")
+	viper.AutomaticEnv()
+	if err := bindEnvs(); err != nil {
+		return nil, err
+	}
+	// 容器平台通常通过PORT指定监听端口，其优先级高于S3_PORT
+	if err := viper.BindEnv("port", "PORT", "S3_PORT"); err != nil {
 		return nil, err
 	}
 
+	// 未显式指定且各位置都没有配置文件时只使用环境变量和默认值
+	if err := viper.ReadInConfig(); err != nil {
+		var notFound viper.ConfigFileNotFoundError
+		if path != "" || !errors.As(err, &notFound) {
+			return nil, err
+		}
+	}
+
 	var config S3Config
 	if err := viper.Unmarshal(&config); err != nil {
 		return nil, err
 	}
 
 	return &config, nil
+}
+
+// bindEnvs 为S3Config的每个配置项绑定环境变量
+// AutomaticEnv只对viper已知的键生效，没有默认值也不在配置文件中的键（如access_key_id）
+// 需要显式绑定，Unmarshal时才会读取对应的环境变量
+// 返回值:
+//
+//	error: 错误信息
+func bindEnvs() error {
+	t := reflect.TypeOf(S3Config{})
+	for i := 0; i < t.NumField(); i++ {
+		key := t.Field(i).Tag.Get("mapstructure")
+		if key == "" {
+			continue
+		}
+		if err := viper.BindEnv(key); err != nil {
+			return err
+		}
+	}
+	return nil
 }
\ No newline at end of file
@@ -0,0 +1,72 @@
+// Package controllers 处理HTTP请求
+// 作者: KO
+// 创建时间: 2026-10-15
+// 修改时间: 2026-10-15
+package controllers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/example/s3service/s3"
+	"github.com/labstack/echo/v4"
+)
+
+// DownloadTar 将前缀下的对象以tar归档（gzip=true时为.tar.gz）流式返回
+// 条目名为键相对于前缀的路径。归档直接写入响应，不在本地暂存；
+// 响应在写出第一个条目时才提交，此前的错误（如存储桶不存在）仍以JSON返回，
+// 之后出错时中断连接，客户端得到不完整的归档而不是看似完整的文件
+// 参数:
+//
+//	ctx: Echo上下文
+//
+// 返回值:
+//
+//	error: 错误信息
+func (c *S3Controller) DownloadTar(ctx echo.Context) error {
+	prefix := ctx.QueryParam("prefix")
+	if prefix == "" {
+		return ctx.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Prefix is required",
+		})
+	}
+	bucket := ctx.QueryParam("bucket")
+	gzipped := ctx.QueryParam("gzip") == "true"
+
+	name := path.Base(strings.TrimSuffix(prefix, "/")) + ".tar"
+	contentType := "application/x-tar"
+	if gzipped {
+		name += ".gz"
+		contentType = "application/gzip"
+	}
+
+	header := ctx.Response().Header()
+	header.Set("Content-Disposition", c.contentDisposition(name))
+	header.Set(echo.HeaderContentType, contentType)
+
+	if _, err := c.service.WriteTar(ctx.Request().Context(), bucket, prefix, ctx.Response(), gzipped); err != nil {
+		if ctx.Response().Committed {
+			log.Printf("Aborting tar download of %s: %v", prefix, err)
+			panic(http.ErrAbortHandler)
+		}
+
+		header.Del("Content-Disposition")
+		if s3.IsNotFound(err) {
+			return ctx.JSON(http.StatusNotFound, map[string]string{
+				"error": "Failed to create tar archive: " + err.Error(),
+			})
+		}
+		if errors.Is(err, s3.ErrEncryptionKeyRequired) {
+			return ctx.JSON(http.StatusBadRequest, map[string]string{
+				"error": err.Error(),
+			})
+		}
+		return ctx.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Failed to create tar archive: " + err.Error(),
+		})
+	}
+	return nil
+}
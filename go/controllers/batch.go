@@ -0,0 +1,377 @@
+// Package controllers 处理HTTP请求
+// 作者: KO
+// 创建时间: 2026-10-15
+// 修改时间: 2026-10-15
+package controllers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/example/s3service/s3"
+	"github.com/labstack/echo/v4"
+)
+
+// normalizeBatchKeys 校验并规范化批量操作的键列表
+// 所有批量接口（删除、复制、存在性检查、预签名等）都应通过该方法校验请求中的键，
+// 以保证行为一致：拒绝空列表、空键以及超过max_batch_size的列表，重复键按首次出现的顺序去重
+// 参数:
+//
+//	keys: 请求中的键列表
+//
+// 返回值:
+//
+//	[]string: 去重后的键列表
+//	error: 校验失败时的错误信息（应以400返回给客户端）
+func (c *S3Controller) normalizeBatchKeys(keys []string) ([]string, error) {
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("keys must not be empty")
+	}
+
+	seen := make(map[string]struct{}, len(keys))
+	unique := make([]string, 0, len(keys))
+	for _, key := range keys {
+		if key == "" {
+			return nil, fmt.Errorf("keys must not contain empty values")
+		}
+		if _, ok := seen[key]; ok {
+			continue
+		}
+		seen[key] = struct{}{}
+		unique = append(unique, key)
+	}
+
+	if c.cfg.MaxBatchSize > 0 && len(unique) > c.cfg.MaxBatchSize {
+		return nil, fmt.Errorf("too many keys in batch request: %d (max %d)", len(unique), c.cfg.MaxBatchSize)
+	}
+
+	return unique, nil
+}
+
+// batchRecentErrors SSE进度事件中携带的最近错误数量
+const batchRecentErrors = 10
+
+// batchRequest 批量删除/复制/移动的请求体
+type batchRequest struct {
+	Bucket     string   `json:"bucket"`     // 存储桶名称（为空时使用默认存储桶）
+	Keys       []string `json:"keys"`       // 文件键列表
+	DestBucket string   `json:"destBucket"` // 复制/移动的目标存储桶（为空时使用源存储桶）
+	DestPrefix string   `json:"destPrefix"` // 复制/移动的目标键前缀
+}
+
+// batchProgress SSE进度事件
+type batchProgress struct {
+	Processed    int              `json:"processed"`    // 已处理的键数量
+	Total        int              `json:"total"`        // 键总数
+	Failed       int              `json:"failed"`       // 失败的键数量
+	RecentErrors []s3.BatchResult `json:"recentErrors"` // 最近失败的键
+}
+
+// batchSummary 批量操作结束后的汇总（SSE的summary事件）
+type batchSummary struct {
+	Total     int              `json:"total"`     // 键总数
+	Succeeded int              `json:"succeeded"` // 成功的键数量
+	Failed    int              `json:"failed"`    // 失败（含未执行）的键数量
+	Errors    []s3.BatchResult `json:"errors"`    // 所有失败的键
+}
+
+// BatchDelete 批量删除文件
+// 请求体：{"bucket": "...", "keys": [...]}；响应为{"deleted": [...], "errors": [{key, code, message}]}，
+// 全部成功时返回200，部分失败时返回207，DeleteObjects请求整体失败时返回502，
+// 客户端可以只重试errors中的键。
+// 请求头Accept: text/event-stream时以SSE流式返回进度事件和最终的summary事件
+// 参数:
+//
+//	ctx: Echo上下文
+//
+// 返回值:
+//
+//	error: 错误信息
+func (c *S3Controller) BatchDelete(ctx echo.Context) error {
+	req, keys, err := c.bindBatchRequest(ctx)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, map[string]string{
+			"error": err.Error(),
+		})
+	}
+
+	if wantsEventStream(ctx) {
+		return c.streamBatch(ctx, len(keys), func(progress chan<- s3.BatchResult) []s3.BatchResult {
+			result, err := c.service.BatchDelete(ctx.Request().Context(), req.Bucket, keys, progress)
+			if err != nil {
+				return failedBatch(keys, err)
+			}
+			results := make([]s3.BatchResult, 0, len(keys))
+			for _, key := range result.Deleted {
+				results = append(results, s3.BatchResult{Key: key})
+			}
+			for _, e := range result.Errors {
+				results = append(results, s3.BatchResult{Key: e.Key, Error: e.Code + ": " + e.Message})
+			}
+			return results
+		})
+	}
+
+	result, err := c.service.BatchDelete(ctx.Request().Context(), req.Bucket, keys, nil)
+	if err != nil {
+		return ctx.JSON(http.StatusBadGateway, map[string]string{
+			"error": "Failed to delete files: " + err.Error(),
+		})
+	}
+
+	status := http.StatusOK
+	if len(result.Errors) > 0 {
+		status = http.StatusMultiStatus
+	}
+	return ctx.JSON(status, result)
+}
+
+// BatchCopy 批量复制文件到destBucket/destPrefix下
+// 请求体：{"bucket": "...", "keys": [...], "destBucket": "...", "destPrefix": "..."}；
+// 返回每个键的结果，全部成功时返回200，存在失败时返回207。
+// 请求头Accept: text/event-stream时以SSE流式返回进度事件和最终的summary事件
+// 参数:
+//
+//	ctx: Echo上下文
+//
+// 返回值:
+//
+//	error: 错误信息
+func (c *S3Controller) BatchCopy(ctx echo.Context) error {
+	return c.batchCopy(ctx, false)
+}
+
+// BatchMove 批量移动文件到destBucket/destPrefix下（复制后删除源对象）
+// 请求体和响应方式同BatchCopy
+// 参数:
+//
+//	ctx: Echo上下文
+//
+// 返回值:
+//
+//	error: 错误信息
+func (c *S3Controller) BatchMove(ctx echo.Context) error {
+	return c.batchCopy(ctx, true)
+}
+
+// batchCopy 批量复制或移动文件
+// 参数:
+//
+//	ctx: Echo上下文
+//	move: 是否在复制后删除源对象
+//
+// 返回值:
+//
+//	error: 错误信息
+func (c *S3Controller) batchCopy(ctx echo.Context, move bool) error {
+	req, keys, err := c.bindBatchRequest(ctx)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, map[string]string{
+			"error": err.Error(),
+		})
+	}
+	// 先校验目标，避免SSE响应开始后才发现请求无效
+	if (req.DestBucket == "" || req.DestBucket == req.Bucket) && req.DestPrefix == "" {
+		return ctx.JSON(http.StatusBadRequest, map[string]string{
+			"error": "destBucket or destPrefix must differ from the source",
+		})
+	}
+
+	return c.respondBatch(ctx, len(keys), func(progress chan<- s3.BatchResult) []s3.BatchResult {
+		results, err := c.service.BatchCopy(ctx.Request().Context(), req.Bucket, keys, req.DestBucket, req.DestPrefix, move, progress)
+		if err != nil {
+			return failedBatch(keys, err)
+		}
+		return results
+	})
+}
+
+// bindBatchRequest 解析批量操作的请求体并校验键列表
+// 参数:
+//
+//	ctx: Echo上下文
+//
+// 返回值:
+//
+//	batchRequest: 请求体
+//	[]string: 去重后的键列表
+//	error: 校验失败时的错误信息
+func (c *S3Controller) bindBatchRequest(ctx echo.Context) (batchRequest, []string, error) {
+	var req batchRequest
+	if err := ctx.Bind(&req); err != nil {
+		return req, nil, fmt.Errorf("invalid request body: %v", err)
+	}
+	keys, err := c.normalizeBatchKeys(req.Keys)
+	if err != nil {
+		return req, nil, err
+	}
+	return req, keys, nil
+}
+
+// failedBatch 返回所有键都以err失败的结果
+// 参数:
+//
+//	keys: 文件键列表
+//	err: 错误
+//
+// 返回值:
+//
+//	[]s3.BatchResult: 每个键的结果
+func failedBatch(keys []string, err error) []s3.BatchResult {
+	results := make([]s3.BatchResult, len(keys))
+	for i, key := range keys {
+		results[i] = s3.BatchResult{Key: key, Error: err.Error()}
+	}
+	return results
+}
+
+// wantsEventStream 判断客户端是否请求SSE响应（Accept包含text/event-stream）
+// 参数:
+//
+//	ctx: Echo上下文
+//
+// 返回值:
+//
+//	bool: 是否请求SSE
+func wantsEventStream(ctx echo.Context) bool {
+	return strings.Contains(ctx.Request().Header.Get(echo.HeaderAccept), "text/event-stream")
+}
+
+// respondBatch 执行批量操作并返回结果
+// 客户端请求SSE时见streamBatch；否则等待全部完成后返回结果列表（存在失败时返回207）
+// 参数:
+//
+//	ctx: Echo上下文
+//	total: 键总数
+//	run: 执行批量操作的函数，每个键完成后将结果发送到progress
+//
+// 返回值:
+//
+//	error: 错误信息
+func (c *S3Controller) respondBatch(ctx echo.Context, total int, run func(progress chan<- s3.BatchResult) []s3.BatchResult) error {
+	if wantsEventStream(ctx) {
+		return c.streamBatch(ctx, total, run)
+	}
+
+	results := run(nil)
+	status := http.StatusOK
+	for _, result := range results {
+		if result.Error != "" {
+			status = http.StatusMultiStatus
+			break
+		}
+	}
+	return ctx.JSON(status, results)
+}
+
+// streamBatch 执行批量操作并以SSE返回进度
+// 每个键完成后发送progress事件（已处理数、总数、失败数和最近的错误），结束时发送summary事件
+// 参数:
+//
+//	ctx: Echo上下文
+//	total: 键总数
+//	run: 执行批量操作的函数，每个键完成后将结果发送到progress
+//
+// 返回值:
+//
+//	error: 错误信息
+func (c *S3Controller) streamBatch(ctx echo.Context, total int, run func(progress chan<- s3.BatchResult) []s3.BatchResult) error {
+	res := ctx.Response()
+	res.Header().Set(echo.HeaderContentType, "text/event-stream")
+	res.Header().Set("Cache-Control", "no-cache")
+	res.Header().Set("X-Accel-Buffering", "no")
+	res.WriteHeader(http.StatusOK)
+	res.Flush()
+
+	progress := make(chan s3.BatchResult, c.cfg.WorkerConcurrency)
+	done := make(chan []s3.BatchResult, 1)
+	go func() {
+		results := run(progress)
+		close(progress)
+		done <- results
+	}()
+
+	// 客户端断开后请求上下文被取消，后续写入失败也要继续读取通道，直到工作协程全部结束
+	state := batchProgress{Total: total, RecentErrors: []s3.BatchResult{}}
+	for result := range progress {
+		state.Processed++
+		if result.Error != "" {
+			state.Failed++
+			state.RecentErrors = append(state.RecentErrors, result)
+			if len(state.RecentErrors) > batchRecentErrors {
+				state.RecentErrors = state.RecentErrors[1:]
+			}
+		}
+		writeSSE(res, "progress", state)
+	}
+
+	summary := batchSummary{Total: total, Errors: []s3.BatchResult{}}
+	for _, result := range <-done {
+		if result.Error != "" {
+			summary.Failed++
+			summary.Errors = append(summary.Errors, result)
+		} else {
+			summary.Succeeded++
+		}
+	}
+	writeSSE(res, "summary", summary)
+	return nil
+}
+
+// writeSSE 写入一个SSE事件并立即刷新
+// 参数:
+//
+//	res: 响应
+//	event: 事件名称
+//	data: 事件数据（序列化为JSON）
+func writeSSE(res *echo.Response, event string, data interface{}) {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return
+	}
+	fmt.Fprintf(res, "event: %s\ndata: %s\n\n", event, payload)
+	res.Flush()
+}
+
+// StatBatch 批量获取文件的存在性和元数据，以NDJSON流式返回
+// 请求体：{"bucket": "...", "keys": [...]}；每行一个键的结果
+// {key, exists, size, contentType, etag, lastModified, error}，按完成顺序输出，
+// 客户端无需等待全部HEAD请求完成即可处理。不存在的键exists为false，
+// 上游错误（如权限不足、超时）在error中报告
+// 参数:
+//
+//	ctx: Echo上下文
+//
+// 返回值:
+//
+//	error: 错误信息
+func (c *S3Controller) StatBatch(ctx echo.Context) error {
+	req, keys, err := c.bindBatchRequest(ctx)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, map[string]string{
+			"error": err.Error(),
+		})
+	}
+
+	res := ctx.Response()
+	res.Header().Set(echo.HeaderContentType, "application/x-ndjson")
+	res.Header().Set("X-Accel-Buffering", "no")
+	res.WriteHeader(http.StatusOK)
+
+	results := make(chan s3.StatResult, c.cfg.WorkerConcurrency)
+	go func() {
+		c.service.StatObjects(ctx.Request().Context(), req.Bucket, keys, results)
+		close(results)
+	}()
+
+	// 客户端断开后写入失败也要继续读取通道，直到工作协程全部结束
+	encoder := json.NewEncoder(res)
+	for result := range results {
+		if err := encoder.Encode(result); err == nil {
+			res.Flush()
+		}
+	}
+	return nil
+}
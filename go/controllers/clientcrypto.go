@@ -0,0 +1,87 @@
+// Package controllers 处理HTTP请求
+// 作者: KO
+// 创建时间: 2026-10-15
+// 修改时间: 2026-10-15
+package controllers
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+
+	"github.com/example/s3service/s3"
+	"github.com/labstack/echo/v4"
+)
+
+// HeaderEncryptionKey 客户端加密使用的请求头，值为Base64编码的AES密钥
+// 注意：该请求头的值不得出现在任何日志或错误信息中
+const HeaderEncryptionKey = "X-Encryption-Key"
+
+// downloadDecrypted 下载客户端加密的对象并流式返回明文
+// 在写出响应头之前先解密第一块，密钥错误或数据损坏时可以返回明确的422；
+// 之后的分块认证失败时响应已经开始，只能中断连接，避免客户端把截断的内容当作完整文件
+// 参数:
+//
+//	ctx: Echo上下文
+//	bucket: 存储桶名称
+//	key: 文件键
+//	versionID: 版本ID（为空时下载最新版本）
+//	encodedKey: Base64编码的AES密钥
+//
+// 返回值:
+//
+//	error: 错误信息
+func (c *S3Controller) downloadDecrypted(ctx echo.Context, bucket, key, versionID, encodedKey string) error {
+	encryptionKey, err := s3.ParseEncryptionKey(encodedKey)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, map[string]string{
+			"error": err.Error(),
+		})
+	}
+
+	body, err := c.service.DownloadDecrypted(ctx.Request().Context(), bucket, key, versionID, encryptionKey)
+	if err != nil {
+		if s3.IsNotFound(err) {
+			return ctx.JSON(http.StatusNotFound, map[string]string{
+				"error": "File not found: " + key,
+			})
+		}
+		if errors.Is(err, s3.ErrNotClientEncrypted) {
+			return ctx.JSON(http.StatusUnprocessableEntity, map[string]string{
+				"error": err.Error(),
+			})
+		}
+		return ctx.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Failed to download file: " + err.Error(),
+		})
+	}
+	defer body.Close()
+
+	reader := bufio.NewReaderSize(body, streamBufferSize)
+	if _, err := reader.Peek(1); err != nil && !errors.Is(err, io.EOF) {
+		if errors.Is(err, s3.ErrDecryptionFailed) {
+			return ctx.JSON(http.StatusUnprocessableEntity, map[string]string{
+				"error": err.Error(),
+			})
+		}
+		return ctx.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Failed to download file: " + err.Error(),
+		})
+	}
+
+	header := ctx.Response().Header()
+	header.Set("Content-Disposition", c.contentDisposition(key))
+	c.applyDownloadPolicy(ctx, key)
+	header.Set(echo.HeaderContentType, "application/octet-stream")
+	ctx.Response().WriteHeader(http.StatusOK)
+
+	if _, err := io.CopyBuffer(ctx.Response(), reader, make([]byte, streamBufferSize)); err != nil {
+		if errors.Is(err, s3.ErrDecryptionFailed) {
+			log.Printf("Aborting decrypted download of %s: %v", key, err)
+			panic(http.ErrAbortHandler)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,44 @@
+// Package controllers 处理HTTP请求
+// 作者: KO
+// 创建时间: 2026-10-15
+// 修改时间: 2026-10-15
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+// RecordedCalls 返回记录的S3调用，仅用于集成测试（debug_record_calls），生产环境不应启用
+// 查询参数operation只返回该操作的调用（如PutObject）
+// 参数:
+//
+//	ctx: Echo上下文
+//
+// 返回值:
+//
+//	error: 错误信息
+func (c *S3Controller) RecordedCalls(ctx echo.Context) error {
+	calls, enabled := c.service.RecordedCalls(ctx.QueryParam("operation"))
+	if !enabled {
+		return ctx.JSON(http.StatusNotFound, map[string]string{
+			"error": "Call recording is disabled (debug_record_calls)",
+		})
+	}
+
+	return ctx.JSON(http.StatusOK, calls)
+}
+
+// ResetRecordedCalls 清空记录的S3调用，供测试用例之间调用
+// 参数:
+//
+//	ctx: Echo上下文
+//
+// 返回值:
+//
+//	error: 错误信息
+func (c *S3Controller) ResetRecordedCalls(ctx echo.Context) error {
+	c.service.ResetRecordedCalls()
+	return ctx.NoContent(http.StatusNoContent)
+}
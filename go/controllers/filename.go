@@ -0,0 +1,76 @@
+// Package controllers 处理HTTP请求
+// 作者: KO
+// 创建时间: 2026-10-15
+// 修改时间: 2026-10-15
+package controllers
+
+import (
+	"encoding/base64"
+	"net/url"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding/htmlindex"
+)
+
+// 保存原始文件名的用户元数据键
+const (
+	originalFilenameKey        = "original-filename"         // 原始文件名字节（base64）
+	originalFilenameCharsetKey = "original-filename-charset" // 原始文件名的字符集
+)
+
+// decodeFilename 将遗留系统以filename_charset编码（如Shift_JIS、Latin-1）上传的文件名转码为UTF-8
+// 已经是合法UTF-8的文件名或未配置字符集时原样返回
+// 参数:
+//
+//	name: 客户端提交的文件名
+//
+// 返回值:
+//
+//	string: UTF-8文件名
+//	map[string]string: 记录原始字节和字符集的元数据（未转码时为nil）
+func (c *S3Controller) decodeFilename(name string) (string, map[string]string) {
+	if c.cfg.FilenameCharset == "" || utf8.ValidString(name) {
+		return name, nil
+	}
+
+	enc, err := htmlindex.Get(c.cfg.FilenameCharset)
+	if err != nil {
+		return name, nil
+	}
+	decoded, err := enc.NewDecoder().String(name)
+	if err != nil {
+		return name, nil
+	}
+
+	return decoded, map[string]string{
+		originalFilenameKey:        base64.StdEncoding.EncodeToString([]byte(name)),
+		originalFilenameCharsetKey: c.cfg.FilenameCharset,
+	}
+}
+
+// contentDisposition 构造附件形式的Content-Disposition
+// 配置了filename_charset时，filename参数按该字符集编码，供只识别本地编码的遗留客户端使用，
+// 同时通过filename*提供UTF-8文件名；未配置时保持原有格式
+// 参数:
+//
+//	name: 文件名
+//
+// 返回值:
+//
+//	string: Content-Disposition值
+func (c *S3Controller) contentDisposition(name string) string {
+	if c.cfg.FilenameCharset == "" {
+		return "attachment; filename=" + name
+	}
+
+	legacy := name
+	if enc, err := htmlindex.Get(c.cfg.FilenameCharset); err == nil {
+		if encoded, err := enc.NewEncoder().String(name); err == nil {
+			legacy = encoded
+		}
+	}
+	legacy = strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(legacy)
+
+	return `attachment; filename="` + legacy + `"; filename*=UTF-8''` + url.PathEscape(name)
+}
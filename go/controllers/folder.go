@@ -0,0 +1,87 @@
+// Package controllers 处理HTTP请求
+// 作者: KO
+// 创建时间: 2026-10-15
+// 修改时间: 2026-10-15
+package controllers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/example/s3service/s3"
+	"github.com/labstack/echo/v4"
+)
+
+// folderRequest 创建文件夹的请求体
+type folderRequest struct {
+	Bucket string `json:"bucket"` // 存储桶名称（为空时使用默认存储桶）
+	Prefix string `json:"prefix"` // 文件夹前缀
+}
+
+// CreateFolder 创建文件夹标记（以/结尾的0字节对象），使空文件夹在列举时保留并显示
+// 请求体：{"bucket": "...", "prefix": "photos/2026/"}，前缀不以/结尾时自动补上
+// 参数:
+//
+//	ctx: Echo上下文
+//
+// 返回值:
+//
+//	error: 错误信息
+func (c *S3Controller) CreateFolder(ctx echo.Context) error {
+	var req folderRequest
+	if err := ctx.Bind(&req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Invalid request body",
+		})
+	}
+
+	key, err := c.service.CreateFolder(ctx.Request().Context(), req.Bucket, req.Prefix)
+	if err != nil {
+		if body, ok := immutableError(err); ok {
+			return ctx.JSON(http.StatusForbidden, body)
+		}
+		if errors.Is(err, s3.ErrEmptyPrefix) {
+			return ctx.JSON(http.StatusBadRequest, map[string]string{
+				"error": err.Error(),
+			})
+		}
+		return ctx.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Failed to create folder: " + err.Error(),
+		})
+	}
+
+	return ctx.JSON(http.StatusCreated, map[string]string{
+		"message": "Folder created successfully with key: " + key,
+		"key":     key,
+	})
+}
+
+// DeletePrefix 删除前缀下的全部对象
+// 查询参数：bucket、prefix（必填）；removeMarker=true时一并删除prefix本身的文件夹标记，
+// 否则保留，使清空后的文件夹仍然存在。响应格式和状态码同批量删除
+// 参数:
+//
+//	ctx: Echo上下文
+//
+// 返回值:
+//
+//	error: 错误信息
+func (c *S3Controller) DeletePrefix(ctx echo.Context) error {
+	result, err := c.service.DeletePrefix(ctx.Request().Context(), ctx.QueryParam("bucket"), ctx.QueryParam("prefix"), ctx.QueryParam("removeMarker") == "true")
+	if err != nil {
+		if errors.Is(err, s3.ErrEmptyPrefix) {
+			return ctx.JSON(http.StatusBadRequest, map[string]string{
+				"error": err.Error(),
+			})
+		}
+		return ctx.JSON(http.StatusBadGateway, map[string]string{
+			"error": "Failed to delete prefix: " + err.Error(),
+		})
+	}
+
+	status := http.StatusOK
+	if len(result.Errors) > 0 {
+		status = http.StatusMultiStatus
+	}
+	return ctx.JSON(status, result)
+}
@@ -0,0 +1,32 @@
+// Package controllers 处理HTTP请求
+// 作者: KO
+// 创建时间: 2026-10-15
+// 修改时间: 2026-10-15
+package controllers
+
+import (
+	"errors"
+	"time"
+
+	"github.com/example/s3service/s3"
+)
+
+// immutableError 判断错误是否表示对象处于不可变窗口内，是时返回403响应体（包含解锁时间）
+// 参数:
+//
+//	err: 服务返回的错误
+//
+// 返回值:
+//
+//	map[string]string: 响应体
+//	bool: 是否为不可变窗口错误
+func immutableError(err error) (map[string]string, bool) {
+	var immutable *s3.ImmutableError
+	if !errors.As(err, &immutable) {
+		return nil, false
+	}
+	return map[string]string{
+		"error":    immutable.Error(),
+		"unlockAt": immutable.UnlockAt.UTC().Format(time.RFC3339),
+	}, true
+}
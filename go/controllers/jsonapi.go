@@ -0,0 +1,139 @@
+// Package controllers 处理HTTP请求
+// 作者: KO
+// 创建时间: 2026-10-15
+// 修改时间: 2026-10-15
+package controllers
+
+import (
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/example/s3service/s3"
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	// jsonAPIMediaType JSON:API规定的媒体类型
+	jsonAPIMediaType = "application/vnd.api+json"
+	// jsonAPIDefaultPageSize 默认每页对象数
+	jsonAPIDefaultPageSize = 100
+	// jsonAPIMaxPageSize 每页对象数上限（与ListObjectsV2的上限一致）
+	jsonAPIMaxPageSize = 1000
+)
+
+// jsonAPIResource JSON:API资源对象
+type jsonAPIResource struct {
+	Type       string                 `json:"type"`       // 资源类型
+	ID         string                 `json:"id"`         // 资源ID（文件键）
+	Attributes map[string]interface{} `json:"attributes"` // 资源属性
+}
+
+// jsonAPIDocument JSON:API顶层文档
+type jsonAPIDocument struct {
+	Data  []jsonAPIResource      `json:"data"`  // 资源列表
+	Meta  map[string]interface{} `json:"meta"`  // 分页元信息
+	Links map[string]interface{} `json:"links"` // 分页链接
+}
+
+// listFilesJSONAPI 以JSON:API文档分页返回文件列表
+// 分页参数为page[size]和page[cursor]；links.next是可直接请求的绝对URL，
+// 其中携带续传令牌和已返回的对象数（page[offset]），用于估算总数。
+// S3不提供对象总数，meta.totalEstimate是已知的下界：没有下一页时为准确值
+// 参数:
+//
+//	ctx: Echo上下文
+//	bucket: 存储桶名称
+//
+// 返回值:
+//
+//	error: 错误信息
+func (c *S3Controller) listFilesJSONAPI(ctx echo.Context, bucket string) error {
+	pageSize := jsonAPIDefaultPageSize
+	if raw := ctx.QueryParam("page[size]"); raw != "" {
+		size, err := strconv.Atoi(raw)
+		if err != nil || size < 1 || size > jsonAPIMaxPageSize {
+			return ctx.JSON(http.StatusBadRequest, map[string]string{
+				"error": "Invalid page[size]: must be between 1 and " + strconv.Itoa(jsonAPIMaxPageSize),
+			})
+		}
+		pageSize = size
+	}
+
+	offset := 0
+	if raw := ctx.QueryParam("page[offset]"); raw != "" {
+		value, err := strconv.Atoi(raw)
+		if err != nil || value < 0 {
+			return ctx.JSON(http.StatusBadRequest, map[string]string{
+				"error": "Invalid page[offset]: " + raw,
+			})
+		}
+		offset = value
+	}
+
+	page, err := c.service.ListFilesPage(ctx.Request().Context(), bucket, s3.ListPageOptions{
+		Prefix:            ctx.QueryParam("prefix"),
+		MaxKeys:           int32(pageSize),
+		ContinuationToken: ctx.QueryParam("page[cursor]"),
+	})
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Failed to list files: " + err.Error(),
+		})
+	}
+
+	data := make([]jsonAPIResource, 0, len(page.Files))
+	for _, file := range page.Files {
+		key, _ := file["key"].(string)
+		attributes := make(map[string]interface{}, len(file)-1)
+		for name, value := range file {
+			if name != "key" {
+				attributes[name] = value
+			}
+		}
+		data = append(data, jsonAPIResource{Type: "objects", ID: key, Attributes: attributes})
+	}
+
+	seen := offset + len(data)
+	links := map[string]interface{}{
+		"self": absoluteURL(ctx, ctx.Request().URL.Query()),
+		"next": nil,
+	}
+	if page.NextToken != "" {
+		query := ctx.Request().URL.Query()
+		query.Set("page[cursor]", page.NextToken)
+		query.Set("page[offset]", strconv.Itoa(seen))
+		query.Set("page[size]", strconv.Itoa(pageSize))
+		links["next"] = absoluteURL(ctx, query)
+	}
+
+	ctx.Response().Header().Set(echo.HeaderContentType, jsonAPIMediaType)
+	return ctx.JSON(http.StatusOK, jsonAPIDocument{
+		Data: data,
+		Meta: map[string]interface{}{
+			"pageSize":      pageSize,
+			"totalEstimate": seen,
+			"hasMore":       page.NextToken != "",
+		},
+		Links: links,
+	})
+}
+
+// absoluteURL 以当前请求的协议、主机和路径构造带指定查询参数的绝对URL
+// 参数:
+//
+//	ctx: Echo上下文
+//	query: 查询参数
+//
+// 返回值:
+//
+//	string: 绝对URL
+func absoluteURL(ctx echo.Context, query url.Values) string {
+	u := url.URL{
+		Scheme:   ctx.Scheme(),
+		Host:     ctx.Request().Host,
+		Path:     ctx.Request().URL.Path,
+		RawQuery: query.Encode(),
+	}
+	return u.String()
+}
@@ -0,0 +1,33 @@
+// Package controllers 处理HTTP请求
+// 作者: KO
+// 创建时间: 2026-10-15
+// 修改时间: 2026-10-15
+package controllers
+
+import (
+	"net/url"
+
+	"github.com/labstack/echo/v4"
+)
+
+// keyParam 读取通配路径参数中的对象键，键中可以包含/（如2026/01/report.pdf）
+// 请求路径含有%2F等非规范编码时，Echo按原始路径匹配路由，参数保持编码形式，此时解码后返回；
+// 否则Echo匹配的已是解码后的路径，不能再次解码（如100%.txt）。
+// net/http已拒绝编码不合法的请求路径，解码失败时原样返回
+// 参数:
+//
+//	ctx: Echo上下文
+//
+// 返回值:
+//
+//	string: 对象键
+func keyParam(ctx echo.Context) string {
+	key := ctx.Param("*")
+	if ctx.Request().URL.RawPath == "" {
+		return key
+	}
+	if decoded, err := url.PathUnescape(key); err == nil {
+		return decoded
+	}
+	return key
+}
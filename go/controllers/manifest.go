@@ -0,0 +1,207 @@
+// Package controllers 处理HTTP请求
+// 作者: KO
+// 创建时间: 2026-10-15
+// 修改时间: 2026-10-15
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/example/s3service/s3"
+	"github.com/labstack/echo/v4"
+)
+
+// minPresignExpiry 预签名URL的最短有效期
+const minPresignExpiry = time.Second
+
+// maxPresignExpiry SigV4预签名URL的最长有效期
+const maxPresignExpiry = 7 * 24 * time.Hour
+
+// manifestEntry 下载清单中的一项
+type manifestEntry struct {
+	Key   string      `json:"key"`             // 文件键
+	Size  interface{} `json:"size"`            // 文件大小（字节）
+	URL   string      `json:"url,omitempty"`   // 下载URL（代理URL或预签名URL）
+	Error string      `json:"error,omitempty"` // 生成预签名URL失败时的错误信息
+}
+
+// manifestOptions 生成下载清单的参数
+type manifestOptions struct {
+	bucket  string        // 存储桶名称
+	prefix  string        // 键前缀
+	presign bool          // 是否生成预签名URL
+	expiry  time.Duration // 预签名URL有效期
+	origin  url.URL       // 本服务的协议和主机，用于构造代理下载URL
+}
+
+// ManifestURLs 以JSON数组流式返回前缀下每个对象的下载URL
+// 查询参数：bucket、prefix；presign=true时返回预签名URL（有效期由expiry指定，如1h，默认同download_redirect_expiry），
+// 否则返回本服务的代理下载URL。逐页列举前缀，每页的预签名URL并发生成，写完一页即刷新到客户端。
+// store=true时不在响应中返回清单，而是在后台生成并存储为对象（按report_gzip压缩），
+// 立即返回202和报告的下载预签名URL，适合不宜长时间占用连接的超大清单
+// 参数:
+//
+//	ctx: Echo上下文
+//
+// 返回值:
+//
+//	error: 错误信息
+func (c *S3Controller) ManifestURLs(ctx echo.Context) error {
+	opts := manifestOptions{
+		bucket:  ctx.QueryParam("bucket"),
+		prefix:  ctx.QueryParam("prefix"),
+		presign: ctx.QueryParam("presign") == "true",
+		expiry:  c.cfg.DownloadRedirectExpiry,
+		origin:  url.URL{Scheme: ctx.Scheme(), Host: ctx.Request().Host},
+	}
+	if value := ctx.QueryParam("expiry"); value != "" {
+		parsed, err := time.ParseDuration(value)
+		if err != nil || parsed <= 0 || parsed > maxPresignExpiry {
+			return ctx.JSON(http.StatusBadRequest, map[string]string{
+				"error": "Invalid expiry: must be a positive duration of at most " + maxPresignExpiry.String(),
+			})
+		}
+		opts.expiry = parsed
+	}
+
+	// 先取第一页，列举失败时仍可返回正常的错误响应
+	page, err := c.service.ListFilesPage(ctx.Request().Context(), opts.bucket, s3.ListPageOptions{Prefix: opts.prefix})
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Failed to list files: " + err.Error(),
+		})
+	}
+
+	if ctx.QueryParam("store") == "true" {
+		report, err := c.service.StartReport("manifest", opts.expiry, func(reportCtx context.Context, w io.Writer) error {
+			return c.writeManifest(reportCtx, w, page, opts, func() {})
+		})
+		if errors.Is(err, s3.ErrTooManyReports) {
+			return ctx.JSON(http.StatusTooManyRequests, map[string]string{
+				"error": err.Error(),
+			})
+		}
+		if err != nil {
+			return ctx.JSON(http.StatusInternalServerError, map[string]string{
+				"error": "Failed to start manifest report: " + err.Error(),
+			})
+		}
+		return ctx.JSON(http.StatusAccepted, report)
+	}
+
+	res := ctx.Response()
+	res.Header().Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	res.WriteHeader(http.StatusOK)
+
+	return c.writeManifest(ctx.Request().Context(), res, page, opts, res.Flush)
+}
+
+// writeManifest 从第一页开始逐页列举，将下载清单以JSON数组写入w
+// 参数:
+//
+//	ctx: 上下文
+//	w: 输出
+//	page: 已取得的第一页
+//	opts: 清单参数
+//	flush: 每写完一页后调用
+//
+// 返回值:
+//
+//	error: 错误信息（已写出部分内容时，输出是不完整的JSON）
+func (c *S3Controller) writeManifest(ctx context.Context, w io.Writer, page *s3.FilePage, opts manifestOptions, flush func()) error {
+	separator := "[\n"
+	for {
+		for _, entry := range c.manifestEntries(ctx, page, opts) {
+			data, err := json.Marshal(entry)
+			if err != nil {
+				return err
+			}
+			if _, err := w.Write(append([]byte(separator), data...)); err != nil {
+				return err
+			}
+			separator = ",\n"
+		}
+		flush()
+
+		if page.NextToken == "" {
+			break
+		}
+		var err error
+		page, err = c.service.ListFilesPage(ctx, opts.bucket, s3.ListPageOptions{
+			Prefix:            opts.prefix,
+			ContinuationToken: page.NextToken,
+		})
+		if err != nil {
+			// 输出已开始，只能中断，读取方会得到不完整的JSON
+			return err
+		}
+	}
+	// 没有任何对象时还未输出左括号
+	closing := "\n]\n"
+	if separator == "[\n" {
+		closing = "[]\n"
+	}
+	_, err := w.Write([]byte(closing))
+	return err
+}
+
+// manifestEntries 为一页文件生成下载清单项
+// 参数:
+//
+//	ctx: 上下文
+//	page: 一页文件列表
+//	opts: 清单参数
+//
+// 返回值:
+//
+//	[]manifestEntry: 清单项
+func (c *S3Controller) manifestEntries(ctx context.Context, page *s3.FilePage, opts manifestOptions) []manifestEntry {
+	entries := make([]manifestEntry, len(page.Files))
+	keys := make([]string, len(page.Files))
+	for i, file := range page.Files {
+		keys[i], _ = file["key"].(string)
+		entries[i] = manifestEntry{Key: keys[i], Size: file["size"]}
+	}
+
+	if opts.presign {
+		urls, errs := c.service.PresignDownloadURLs(ctx, opts.bucket, keys, opts.expiry)
+		for i := range entries {
+			if errs[i] != nil {
+				entries[i].Error = errs[i].Error()
+				continue
+			}
+			entries[i].URL = urls[i]
+		}
+		return entries
+	}
+
+	for i := range entries {
+		entries[i].URL = proxyDownloadURL(opts.origin, opts.bucket, keys[i])
+	}
+	return entries
+}
+
+// proxyDownloadURL 构造通过本服务下载文件的绝对URL
+// 参数:
+//
+//	origin: 本服务的协议和主机
+//	bucket: 存储桶名称（为空时不携带bucket参数）
+//	key: 文件键
+//
+// 返回值:
+//
+//	string: 下载URL
+func proxyDownloadURL(origin url.URL, bucket, key string) string {
+	u := origin
+	u.Path = "/api/s3/download/" + key
+	if bucket != "" {
+		u.RawQuery = url.Values{"bucket": {bucket}}.Encode()
+	}
+	return u.String()
+}
@@ -0,0 +1,119 @@
+// Package controllers 处理HTTP请求
+// 作者: KO
+// 创建时间: 2026-10-15
+// 修改时间: 2026-10-15
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/example/s3service/s3"
+	"github.com/labstack/echo/v4"
+)
+
+// metadataFieldPrefix 作为用户自定义元数据读取的表单字段前缀
+const metadataFieldPrefix = "x-meta-"
+
+// formMetadata 从上传表单中读取用户自定义元数据
+// 支持两种形式：x-meta-<name>表单字段，以及值为JSON对象的metadata字段；
+// 同名时x-meta-*字段优先。名称统一转为小写（S3保存时同样转为小写），
+// 结果与base合并，base中的项（如服务记录的原始文件名）不会被覆盖
+// 参数:
+//
+//	ctx: Echo上下文
+//	base: 服务自身写入的元数据（可为nil）
+//
+// 返回值:
+//
+//	map[string]string: 合并后的元数据（都为空时为nil）
+//	error: metadata字段不是JSON字符串对象时的错误信息
+func formMetadata(ctx echo.Context, base map[string]string) (map[string]string, error) {
+	form, err := ctx.FormParams()
+	if err != nil {
+		return nil, err
+	}
+
+	metadata := make(map[string]string)
+	if raw := form.Get("metadata"); raw != "" {
+		var fields map[string]string
+		if err := json.Unmarshal([]byte(raw), &fields); err != nil {
+			return nil, errors.New("metadata must be a JSON object of string values")
+		}
+		for name, value := range fields {
+			metadata[strings.ToLower(name)] = value
+		}
+	}
+	for field, values := range form {
+		lower := strings.ToLower(field)
+		if !strings.HasPrefix(lower, metadataFieldPrefix) || len(values) == 0 {
+			continue
+		}
+		metadata[strings.TrimPrefix(lower, metadataFieldPrefix)] = values[0]
+	}
+	for name, value := range base {
+		metadata[name] = value
+	}
+
+	if len(metadata) == 0 {
+		return nil, nil
+	}
+	return metadata, nil
+}
+
+// GetMetadata 获取文件的用户自定义元数据
+// 参数:
+//
+//	ctx: Echo上下文
+//
+// 返回值:
+//
+//	error: 错误信息
+func (c *S3Controller) GetMetadata(ctx echo.Context) error {
+	key := keyParam(ctx)
+	bucket := ctx.QueryParam("bucket")
+
+	metadata, err := c.service.GetMetadata(ctx.Request().Context(), bucket, key)
+	if err != nil {
+		if s3.IsNotFound(err) {
+			return ctx.JSON(http.StatusNotFound, map[string]string{
+				"error": "File not found: " + key,
+			})
+		}
+		return ctx.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Failed to get metadata: " + err.Error(),
+		})
+	}
+
+	return ctx.JSON(http.StatusOK, metadata)
+}
+
+// GetObjectDetails 以单个JSON文档返回文件的HEAD信息、用户元数据、标签、ACL、对象锁定设置和版本
+// 各部分并发获取，部分失败时仍返回200，失败原因在errors字段中；对象不存在时返回404
+// 参数:
+//
+//	ctx: Echo上下文
+//
+// 返回值:
+//
+//	error: 错误信息
+func (c *S3Controller) GetObjectDetails(ctx echo.Context) error {
+	key := keyParam(ctx)
+	bucket := ctx.QueryParam("bucket")
+
+	details, err := c.service.GetObjectDetails(ctx.Request().Context(), bucket, key)
+	if err != nil {
+		if s3.IsNotFound(err) {
+			return ctx.JSON(http.StatusNotFound, map[string]string{
+				"error": "File not found: " + key,
+			})
+		}
+		return ctx.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Failed to get file info: " + err.Error(),
+		})
+	}
+
+	return ctx.JSON(http.StatusOK, details)
+}
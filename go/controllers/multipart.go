@@ -0,0 +1,176 @@
+// Package controllers 处理HTTP请求
+// 作者: KO
+// 创建时间: 2026-10-15
+// 修改时间: 2026-10-15
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/example/s3service/s3"
+	"github.com/labstack/echo/v4"
+)
+
+// UploadMultipart 上传文件，大文件自动使用分段上传
+// 文件大小超过multipart_threshold时按multipart_part_size分段上传（任一分段失败都会中止，不留下残余分段），
+// 否则与普通上传一样使用单次PutObject。
+// 分段上传时，Accept为text/event-stream的请求以SSE返回进度（progress事件和最终的complete或error事件），
+// Accept为application/x-ndjson的请求以分块NDJSON逐行返回同样的内容
+// 参数:
+//
+//	ctx: Echo上下文
+//
+// 返回值:
+//
+//	error: 错误信息
+func (c *S3Controller) UploadMultipart(ctx echo.Context) error {
+	file, err := ctx.FormFile("file")
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Invalid file",
+		})
+	}
+
+	src, err := file.Open()
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Failed to open file",
+		})
+	}
+	defer src.Close()
+
+	key := ctx.FormValue("key")
+	if key == "" {
+		key = file.Filename
+	}
+	key, filenameMetadata := c.decodeFilename(key)
+	key = c.service.NormalizeKey(key)
+	bucket := ctx.FormValue("bucket")
+
+	opts := s3.UploadOptions{
+		ContentType: file.Header.Get(echo.HeaderContentType),
+		Metadata:    filenameMetadata,
+	}
+
+	mode := "single"
+	if file.Size > c.cfg.MultipartThreshold {
+		mode = "multipart"
+		if format := progressStreamFormat(ctx); format != "" {
+			return c.streamMultipartUpload(ctx, format, bucket, key, src, file.Size, opts)
+		}
+		// 传入文件大小，上传前即可按上传后的用量检查配额
+		_, err = c.service.UploadMultipartWithProgress(ctx.Request().Context(), bucket, key, src, file.Size, c.cfg.MultipartPartSize, nil, opts)
+	} else {
+		err = c.service.UploadStream(ctx.Request().Context(), bucket, key, src, file.Size, opts)
+	}
+	if err != nil {
+		if body, ok := immutableError(err); ok {
+			return ctx.JSON(http.StatusForbidden, body)
+		}
+		if errors.Is(err, s3.ErrQuotaExceeded) {
+			return ctx.JSON(http.StatusInsufficientStorage, map[string]string{
+				"error": err.Error(),
+			})
+		}
+		if errors.Is(err, s3.ErrInvalidMetadata) {
+			return ctx.JSON(http.StatusBadRequest, map[string]string{
+				"error": err.Error(),
+			})
+		}
+		return ctx.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Failed to upload file: " + err.Error(),
+		})
+	}
+
+	response := map[string]string{
+		"message": "File uploaded successfully with key: " + key,
+		"mode":    mode,
+	}
+	if url := c.service.PublicURL(bucket, key); url != "" {
+		response["url"] = url
+	}
+	return ctx.JSON(http.StatusOK, response)
+}
+
+// progressStreamFormat 根据Accept请求头返回进度流的格式
+// 参数:
+//
+//	ctx: Echo上下文
+//
+// 返回值:
+//
+//	string: sse、ndjson，不需要流式进度时为空
+func progressStreamFormat(ctx echo.Context) string {
+	if wantsEventStream(ctx) {
+		return "sse"
+	}
+	if strings.Contains(ctx.Request().Header.Get(echo.HeaderAccept), "application/x-ndjson") {
+		return "ndjson"
+	}
+	return ""
+}
+
+// multipartProgressEvent 分段上传进度流中的一条消息
+type multipartProgressEvent struct {
+	Type string `json:"type"` // 消息类型：progress、complete、error
+	*s3.MultipartProgress
+	Key   string `json:"key,omitempty"`   // 文件键（complete）
+	ETag  string `json:"etag,omitempty"`  // 完成后对象的ETag（complete）
+	URL   string `json:"url,omitempty"`   // 对象的公开URL（complete，配置了public_url_base时）
+	Error string `json:"error,omitempty"` // 错误信息（error）
+}
+
+// streamMultipartUpload 执行分段上传并以SSE或NDJSON流式返回进度
+// 每个分段开始和完成时各发送一条progress消息，结束时发送complete或error消息；
+// 响应头在上传开始前已发出，上传失败时状态码仍为200，客户端应以最后一条消息为准
+// 参数:
+//
+//	ctx: Echo上下文
+//	format: sse或ndjson
+//	bucket: 存储桶名称
+//	key: 文件键
+//	body: 文件内容
+//	size: 文件大小（字节）
+//	opts: 上传选项（内容类型和元数据）
+//
+// 返回值:
+//
+//	error: 错误信息
+func (c *S3Controller) streamMultipartUpload(ctx echo.Context, format, bucket, key string, body io.Reader, size int64, opts s3.UploadOptions) error {
+	res := ctx.Response()
+	if format == "sse" {
+		res.Header().Set(echo.HeaderContentType, "text/event-stream")
+		res.Header().Set("Cache-Control", "no-cache")
+	} else {
+		res.Header().Set(echo.HeaderContentType, "application/x-ndjson")
+	}
+	res.Header().Set("X-Accel-Buffering", "no")
+	res.WriteHeader(http.StatusOK)
+	res.Flush()
+
+	encoder := json.NewEncoder(res)
+	send := func(event multipartProgressEvent) {
+		if format == "sse" {
+			writeSSE(res, event.Type, event)
+			return
+		}
+		if err := encoder.Encode(event); err == nil {
+			res.Flush()
+		}
+	}
+
+	etag, err := c.service.UploadMultipartWithProgress(ctx.Request().Context(), bucket, key, body, size, c.cfg.MultipartPartSize, func(p s3.MultipartProgress) {
+		send(multipartProgressEvent{Type: "progress", MultipartProgress: &p})
+	}, opts)
+	if err != nil {
+		send(multipartProgressEvent{Type: "error", Error: "Failed to upload file: " + err.Error()})
+		return nil
+	}
+
+	send(multipartProgressEvent{Type: "complete", Key: key, ETag: etag, URL: c.service.PublicURL(bucket, key)})
+	return nil
+}
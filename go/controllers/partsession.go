@@ -0,0 +1,205 @@
+// Package controllers 处理HTTP请求
+// 作者: KO
+// 创建时间: 2026-10-15
+// 修改时间: 2026-10-15
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/example/s3service/s3"
+	"github.com/labstack/echo/v4"
+)
+
+// createSessionRequest 创建分段写入会话的请求体
+type createSessionRequest struct {
+	Bucket    string `json:"bucket"`    // 存储桶名称（为空时使用默认存储桶）
+	Key       string `json:"key"`       // 文件键
+	TotalSize int64  `json:"totalSize"` // 对象总大小（字节）
+	PartSize  int64  `json:"partSize"`  // 分段大小（字节，为空时使用multipart_part_size）
+}
+
+// CreatePartSession 创建按偏移写入的分段上传会话
+// 返回会话ID和分段布局，客户端随后可按任意顺序上传各分段
+// 参数:
+//
+//	ctx: Echo上下文
+//
+// 返回值:
+//
+//	error: 错误信息
+func (c *S3Controller) CreatePartSession(ctx echo.Context) error {
+	var req createSessionRequest
+	if err := ctx.Bind(&req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Invalid request body",
+		})
+	}
+	if req.PartSize == 0 {
+		req.PartSize = c.cfg.MultipartPartSize
+	}
+
+	session, err := c.service.CreatePartSession(ctx.Request().Context(), req.Bucket, c.service.NormalizeKey(req.Key), req.TotalSize, req.PartSize)
+	if err != nil {
+		if body, ok := immutableError(err); ok {
+			return ctx.JSON(http.StatusForbidden, body)
+		}
+		if errors.Is(err, s3.ErrInvalidSession) {
+			return ctx.JSON(http.StatusBadRequest, map[string]string{
+				"error": err.Error(),
+			})
+		}
+		if errors.Is(err, s3.ErrQuotaExceeded) {
+			return ctx.JSON(http.StatusInsufficientStorage, map[string]string{
+				"error": err.Error(),
+			})
+		}
+		return ctx.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Failed to create upload session: " + err.Error(),
+		})
+	}
+
+	return ctx.JSON(http.StatusCreated, session)
+}
+
+// UploadSessionPart 上传会话中的一个分段，请求体为分段的原始字节
+// 通过查询参数number指定分段号，或通过offset指定分段起始偏移（必须与分段边界对齐）
+// 参数:
+//
+//	ctx: Echo上下文
+//
+// 返回值:
+//
+//	error: 错误信息
+func (c *S3Controller) UploadSessionPart(ctx echo.Context) error {
+	id := ctx.Param("id")
+	session, err := c.service.GetPartSession(id)
+	if err != nil {
+		return ctx.JSON(http.StatusNotFound, map[string]string{
+			"error": "Upload session not found: " + id,
+		})
+	}
+
+	numberParam := ctx.QueryParam("number")
+	offsetParam := ctx.QueryParam("offset")
+	if (numberParam == "") == (offsetParam == "") {
+		return ctx.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Exactly one of number or offset is required",
+		})
+	}
+
+	var number int32
+	if numberParam != "" {
+		parsed, err := strconv.ParseInt(numberParam, 10, 32)
+		if err != nil {
+			return ctx.JSON(http.StatusBadRequest, map[string]string{
+				"error": "Invalid number: " + numberParam,
+			})
+		}
+		number = int32(parsed)
+	} else {
+		offset, err := strconv.ParseInt(offsetParam, 10, 64)
+		if err != nil {
+			return ctx.JSON(http.StatusBadRequest, map[string]string{
+				"error": "Invalid offset: " + offsetParam,
+			})
+		}
+		if number, err = session.PartNumberForOffset(offset); err != nil {
+			return ctx.JSON(http.StatusBadRequest, map[string]string{
+				"error": err.Error(),
+			})
+		}
+	}
+
+	// 没有Content-Length（chunked）时ContentLength为-1，由服务读取内容后校验长度
+	size := ctx.Request().ContentLength
+
+	if err := c.service.UploadSessionPart(ctx.Request().Context(), id, number, ctx.Request().Body, size); err != nil {
+		if errors.Is(err, s3.ErrInvalidPart) {
+			return ctx.JSON(http.StatusBadRequest, map[string]string{
+				"error": err.Error(),
+			})
+		}
+		if errors.Is(err, s3.ErrSessionNotFound) {
+			return ctx.JSON(http.StatusNotFound, map[string]string{
+				"error": "Upload session not found: " + id,
+			})
+		}
+		return ctx.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Failed to upload part: " + err.Error(),
+		})
+	}
+
+	return ctx.JSON(http.StatusOK, map[string]interface{}{
+		"sessionId":  id,
+		"partNumber": number,
+	})
+}
+
+// CompletePartSession 校验全部分段均已上传后完成会话，有分段缺失时返回409
+// 参数:
+//
+//	ctx: Echo上下文
+//
+// 返回值:
+//
+//	error: 错误信息
+func (c *S3Controller) CompletePartSession(ctx echo.Context) error {
+	id := ctx.Param("id")
+	session, err := c.service.GetPartSession(id)
+	if err != nil {
+		return ctx.JSON(http.StatusNotFound, map[string]string{
+			"error": "Upload session not found: " + id,
+		})
+	}
+
+	etag, err := c.service.CompletePartSession(ctx.Request().Context(), id)
+	if err != nil {
+		if errors.Is(err, s3.ErrSessionIncomplete) {
+			return ctx.JSON(http.StatusConflict, map[string]string{
+				"error": err.Error(),
+			})
+		}
+		if errors.Is(err, s3.ErrSessionNotFound) {
+			return ctx.JSON(http.StatusNotFound, map[string]string{
+				"error": "Upload session not found: " + id,
+			})
+		}
+		return ctx.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Failed to complete upload session: " + err.Error(),
+		})
+	}
+
+	return ctx.JSON(http.StatusOK, map[string]string{
+		"key":  session.Key,
+		"etag": etag,
+	})
+}
+
+// AbortPartSession 中止会话并丢弃已上传的分段
+// 参数:
+//
+//	ctx: Echo上下文
+//
+// 返回值:
+//
+//	error: 错误信息
+func (c *S3Controller) AbortPartSession(ctx echo.Context) error {
+	id := ctx.Param("id")
+	if err := c.service.AbortPartSession(ctx.Request().Context(), id); err != nil {
+		if errors.Is(err, s3.ErrSessionNotFound) {
+			return ctx.JSON(http.StatusNotFound, map[string]string{
+				"error": "Upload session not found: " + id,
+			})
+		}
+		return ctx.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Failed to abort upload session: " + err.Error(),
+		})
+	}
+
+	return ctx.JSON(http.StatusOK, map[string]string{
+		"message": "Upload session aborted: " + id,
+	})
+}
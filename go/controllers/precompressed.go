@@ -0,0 +1,123 @@
+// Package controllers 处理HTTP请求
+// 作者: KO
+// 创建时间: 2026-10-15
+// 修改时间: 2026-10-15
+package controllers
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+// precompressedEncodings 支持的预压缩编码（按服务端偏好排序，客户端q值相同时优先使用靠前的编码）
+var precompressedEncodings = []string{"br", "gzip"}
+
+// acceptedEncodings 解析Accept-Encoding请求头，返回客户端可接受的预压缩编码
+// q=0的编码视为不可接受；*匹配所有未显式列出的编码
+// 参数:
+//
+//	header: Accept-Encoding请求头
+//
+// 返回值:
+//
+//	[]string: 可接受的编码（按q值降序，q值相同时按服务端偏好）
+func acceptedEncodings(header string) []string {
+	weights := make(map[string]float64)
+	wildcard := -1.0
+	for _, part := range strings.Split(header, ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+
+		q := 1.0
+		if value, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				continue
+			}
+			q = parsed
+		}
+
+		if name == "*" {
+			wildcard = q
+		} else {
+			weights[name] = q
+		}
+	}
+
+	var accepted []string
+	for _, encoding := range precompressedEncodings {
+		q, ok := weights[encoding]
+		if !ok {
+			q = wildcard
+		}
+		if q > 0 {
+			weights[encoding] = q
+			accepted = append(accepted, encoding)
+		}
+	}
+	sort.SliceStable(accepted, func(i, j int) bool {
+		return weights[accepted[i]] > weights[accepted[j]]
+	})
+	return accepted
+}
+
+// servePrecompressed 客户端接受br或gzip编码且对象存在key.br或key.gz变体时，返回该变体
+// 响应使用原对象的内容类型并设置相应的Content-Encoding；没有可用变体时不写出响应，由调用方回退到原对象
+// 参数:
+//
+//	ctx: Echo上下文
+//	bucket: 存储桶名称
+//	key: 原对象的文件键
+//
+// 返回值:
+//
+//	bool: 是否已写出响应
+//	error: 错误信息
+func (c *S3Controller) servePrecompressed(ctx echo.Context, bucket, key string) (bool, error) {
+	encodings := acceptedEncodings(ctx.Request().Header.Get(echo.HeaderAcceptEncoding))
+	if len(encodings) == 0 {
+		return false, nil
+	}
+
+	variant, err := c.service.FindPrecompressed(ctx.Request().Context(), bucket, key, encodings)
+	if err != nil {
+		return true, ctx.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Failed to look up precompressed variants: " + err.Error(),
+		})
+	}
+	if variant == nil {
+		return false, nil
+	}
+
+	body, size, _, err := c.service.DownloadStream(ctx.Request().Context(), bucket, variant.Key)
+	if err != nil {
+		return true, ctx.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Failed to download file: " + err.Error(),
+		})
+	}
+	defer body.Close()
+
+	header := ctx.Response().Header()
+	header.Set("Content-Disposition", c.contentDisposition(key))
+	c.applyDownloadPolicy(ctx, key)
+	header.Set("Content-Length", fmt.Sprintf("%d", size))
+	header.Set(echo.HeaderContentType, c.blobContentType(variant.ContentType))
+	header.Set(echo.HeaderContentEncoding, variant.Encoding)
+	// gzip中间件启用时已添加Vary，避免重复
+	if !strings.Contains(strings.Join(header.Values(echo.HeaderVary), ","), echo.HeaderAcceptEncoding) {
+		header.Add(echo.HeaderVary, echo.HeaderAcceptEncoding)
+	}
+	ctx.Response().WriteHeader(http.StatusOK)
+
+	_, _ = io.Copy(ctx.Response(), body)
+	return true, nil
+}
@@ -0,0 +1,198 @@
+// Package controllers 处理HTTP请求
+// 作者: KO
+// 创建时间: 2026-10-15
+// 修改时间: 2026-10-15
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/example/s3service/s3"
+	"github.com/labstack/echo/v4"
+)
+
+// initDirectUploadRequest 创建直传分段上传的请求体
+type initDirectUploadRequest struct {
+	Bucket      string `json:"bucket"`      // 存储桶名称（为空时使用默认存储桶）
+	Key         string `json:"key"`         // 文件键
+	ContentType string `json:"contentType"` // 内容类型（为空时按扩展名推断）
+	TotalSize   int64  `json:"totalSize"`   // 对象总大小（字节，可选，用于配额检查）
+}
+
+// completeDirectUploadRequest 完成直传分段上传的请求体
+type completeDirectUploadRequest struct {
+	Parts []s3.UploadedPart `json:"parts"` // 已上传的分段及其ETag
+}
+
+// InitDirectUpload 创建由浏览器直接上传分段的分段上传，返回上传ID
+// 客户端随后为每个分段获取预签名URL并行上传，最后提交各分段的ETag完成上传
+// 参数:
+//
+//	ctx: Echo上下文
+//
+// 返回值:
+//
+//	error: 错误信息
+func (c *S3Controller) InitDirectUpload(ctx echo.Context) error {
+	var req initDirectUploadRequest
+	if err := ctx.Bind(&req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Invalid request body",
+		})
+	}
+
+	upload, err := c.service.CreateDirectUpload(ctx.Request().Context(), req.Bucket, c.service.NormalizeKey(req.Key), req.ContentType, req.TotalSize)
+	if err != nil {
+		if body, ok := immutableError(err); ok {
+			return ctx.JSON(http.StatusForbidden, body)
+		}
+		if errors.Is(err, s3.ErrInvalidSession) {
+			return ctx.JSON(http.StatusBadRequest, map[string]string{
+				"error": err.Error(),
+			})
+		}
+		if errors.Is(err, s3.ErrQuotaExceeded) {
+			return ctx.JSON(http.StatusInsufficientStorage, map[string]string{
+				"error": err.Error(),
+			})
+		}
+		return ctx.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Failed to create multipart upload: " + err.Error(),
+		})
+	}
+
+	return ctx.JSON(http.StatusCreated, upload)
+}
+
+// PresignDirectUploadPart 生成直传分段上传中一个分段的预签名PUT URL
+// 查询参数：expiry为有效期（如15m，默认同download_redirect_expiry），须在1秒到7天之间；
+// 客户端上传分段后需保存响应头中的ETag，完成上传时提交
+// 参数:
+//
+//	ctx: Echo上下文
+//
+// 返回值:
+//
+//	error: 错误信息
+func (c *S3Controller) PresignDirectUploadPart(ctx echo.Context) error {
+	id := ctx.Param("id")
+	numberParam := ctx.Param("num")
+	number, err := strconv.ParseInt(numberParam, 10, 32)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Invalid part number: " + numberParam,
+		})
+	}
+
+	expiry := c.cfg.DownloadRedirectExpiry
+	if value := ctx.QueryParam("expiry"); value != "" {
+		parsed, err := time.ParseDuration(value)
+		if err != nil || parsed < minPresignExpiry || parsed > maxPresignExpiry {
+			return ctx.JSON(http.StatusBadRequest, map[string]string{
+				"error": "Invalid expiry: must be between " + minPresignExpiry.String() + " and " + maxPresignExpiry.String(),
+			})
+		}
+		expiry = parsed
+	}
+
+	if status, err := c.checkPresignConstraints(ctx); err != nil {
+		return ctx.JSON(status, map[string]string{
+			"error": err.Error(),
+		})
+	}
+
+	expiresAt := time.Now().Add(expiry)
+	url, err := c.service.PresignDirectUploadPart(ctx.Request().Context(), id, int32(number), expiry)
+	if err != nil {
+		if errors.Is(err, s3.ErrInvalidPart) {
+			return ctx.JSON(http.StatusBadRequest, map[string]string{
+				"error": err.Error(),
+			})
+		}
+		if errors.Is(err, s3.ErrSessionNotFound) {
+			return ctx.JSON(http.StatusNotFound, map[string]string{
+				"error": "Multipart upload not found: " + id,
+			})
+		}
+		return ctx.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Failed to presign part upload URL: " + err.Error(),
+		})
+	}
+
+	return ctx.JSON(http.StatusOK, map[string]interface{}{
+		"url":        url,
+		"method":     http.MethodPut,
+		"partNumber": number,
+		"expiresAt":  expiresAt.UTC().Format(time.RFC3339),
+	})
+}
+
+// CompleteDirectUpload 按客户端提交的分段ETag完成直传分段上传
+// 请求体：{"parts": [{"partNumber": 1, "etag": "..."}, ...]}
+// 参数:
+//
+//	ctx: Echo上下文
+//
+// 返回值:
+//
+//	error: 错误信息
+func (c *S3Controller) CompleteDirectUpload(ctx echo.Context) error {
+	id := ctx.Param("id")
+	var req completeDirectUploadRequest
+	if err := ctx.Bind(&req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Invalid request body",
+		})
+	}
+
+	upload, etag, err := c.service.CompleteDirectUpload(ctx.Request().Context(), id, req.Parts)
+	if err != nil {
+		if errors.Is(err, s3.ErrInvalidPart) {
+			return ctx.JSON(http.StatusBadRequest, map[string]string{
+				"error": err.Error(),
+			})
+		}
+		if errors.Is(err, s3.ErrSessionNotFound) {
+			return ctx.JSON(http.StatusNotFound, map[string]string{
+				"error": "Multipart upload not found: " + id,
+			})
+		}
+		return ctx.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Failed to complete multipart upload: " + err.Error(),
+		})
+	}
+
+	return ctx.JSON(http.StatusOK, map[string]string{
+		"key":  upload.Key,
+		"etag": etag,
+	})
+}
+
+// AbortDirectUpload 中止直传分段上传并丢弃已上传的分段
+// 参数:
+//
+//	ctx: Echo上下文
+//
+// 返回值:
+//
+//	error: 错误信息
+func (c *S3Controller) AbortDirectUpload(ctx echo.Context) error {
+	id := ctx.Param("id")
+	if err := c.service.AbortDirectUpload(ctx.Request().Context(), id); err != nil {
+		if errors.Is(err, s3.ErrSessionNotFound) {
+			return ctx.JSON(http.StatusNotFound, map[string]string{
+				"error": "Multipart upload not found: " + id,
+			})
+		}
+		return ctx.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Failed to abort multipart upload: " + err.Error(),
+		})
+	}
+
+	return ctx.JSON(http.StatusOK, map[string]string{
+		"message": "Multipart upload aborted: " + id,
+	})
+}
@@ -0,0 +1,199 @@
+// Package controllers 处理HTTP请求
+// 作者: KO
+// 创建时间: 2026-10-15
+// 修改时间: 2026-10-15
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"strconv"
+	"strings"
+
+	"github.com/example/s3service/s3"
+	"github.com/labstack/echo/v4"
+)
+
+// maxRanges 单个请求允许的最大范围数，超过时忽略Range返回完整内容
+const maxRanges = 16
+
+var (
+	// errInvalidRange Range请求头语法无效（按规范应忽略该请求头）
+	errInvalidRange = errors.New("invalid range")
+	// errUnsatisfiableRange Range请求头中没有任何可满足的范围
+	errUnsatisfiableRange = errors.New("unsatisfiable range")
+)
+
+// httpRange 一个字节范围
+type httpRange struct {
+	start  int64 // 起始偏移
+	length int64 // 长度
+}
+
+// header 返回S3 GetObject使用的Range值
+// 返回值:
+//
+//	string: 如bytes=0-1023
+func (r httpRange) header() string {
+	return fmt.Sprintf("bytes=%d-%d", r.start, r.start+r.length-1)
+}
+
+// contentRange 返回该范围对应的Content-Range响应头
+// 参数:
+//
+//	size: 对象总大小
+//
+// 返回值:
+//
+//	string: 如bytes 0-1023/4096
+func (r httpRange) contentRange(size int64) string {
+	return fmt.Sprintf("bytes %d-%d/%d", r.start, r.start+r.length-1, size)
+}
+
+// parseRange 按RFC 7233解析Range请求头
+// 不可满足的单个范围会被丢弃，全部不可满足时返回errUnsatisfiableRange
+// 参数:
+//
+//	header: Range请求头
+//	size: 对象总大小
+//
+// 返回值:
+//
+//	[]httpRange: 解析出的范围
+//	error: 语法无效时返回errInvalidRange，全部不可满足时返回errUnsatisfiableRange
+func parseRange(header string, size int64) ([]httpRange, error) {
+	const prefix = "bytes="
+	if !strings.HasPrefix(header, prefix) {
+		return nil, errInvalidRange
+	}
+
+	var ranges []httpRange
+	for _, spec := range strings.Split(header[len(prefix):], ",") {
+		spec = strings.TrimSpace(spec)
+		if spec == "" {
+			continue
+		}
+		i := strings.Index(spec, "-")
+		if i < 0 {
+			return nil, errInvalidRange
+		}
+		startStr, endStr := strings.TrimSpace(spec[:i]), strings.TrimSpace(spec[i+1:])
+
+		var r httpRange
+		if startStr == "" {
+			// 后缀范围：bytes=-N 表示最后N个字节
+			n, err := strconv.ParseInt(endStr, 10, 64)
+			if err != nil || n < 0 {
+				return nil, errInvalidRange
+			}
+			if n == 0 || size == 0 {
+				continue
+			}
+			if n > size {
+				n = size
+			}
+			r = httpRange{start: size - n, length: n}
+		} else {
+			start, err := strconv.ParseInt(startStr, 10, 64)
+			if err != nil || start < 0 {
+				return nil, errInvalidRange
+			}
+			if start >= size {
+				// 起始位置超出对象大小，该范围不可满足
+				continue
+			}
+			end := size - 1
+			if endStr != "" {
+				end, err = strconv.ParseInt(endStr, 10, 64)
+				if err != nil || end < start {
+					return nil, errInvalidRange
+				}
+				if end >= size {
+					end = size - 1
+				}
+			}
+			r = httpRange{start: start, length: end - start + 1}
+		}
+		ranges = append(ranges, r)
+	}
+
+	if len(ranges) == 0 {
+		return nil, errUnsatisfiableRange
+	}
+
+	return ranges, nil
+}
+
+// serveRanges 以206响应返回对象的一个或多个范围
+// 单个范围直接返回并设置Content-Range；多个范围以multipart/byteranges返回
+// 参数:
+//
+//	ctx: Echo上下文
+//	bucket: 存储桶名称
+//	info: 对象元数据
+//	ranges: 请求的范围
+//
+// 返回值:
+//
+//	error: 错误信息
+func (c *S3Controller) serveRanges(ctx echo.Context, bucket string, info *s3.ObjectInfo, ranges []httpRange) error {
+	contentType := c.service.DownloadContentType(info.ContentType)
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+
+	header := ctx.Response().Header()
+	header.Set("Accept-Ranges", "bytes")
+
+	if len(ranges) == 1 {
+		r := ranges[0]
+		body, _, err := c.service.DownloadRange(ctx.Request().Context(), bucket, info.Key, r.header())
+		if err != nil {
+			// 对象在HEAD之后被替换为更小的内容时，S3返回InvalidRange
+			if s3.IsInvalidRange(err) {
+				return ctx.JSON(http.StatusRequestedRangeNotSatisfiable, map[string]string{
+					"error": "Range not satisfiable: " + r.header(),
+				})
+			}
+			return ctx.JSON(http.StatusInternalServerError, map[string]string{
+				"error": "Failed to download file: " + err.Error(),
+			})
+		}
+		defer body.Close()
+
+		header.Set("Content-Range", r.contentRange(info.Size))
+		header.Set("Content-Length", strconv.FormatInt(r.length, 10))
+		return ctx.Stream(http.StatusPartialContent, contentType, body)
+	}
+
+	writer := multipart.NewWriter(ctx.Response())
+	header.Set(echo.HeaderContentType, "multipart/byteranges; boundary="+writer.Boundary())
+	ctx.Response().WriteHeader(http.StatusPartialContent)
+
+	for _, r := range ranges {
+		part, err := writer.CreatePart(textproto.MIMEHeader{
+			"Content-Type":  {contentType},
+			"Content-Range": {r.contentRange(info.Size)},
+		})
+		if err != nil {
+			return err
+		}
+
+		// 响应头已经发出，后续出错只能中断连接
+		body, _, err := c.service.DownloadRange(ctx.Request().Context(), bucket, info.Key, r.header())
+		if err != nil {
+			return err
+		}
+		_, err = io.Copy(part, body)
+		body.Close()
+		if err != nil {
+			return err
+		}
+	}
+
+	return writer.Close()
+}
@@ -0,0 +1,65 @@
+// Package controllers 处理HTTP请求
+// 作者: KO
+// 创建时间: 2026-10-15
+// 修改时间: 2026-10-15
+package controllers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/example/s3service/s3"
+	"github.com/labstack/echo/v4"
+)
+
+// UploadRaw 以原始请求体上传文件（非multipart/form-data），支持Transfer-Encoding: chunked
+// 请求体不可Seek，服务先读取一遍（没有Content-Length时同时确定长度）再上传：
+// 不超过chunked_memory_limit的内容在内存中缓冲，更大的内容写入临时文件，不会在内存中缓冲整个请求体
+// 参数:
+//
+//	ctx: Echo上下文
+//
+// 返回值:
+//
+//	error: 错误信息
+func (c *S3Controller) UploadRaw(ctx echo.Context) error {
+	key := c.service.NormalizeKey(keyParam(ctx))
+	if key == "" {
+		return ctx.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Key is required",
+		})
+	}
+	bucket := ctx.QueryParam("bucket")
+
+	req := ctx.Request()
+	opts := s3.UploadOptions{
+		ContentType: req.Header.Get(echo.HeaderContentType),
+	}
+	// ContentLength为-1表示长度未知（chunked），由服务缓冲后确定
+	if err := c.service.UploadStream(req.Context(), bucket, key, req.Body, req.ContentLength, opts); err != nil {
+		if body, ok := immutableError(err); ok {
+			return ctx.JSON(http.StatusForbidden, body)
+		}
+		if errors.Is(err, s3.ErrInvalidMetadata) {
+			return ctx.JSON(http.StatusBadRequest, map[string]string{
+				"error": err.Error(),
+			})
+		}
+		if errors.Is(err, s3.ErrQuotaExceeded) {
+			return ctx.JSON(http.StatusInsufficientStorage, map[string]string{
+				"error": err.Error(),
+			})
+		}
+		return ctx.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Failed to upload file: " + err.Error(),
+		})
+	}
+
+	response := map[string]string{
+		"message": "File uploaded successfully with key: " + key,
+	}
+	if url := c.service.PublicURL(bucket, key); url != "" {
+		response["url"] = url
+	}
+	return ctx.JSON(http.StatusOK, response)
+}
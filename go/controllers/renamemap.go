@@ -0,0 +1,79 @@
+// Package controllers 处理HTTP请求
+// 作者: KO
+// 创建时间: 2026-10-15
+// 修改时间: 2026-10-15
+package controllers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/example/s3service/s3"
+	"github.com/labstack/echo/v4"
+)
+
+// RenameMap 按CSV中的old-key,new-key映射批量重命名对象
+// CSV可以作为multipart文件字段file上传，也可以通过表单字段csvKey指定存储桶中已上传的CSV；
+// 每一行的结果单独返回，全部成功时返回200，存在格式无效或失败的行时返回207。
+// 新键已存在的行会被跳过，中断后可用同一个CSV重新执行
+// 参数:
+//
+//	ctx: Echo上下文
+//
+// 返回值:
+//
+//	error: 错误信息
+func (c *S3Controller) RenameMap(ctx echo.Context) error {
+	bucket := ctx.FormValue("bucket")
+	csvKey := ctx.FormValue("csvKey")
+
+	var csvData io.ReadCloser
+	if file, err := ctx.FormFile("file"); err == nil {
+		csvData, err = file.Open()
+		if err != nil {
+			return ctx.JSON(http.StatusInternalServerError, map[string]string{
+				"error": "Failed to open file",
+			})
+		}
+	} else if csvKey != "" {
+		csvData, _, err = c.service.DownloadRange(ctx.Request().Context(), bucket, csvKey, "")
+		if err != nil {
+			if s3.IsNotFound(err) {
+				return ctx.JSON(http.StatusNotFound, map[string]string{
+					"error": "CSV not found: " + csvKey,
+				})
+			}
+			return ctx.JSON(http.StatusInternalServerError, map[string]string{
+				"error": "Failed to download CSV: " + err.Error(),
+			})
+		}
+	} else {
+		return ctx.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Either a CSV file or csvKey is required",
+		})
+	}
+	defer csvData.Close()
+
+	results, err := c.service.RenameFromCSV(ctx.Request().Context(), bucket, csvData)
+	if err != nil {
+		if errors.Is(err, s3.ErrRenameMapEmpty) {
+			return ctx.JSON(http.StatusBadRequest, map[string]string{
+				"error": err.Error(),
+			})
+		}
+		return ctx.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Failed to read rename map: " + err.Error(),
+		})
+	}
+
+	status := http.StatusOK
+	for _, result := range results {
+		if result.Error != "" {
+			status = http.StatusMultiStatus
+			break
+		}
+	}
+
+	return ctx.JSON(status, results)
+}
@@ -1,34 +1,44 @@
 // Package controllers 处理HTTP请求
 // 作者: KO
 // 创建时间: 2026-01-27
-// 修改时间: 2026-01-27
+// 修改时间: 2026-10-15
 package controllers
 
 import (
-	"bytes"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/example/s3service/config"
 	"github.com/example/s3service/s3"
 	"github.com/labstack/echo/v4"
 )
 
 // S3Controller 处理S3相关的HTTP请求
 type S3Controller struct {
-	service *s3.Service // S3服务实例
+	service *s3.Service      // S3服务实例
+	cfg     *config.S3Config // 服务配置
 }
 
 // NewS3Controller 创建新的S3控制器实例
 // 参数:
 //
 //	service: S3服务实例
+//	cfg: 服务配置
 //
 // 返回值:
 //
 //	*S3Controller: S3控制器实例
-func NewS3Controller(service *s3.Service) *S3Controller {
+func NewS3Controller(service *s3.Service, cfg *config.S3Config) *S3Controller {
 	return &S3Controller{
 		service: service,
+		cfg:     cfg,
 	}
 }
 
@@ -57,36 +67,102 @@ func (c *S3Controller) UploadFile(ctx echo.Context) error {
 	}
 	defer src.Close()
 
-	// 读取文件内容
-	content := bytes.Buffer{}
-	if _, err := content.ReadFrom(src); err != nil {
-		return ctx.JSON(http.StatusInternalServerError, map[string]string{
-			"error": "Failed to read file",
-		})
-	}
-
-	// 获取对象键
+	// 获取对象键，遗留客户端提交的非UTF-8文件名先转码为UTF-8
 	key := ctx.FormValue("key")
 	if key == "" {
 		key = file.Filename
 	}
+	key, filenameMetadata := c.decodeFilename(key)
+	key = c.service.NormalizeKey(key)
 
 	// 获取存储桶名称
 	bucket := ctx.FormValue("bucket")
 
+	// 指定暂存ID时上传到暂存区，待发布时再复制到最终键
+	objectKey := key
+	stagingID := ctx.FormValue("stagingId")
+	if stagingID != "" {
+		objectKey, err = c.service.StagingKey(stagingID, key)
+		if err != nil {
+			return ctx.JSON(http.StatusBadRequest, map[string]string{
+				"error": "Invalid stagingId: " + stagingID,
+			})
+		}
+	}
+
+	// 用户自定义元数据，以x-amz-meta-*请求头随对象保存
+	metadata, err := formMetadata(ctx, filenameMetadata)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, map[string]string{
+			"error": err.Error(),
+		})
+	}
+
 	// 上传文件
-	if err := c.service.UploadFile(ctx.Request().Context(), bucket, key, content.Bytes()); err != nil {
+	opts := s3.UploadOptions{
+		ContentType:     file.Header.Get(echo.HeaderContentType),
+		Metadata:        metadata,
+		SkipIfUnchanged: ctx.FormValue("skipIfUnchanged") == "true",
+
+		ChecksumAlgorithm: ctx.FormValue("checksumAlgorithm"),
+		Checksum:          ctx.FormValue("checksum"),
+	}
+	// 提供了加密密钥时在存储前加密，S3只保存密文
+	if encodedKey := ctx.Request().Header.Get(HeaderEncryptionKey); encodedKey != "" {
+		opts.EncryptionKey, err = s3.ParseEncryptionKey(encodedKey)
+		if err != nil {
+			return ctx.JSON(http.StatusBadRequest, map[string]string{
+				"error": err.Error(),
+			})
+		}
+	}
+	// 直接以上传的文件流写入S3，不在内存中缓冲整个文件
+	if err := c.service.UploadStream(ctx.Request().Context(), bucket, objectKey, src, file.Size, opts); err != nil {
+		if body, ok := immutableError(err); ok {
+			return ctx.JSON(http.StatusForbidden, body)
+		}
+		// 内容未变化时跳过上传，按条件请求的语义返回304
+		if errors.Is(err, s3.ErrUnchanged) {
+			return ctx.NoContent(http.StatusNotModified)
+		}
+		if errors.Is(err, s3.ErrQuotaExceeded) {
+			return ctx.JSON(http.StatusInsufficientStorage, map[string]string{
+				"error": err.Error(),
+			})
+		}
+		if errors.Is(err, s3.ErrInvalidChecksum) || errors.Is(err, s3.ErrChecksumMismatch) || errors.Is(err, s3.ErrInvalidMetadata) ||
+			errors.Is(err, s3.ErrUnchangedCheckUnsupported) {
+			return ctx.JSON(http.StatusBadRequest, map[string]string{
+				"error": err.Error(),
+			})
+		}
 		return ctx.JSON(http.StatusInternalServerError, map[string]string{
 			"error": "Failed to upload file: " + err.Error(),
 		})
 	}
 
-	return ctx.JSON(http.StatusOK, map[string]string{
+	if stagingID != "" {
+		return ctx.JSON(http.StatusOK, map[string]string{
+			"message": "File staged successfully with key: " + key,
+		})
+	}
+
+	response := map[string]string{
 		"message": "File uploaded successfully with key: " + key,
-	})
+	}
+	if url := c.service.PublicURL(bucket, key); url != "" {
+		response["url"] = url
+	}
+	return ctx.JSON(http.StatusOK, response)
 }
 
 // DownloadFile 从S3存储桶下载文件
+// 启用download_redirect时先通过HeadObject获取对象大小：小对象直接代理返回，
+// 大对象返回302重定向到预签名URL，由客户端直接从S3下载。
+// 支持Range请求（包括多范围的multipart/byteranges），便于下载工具断点续传；
+// 范围全部不可满足时返回416和Content-Range: bytes */size
+// 完整下载直接从S3的响应流复制到客户端，不在内存中缓冲整个对象。
+// precompressed=true时按Accept-Encoding优先返回同名的.br或.gz预压缩变体
 // 参数:
 //
 //	ctx: Echo上下文
@@ -95,21 +171,209 @@ func (c *S3Controller) UploadFile(ctx echo.Context) error {
 //
 //	error: 错误信息
 func (c *S3Controller) DownloadFile(ctx echo.Context) error {
-	key := ctx.Param("key")
+	key := keyParam(ctx)
 	bucket := ctx.QueryParam("bucket")
+	rangeHeader := ctx.Request().Header.Get("Range")
+
+	if err := c.throttleDownload(ctx); err != nil {
+		return ctx.JSON(http.StatusBadRequest, map[string]string{
+			"error": err.Error(),
+		})
+	}
+
+	// 提供了加密密钥时按客户端加密对象解密后返回（可指定版本，不支持Range和重定向）
+	versionID := ctx.QueryParam("versionId")
+	if encodedKey := ctx.Request().Header.Get(HeaderEncryptionKey); encodedKey != "" {
+		return c.downloadDecrypted(ctx, bucket, key, versionID, encodedKey)
+	}
+
+	// 指定版本时直接下载该版本的完整内容（不支持Range和重定向）
+	if versionID != "" {
+		body, size, contentType, err := c.service.DownloadVersionStream(ctx.Request().Context(), bucket, key, versionID)
+		if err != nil {
+			if errors.Is(err, s3.ErrEncryptionKeyRequired) {
+				return ctx.JSON(http.StatusBadRequest, map[string]string{
+					"error": err.Error(),
+				})
+			}
+			return ctx.JSON(http.StatusInternalServerError, map[string]string{
+				"error": "Failed to download file: " + err.Error(),
+			})
+		}
+		defer body.Close()
+
+		ctx.Response().Header().Set("Content-Disposition", c.contentDisposition(key))
+		c.applyDownloadPolicy(ctx, key)
+		ctx.Response().Header().Set("Content-Length", fmt.Sprintf("%d", size))
+		ctx.Response().Header().Set(echo.HeaderContentType, c.blobContentType(contentType))
+		ctx.Response().WriteHeader(http.StatusOK)
+
+		_, _ = io.Copy(ctx.Response(), body)
+		return nil
+	}
+
+	// 预压缩模式下优先返回客户端可接受的.br或.gz变体（Range请求针对原对象的字节，不使用变体）
+	if ctx.QueryParam("precompressed") == "true" && rangeHeader == "" {
+		if served, err := c.servePrecompressed(ctx, bucket, key); served || err != nil {
+			return err
+		}
+	}
+
+	var info *s3.ObjectInfo
+	if c.cfg.DownloadRedirect || rangeHeader != "" {
+		var err error
+		info, err = c.service.StatObject(ctx.Request().Context(), bucket, key)
+		if err != nil {
+			return ctx.JSON(http.StatusInternalServerError, map[string]string{
+				"error": "Failed to stat file: " + err.Error(),
+			})
+		}
+		if info.Metadata[s3.MetaClientEncryption] != "" {
+			return ctx.JSON(http.StatusBadRequest, map[string]string{
+				"error": s3.ErrEncryptionKeyRequired.Error(),
+			})
+		}
+	}
+
+	if c.cfg.DownloadRedirect && info.Size >= c.cfg.DownloadRedirectThreshold {
+		url, err := c.service.PresignDownloadURL(ctx.Request().Context(), bucket, info.Key, c.cfg.DownloadRedirectExpiry)
+		if err != nil {
+			return ctx.JSON(http.StatusInternalServerError, map[string]string{
+				"error": "Failed to presign download URL: " + err.Error(),
+			})
+		}
+		return ctx.Redirect(http.StatusFound, url)
+	}
 
-	content, err := c.service.DownloadFile(ctx.Request().Context(), bucket, key)
+	if rangeHeader != "" {
+		ranges, err := parseRange(rangeHeader, info.Size)
+		if errors.Is(err, errUnsatisfiableRange) {
+			ctx.Response().Header().Set("Content-Range", fmt.Sprintf("bytes */%d", info.Size))
+			return ctx.JSON(http.StatusRequestedRangeNotSatisfiable, map[string]string{
+				"error": "Range not satisfiable: " + rangeHeader,
+			})
+		}
+		// 语法无效或范围过多时按规范忽略Range，返回完整内容
+		if err == nil && len(ranges) <= maxRanges {
+			ctx.Response().Header().Set("Content-Disposition", c.contentDisposition(key))
+			c.applyDownloadPolicy(ctx, key)
+			return c.serveRanges(ctx, bucket, info, ranges)
+		}
+	}
+
+	body, size, contentType, err := c.service.DownloadStream(ctx.Request().Context(), bucket, key)
 	if err != nil {
+		if errors.Is(err, s3.ErrEncryptionKeyRequired) {
+			return ctx.JSON(http.StatusBadRequest, map[string]string{
+				"error": err.Error(),
+			})
+		}
 		return ctx.JSON(http.StatusInternalServerError, map[string]string{
 			"error": "Failed to download file: " + err.Error(),
 		})
 	}
 
+	defer body.Close()
+
 	// 设置响应头
-	ctx.Response().Header().Set("Content-Disposition", "attachment; filename="+key)
-	ctx.Response().Header().Set("Content-Length", fmt.Sprintf("%d", len(content)))
+	ctx.Response().Header().Set("Content-Disposition", c.contentDisposition(key))
+	c.applyDownloadPolicy(ctx, key)
+	ctx.Response().Header().Set("Content-Length", fmt.Sprintf("%d", size))
+	ctx.Response().Header().Set("Accept-Ranges", "bytes")
+	ctx.Response().Header().Set(echo.HeaderContentType, c.blobContentType(contentType))
+	ctx.Response().WriteHeader(http.StatusOK)
 
-	return ctx.Blob(http.StatusOK, "application/octet-stream", content)
+	// 直接从S3的响应流复制到客户端；响应头已发出，客户端中途断开时只能结束复制
+	_, _ = io.Copy(ctx.Response(), body)
+	return nil
+}
+
+// blobContentType 返回下载响应使用的内容类型
+// 使用对象保存的内容类型（按force_download_override处理高风险类型），S3未返回时回退到application/octet-stream
+// 参数:
+//
+//	stored: 对象保存的内容类型
+//
+// 返回值:
+//
+//	string: 响应使用的内容类型
+func (c *S3Controller) blobContentType(stored string) string {
+	if contentType := c.service.DownloadContentType(stored); contentType != "" {
+		return contentType
+	}
+	return "application/octet-stream"
+}
+
+// applyDownloadPolicy 启用force_download时为下载响应设置附件形式的Content-Disposition（文件名取键的最后一段）
+// 和X-Content-Type-Options: nosniff，防止浏览器内联渲染不可信的内容。
+// 需要在设置通用的Content-Disposition之后调用，否则其文件名会被覆盖
+// 参数:
+//
+//	ctx: Echo上下文
+//	key: 文件键
+func (c *S3Controller) applyDownloadPolicy(ctx echo.Context, key string) {
+	if !c.cfg.ForceDownload {
+		return
+	}
+
+	header := ctx.Response().Header()
+	header.Set("Content-Disposition", c.contentDisposition(path.Base(key)))
+	header.Set("X-Content-Type-Options", "nosniff")
+}
+
+// HeadBytes 以二进制形式返回文件的前N个字节
+// 与下载接口不同，只通过范围请求读取文件头部，并返回对象真实的内容类型，
+// 便于直接交给媒体解析工具读取文件头；N由查询参数n指定（默认1024），上限为head_bytes_max
+// 参数:
+//
+//	ctx: Echo上下文
+//
+// 返回值:
+//
+//	error: 错误信息
+func (c *S3Controller) HeadBytes(ctx echo.Context) error {
+	key := keyParam(ctx)
+	bucket := ctx.QueryParam("bucket")
+
+	n := int64(1024)
+	if value := ctx.QueryParam("n"); value != "" {
+		parsed, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || parsed <= 0 {
+			return ctx.JSON(http.StatusBadRequest, map[string]string{
+				"error": "Invalid n: " + value,
+			})
+		}
+		n = parsed
+	}
+	if n > c.cfg.HeadBytesMax {
+		return ctx.JSON(http.StatusBadRequest, map[string]string{
+			"error": fmt.Sprintf("n must not exceed %d", c.cfg.HeadBytesMax),
+		})
+	}
+
+	body, info, err := c.service.DownloadRange(ctx.Request().Context(), bucket, key, fmt.Sprintf("bytes=0-%d", n-1))
+	if err != nil {
+		// 空文件无法满足任何范围请求，直接返回空内容
+		if s3.IsInvalidRange(err) {
+			ctx.Response().Header().Set("Accept-Ranges", "bytes")
+			return ctx.Blob(http.StatusOK, "application/octet-stream", nil)
+		}
+		return ctx.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Failed to read file: " + err.Error(),
+		})
+	}
+	defer body.Close()
+
+	contentType := c.service.DownloadContentType(info.ContentType)
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+
+	c.applyDownloadPolicy(ctx, key)
+	ctx.Response().Header().Set("Accept-Ranges", "bytes")
+	ctx.Response().Header().Set("Content-Length", fmt.Sprintf("%d", info.ContentLength))
+
+	return ctx.Stream(http.StatusOK, contentType, body)
 }
 
 // DeleteFile 从S3存储桶删除文件
@@ -121,10 +385,27 @@ func (c *S3Controller) DownloadFile(ctx echo.Context) error {
 //
 //	error: 错误信息
 func (c *S3Controller) DeleteFile(ctx echo.Context) error {
-	key := ctx.Param("key")
+	key := keyParam(ctx)
 	bucket := ctx.QueryParam("bucket")
 
+	if versionID := ctx.QueryParam("versionId"); versionID != "" {
+		if err := c.service.DeleteFileVersion(ctx.Request().Context(), bucket, key, versionID); err != nil {
+			if body, ok := immutableError(err); ok {
+				return ctx.JSON(http.StatusForbidden, body)
+			}
+			return ctx.JSON(http.StatusInternalServerError, map[string]string{
+				"error": "Failed to delete file version: " + err.Error(),
+			})
+		}
+		return ctx.JSON(http.StatusOK, map[string]string{
+			"message": "File version deleted successfully: " + key + " (" + versionID + ")",
+		})
+	}
+
 	if err := c.service.DeleteFile(ctx.Request().Context(), bucket, key); err != nil {
+		if body, ok := immutableError(err); ok {
+			return ctx.JSON(http.StatusForbidden, body)
+		}
 		return ctx.JSON(http.StatusInternalServerError, map[string]string{
 			"error": "Failed to delete file: " + err.Error(),
 		})
@@ -135,7 +416,138 @@ func (c *S3Controller) DeleteFile(ctx echo.Context) error {
 	})
 }
 
+// copyRequest 复制文件的请求体
+type copyRequest struct {
+	SrcBucket string `json:"srcBucket"` // 源存储桶（为空时使用默认存储桶）
+	SrcKey    string `json:"srcKey"`    // 源文件键
+	DstBucket string `json:"dstBucket"` // 目标存储桶（为空时使用默认存储桶）
+	DstKey    string `json:"dstKey"`    // 目标文件键
+}
+
+// CopyFile 在S3内部复制文件（服务端复制，不经过本服务传输数据）
+// 请求体：{"srcBucket": "...", "srcKey": "...", "dstBucket": "...", "dstKey": "..."}
+// 参数:
+//
+//	ctx: Echo上下文
+//
+// 返回值:
+//
+//	error: 错误信息
+func (c *S3Controller) CopyFile(ctx echo.Context) error {
+	var req copyRequest
+	if err := ctx.Bind(&req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Invalid request body",
+		})
+	}
+	if req.SrcKey == "" || req.DstKey == "" {
+		return ctx.JSON(http.StatusBadRequest, map[string]string{
+			"error": "srcKey and dstKey are required",
+		})
+	}
+	dstKey := c.service.NormalizeKey(req.DstKey)
+
+	if err := c.service.CopyObject(ctx.Request().Context(), req.SrcBucket, req.SrcKey, req.DstBucket, dstKey); err != nil {
+		if errors.Is(err, s3.ErrInvalidCopy) {
+			return ctx.JSON(http.StatusBadRequest, map[string]string{
+				"error": "Source and destination must differ",
+			})
+		}
+		if body, ok := immutableError(err); ok {
+			return ctx.JSON(http.StatusForbidden, body)
+		}
+		if s3.IsNotFound(err) {
+			return ctx.JSON(http.StatusNotFound, map[string]string{
+				"error": "File not found: " + req.SrcKey,
+			})
+		}
+		return ctx.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Failed to copy file: " + err.Error(),
+		})
+	}
+
+	return ctx.JSON(http.StatusOK, map[string]string{
+		"message": "File copied successfully to key: " + dstKey,
+	})
+}
+
+// MoveFile 移动（重命名）文件，可跨存储桶
+// 以服务端复制、校验目标、删除源对象的方式完成，不是原子操作；删除源对象失败时会删除目标副本回滚
+// 请求体：{"srcBucket": "...", "srcKey": "...", "dstBucket": "...", "dstKey": "..."}
+// 参数:
+//
+//	ctx: Echo上下文
+//
+// 返回值:
+//
+//	error: 错误信息
+func (c *S3Controller) MoveFile(ctx echo.Context) error {
+	var req copyRequest
+	if err := ctx.Bind(&req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Invalid request body",
+		})
+	}
+	if req.SrcKey == "" || req.DstKey == "" {
+		return ctx.JSON(http.StatusBadRequest, map[string]string{
+			"error": "srcKey and dstKey are required",
+		})
+	}
+	dstKey := c.service.NormalizeKey(req.DstKey)
+
+	if err := c.service.MoveObject(ctx.Request().Context(), req.SrcBucket, req.SrcKey, req.DstBucket, dstKey); err != nil {
+		if body, ok := immutableError(err); ok {
+			return ctx.JSON(http.StatusForbidden, body)
+		}
+		if errors.Is(err, s3.ErrInvalidMove) {
+			return ctx.JSON(http.StatusBadRequest, map[string]string{
+				"error": err.Error(),
+			})
+		}
+		if s3.IsNotFound(err) {
+			return ctx.JSON(http.StatusNotFound, map[string]string{
+				"error": "File not found: " + req.SrcKey,
+			})
+		}
+		return ctx.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Failed to move file: " + err.Error(),
+		})
+	}
+
+	return ctx.JSON(http.StatusOK, map[string]string{
+		"message": "File moved successfully to key: " + dstKey,
+	})
+}
+
+// StatFile 返回文件的元数据，包括S3保存的完整对象校验和（上传时指定了checksumAlgorithm的对象）
+// 参数:
+//
+//	ctx: Echo上下文
+//
+// 返回值:
+//
+//	error: 错误信息
+func (c *S3Controller) StatFile(ctx echo.Context) error {
+	key := keyParam(ctx)
+	bucket := ctx.QueryParam("bucket")
+
+	info, err := c.service.StatObject(ctx.Request().Context(), bucket, key)
+	if err != nil {
+		if s3.IsNotFound(err) {
+			return ctx.JSON(http.StatusNotFound, map[string]string{
+				"error": "File not found: " + key,
+			})
+		}
+		return ctx.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Failed to stat file: " + err.Error(),
+		})
+	}
+
+	return ctx.JSON(http.StatusOK, info)
+}
+
 // CheckFileExists 检查文件是否存在于S3存储桶
+// 返回true或false；无法确定是否存在（如网络故障、权限不足）时返回500，而不是false
 // 参数:
 //
 //	ctx: Echo上下文
@@ -144,15 +556,27 @@ func (c *S3Controller) DeleteFile(ctx echo.Context) error {
 //
 //	error: 错误信息
 func (c *S3Controller) CheckFileExists(ctx echo.Context) error {
-	key := ctx.Param("key")
+	key := keyParam(ctx)
 	bucket := ctx.QueryParam("bucket")
 
-	exists := c.service.FileExists(ctx.Request().Context(), bucket, key)
+	var exists bool
+	var err error
+	if versionID := ctx.QueryParam("versionId"); versionID != "" {
+		exists, err = c.service.FileVersionExists(ctx.Request().Context(), bucket, key, versionID)
+	} else {
+		exists, err = c.service.FileExists(ctx.Request().Context(), bucket, key)
+	}
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Failed to check file existence: " + err.Error(),
+		})
+	}
 
 	return ctx.JSON(http.StatusOK, exists)
 }
 
 // HealthCheck 健康检查端点
+// 查询参数deep=true时执行深度检查（访问S3，结果按health_cache_ttl缓存），S3不可用时返回503
 // 参数:
 //
 //	ctx: Echo上下文
@@ -161,10 +585,20 @@ func (c *S3Controller) CheckFileExists(ctx echo.Context) error {
 //
 //	error: 错误信息
 func (c *S3Controller) HealthCheck(ctx echo.Context) error {
-	return ctx.String(http.StatusOK, "S3 Service is running")
+	if ctx.QueryParam("deep") != "true" {
+		return ctx.String(http.StatusOK, "S3 Service is running")
+	}
+
+	status := c.service.CheckHealth(ctx.Request().Context())
+	if !status.Healthy {
+		return ctx.JSON(http.StatusServiceUnavailable, status)
+	}
+	return ctx.JSON(http.StatusOK, status)
 }
 
 // ListFiles 列出S3存储桶中的所有文件
+// 查询参数prefix限定键前缀；设置delimiter（如/）时返回{files, commonPrefixes}，
+// 更深层的键归并为公共前缀，可按目录树浏览
 // 参数:
 //
 //	ctx: Echo上下文
@@ -175,56 +609,385 @@ func (c *S3Controller) HealthCheck(ctx echo.Context) error {
 func (c *S3Controller) ListFiles(ctx echo.Context) error {
 	bucket := ctx.QueryParam("bucket")
 
-	files, err := c.service.ListFiles(ctx.Request().Context(), bucket)
+	if ctx.QueryParam("includeVersions") == "true" {
+		files, err := c.service.ListFileVersions(ctx.Request().Context(), bucket, ctx.QueryParam("prefix"))
+		if err != nil {
+			return ctx.JSON(http.StatusInternalServerError, map[string]string{
+				"error": "Failed to list file versions: " + err.Error(),
+			})
+		}
+		return ctx.JSON(http.StatusOK, files)
+	}
+
+	glob := ctx.QueryParam("glob")
+	pattern := ctx.QueryParam("regex")
+	if glob != "" && pattern != "" {
+		return ctx.JSON(http.StatusBadRequest, map[string]string{
+			"error": "glob and regex cannot be combined",
+		})
+	}
+	delimiter := ctx.QueryParam("delimiter")
+	if delimiter != "" && (glob != "" || pattern != "") {
+		return ctx.JSON(http.StatusBadRequest, map[string]string{
+			"error": "delimiter cannot be combined with glob or regex",
+		})
+	}
+
+	// 按标签过滤需要逐个获取对象标签，只支持与prefix组合
+	if tag := ctx.QueryParam("tag"); tag != "" {
+		if glob != "" || pattern != "" || delimiter != "" || ctx.QueryParam("format") != "" ||
+			ctx.QueryParams().Has("cursor") || ctx.QueryParams().Has("token") || ctx.QueryParams().Has("limit") {
+			return ctx.JSON(http.StatusBadRequest, map[string]string{
+				"error": "tag can only be combined with prefix",
+			})
+		}
+		return c.listFilesByTag(ctx, bucket, tag)
+	}
+
+	if ctx.QueryParam("format") == "jsonapi" {
+		if glob != "" || pattern != "" {
+			return ctx.JSON(http.StatusBadRequest, map[string]string{
+				"error": "glob and regex are not supported with format=jsonapi",
+			})
+		}
+		return c.listFilesJSONAPI(ctx, bucket)
+	}
+
+	if ctx.QueryParams().Has("cursor") {
+		if delimiter != "" {
+			return ctx.JSON(http.StatusBadRequest, map[string]string{
+				"error": "delimiter is not supported with cursor pagination, use token pagination",
+			})
+		}
+		if glob != "" || pattern != "" {
+			return ctx.JSON(http.StatusBadRequest, map[string]string{
+				"error": "glob and regex are not supported with cursor pagination",
+			})
+		}
+		return c.listFilesByCursor(ctx, bucket)
+	}
+
+	if ctx.QueryParams().Has("token") || ctx.QueryParams().Has("limit") {
+		if glob != "" || pattern != "" {
+			return ctx.JSON(http.StatusBadRequest, map[string]string{
+				"error": "glob and regex are not supported with token pagination",
+			})
+		}
+		return c.listFilesByToken(ctx, bucket)
+	}
+
+	if glob != "" {
+		return c.listFilesByGlob(ctx, bucket, glob)
+	}
+	if pattern != "" {
+		return c.listFilesByRegex(ctx, bucket, ctx.QueryParam("prefix"), pattern)
+	}
+
+	prefix := ctx.QueryParam("prefix")
+	if prefix == "" && delimiter == "" {
+		files, err := c.service.ListFiles(ctx.Request().Context(), bucket)
+		if err != nil {
+			return ctx.JSON(http.StatusInternalServerError, map[string]string{
+				"error": "Failed to list files: " + err.Error(),
+			})
+		}
+		return ctx.JSON(http.StatusOK, files)
+	}
+
+	listing, err := c.service.ListFilesWithPrefix(ctx.Request().Context(), bucket, prefix, delimiter)
 	if err != nil {
+		if errors.Is(err, s3.ErrDelimiterUnsupported) {
+			return ctx.JSON(http.StatusBadRequest, map[string]string{
+				"error": err.Error(),
+			})
+		}
 		return ctx.JSON(http.StatusInternalServerError, map[string]string{
 			"error": "Failed to list files: " + err.Error(),
 		})
 	}
+	// 只按前缀过滤时保持与不带参数时相同的数组格式
+	if delimiter == "" {
+		return ctx.JSON(http.StatusOK, listing.Files)
+	}
 
-	return ctx.JSON(http.StatusOK, files)
+	return ctx.JSON(http.StatusOK, listing)
 }
 
-// ListBuckets 列出所有S3存储桶
+// listFilesByCursor 以StartAfter游标分页列出文件
+// cursor为上一页返回的nextCursor（首页传空值），即上一页最后一个键；limit为每页数量（默认1000，最大1000）。
+// 与不透明的续传令牌不同，游标只依赖键的字典序，在迭代期间对象被增删时也始终有效：
+// 整个迭代期间一直存在的对象都恰好返回一次，不会被跳过或重复；迭代期间删除的对象若尚未到达则不再出现；
+// 新增的对象只有键排在当前游标之后时才会被返回，排在游标之前的新对象本次迭代看不到。
+// 因此游标分页得到的并不是某一时刻的快照，而是“对已存在对象不遗漏”的保证
 // 参数:
 //
 //	ctx: Echo上下文
+//	bucket: 存储桶名称
 //
 // 返回值:
 //
 //	error: 错误信息
-func (c *S3Controller) ListBuckets(ctx echo.Context) error {
-	buckets, err := c.service.ListBuckets(ctx.Request().Context())
+func (c *S3Controller) listFilesByCursor(ctx echo.Context, bucket string) error {
+	limit, err := parseListLimit(ctx.QueryParam("limit"))
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, map[string]string{
+			"error": err.Error(),
+		})
+	}
+
+	page, err := c.service.ListFilesPage(ctx.Request().Context(), bucket, s3.ListPageOptions{
+		Prefix:     ctx.QueryParam("prefix"),
+		MaxKeys:    int32(limit),
+		StartAfter: ctx.QueryParam("cursor"),
+	})
 	if err != nil {
 		return ctx.JSON(http.StatusInternalServerError, map[string]string{
-			"error": "Failed to list buckets: " + err.Error(),
+			"error": "Failed to list files: " + err.Error(),
 		})
 	}
 
-	return ctx.JSON(http.StatusOK, buckets)
+	return ctx.JSON(http.StatusOK, map[string]interface{}{
+		"files":      page.Files,
+		"nextCursor": page.NextCursor,
+	})
 }
 
-// CreateBucket 创建新的S3存储桶
+// listFilesByToken 以续传令牌分页列出文件
+// token为上一页返回的nextToken（首页不传或传空值），limit为每页数量（默认1000，最大1000）；
+// nextToken为空表示没有更多结果。设置了delimiter时同时返回本页的commonPrefixes
 // 参数:
 //
 //	ctx: Echo上下文
+//	bucket: 存储桶名称
 //
 // 返回值:
 //
 //	error: 错误信息
-func (c *S3Controller) CreateBucket(ctx echo.Context) error {
-	bucketName := ctx.QueryParam("bucketName")
-	if bucketName == "" {
+func (c *S3Controller) listFilesByToken(ctx echo.Context, bucket string) error {
+	limit, err := parseListLimit(ctx.QueryParam("limit"))
+	if err != nil {
 		return ctx.JSON(http.StatusBadRequest, map[string]string{
-			"error": "Bucket name is required",
+			"error": err.Error(),
 		})
 	}
 
-	if err := c.service.CreateBucket(ctx.Request().Context(), bucketName); err != nil {
-		if err.Error() == "bucket already exists" {
-			return ctx.JSON(http.StatusConflict, map[string]string{
-				"error": "Bucket already exists: " + bucketName,
-			})
+	page, err := c.service.ListFilesPage(ctx.Request().Context(), bucket, s3.ListPageOptions{
+		Prefix:            ctx.QueryParam("prefix"),
+		MaxKeys:           int32(limit),
+		ContinuationToken: ctx.QueryParam("token"),
+		Delimiter:         ctx.QueryParam("delimiter"),
+	})
+	if err != nil {
+		if errors.Is(err, s3.ErrDelimiterUnsupported) {
+			return ctx.JSON(http.StatusBadRequest, map[string]string{
+				"error": err.Error(),
+			})
+		}
+		return ctx.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Failed to list files: " + err.Error(),
+		})
+	}
+
+	response := map[string]interface{}{
+		"files":     page.Files,
+		"nextToken": page.NextToken,
+	}
+	if ctx.QueryParam("delimiter") != "" {
+		response["commonPrefixes"] = page.CommonPrefixes
+	}
+	return ctx.JSON(http.StatusOK, response)
+}
+
+// parseListLimit 解析分页列举的每页数量
+// 参数:
+//
+//	raw: 查询参数limit的值（为空时使用默认值1000）
+//
+// 返回值:
+//
+//	int: 每页数量
+//	error: 不在1~1000之间时的错误信息
+func parseListLimit(raw string) (int, error) {
+	if raw == "" {
+		return 1000, nil
+	}
+	value, err := strconv.Atoi(raw)
+	if err != nil || value < 1 || value > 1000 {
+		return 0, errors.New("Invalid limit: must be between 1 and 1000")
+	}
+	return value, nil
+}
+
+// listFilesByRegex 列出键匹配正则表达式的文件
+// 使用Go的RE2正则引擎，匹配时间与输入长度成线性关系，不会出现灾难性回溯；
+// 模式长度受list_regex_max_length限制。过滤在服务端完成，总是扫描prefix下的全部对象
+// 参数:
+//
+//	ctx: Echo上下文
+//	bucket: 存储桶名称
+//	prefix: 键前缀（为空时扫描整个存储桶）
+//	pattern: 正则表达式
+//
+// 返回值:
+//
+//	error: 错误信息
+func (c *S3Controller) listFilesByRegex(ctx echo.Context, bucket, prefix, pattern string) error {
+	if len(pattern) > c.cfg.ListRegexMaxLength {
+		return ctx.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Regex pattern too long: maximum length is " + strconv.Itoa(c.cfg.ListRegexMaxLength),
+		})
+	}
+
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Invalid regex pattern: " + err.Error(),
+		})
+	}
+
+	files, err := c.service.ListFilesFiltered(ctx.Request().Context(), bucket, prefix, re.MatchString)
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Failed to list files: " + err.Error(),
+		})
+	}
+
+	return ctx.JSON(http.StatusOK, files)
+}
+
+// listFilesByGlob 列出键匹配glob模式（path.Match语义）的文件
+// 使用glob中最长的字面量前缀作为S3列举前缀，其余部分在服务端过滤；
+// 模式越早出现通配符（如*.gz），需要扫描的对象就越多
+// 参数:
+//
+//	ctx: Echo上下文
+//	bucket: 存储桶名称
+//	glob: glob模式
+//
+// 返回值:
+//
+//	error: 错误信息
+func (c *S3Controller) listFilesByGlob(ctx echo.Context, bucket, glob string) error {
+	if _, err := path.Match(glob, ""); err != nil {
+		return ctx.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Invalid glob pattern: " + glob,
+		})
+	}
+
+	prefix := glob
+	if i := strings.IndexAny(glob, "*?[\\"); i >= 0 {
+		prefix = glob[:i]
+	}
+
+	files, err := c.service.ListFilesFiltered(ctx.Request().Context(), bucket, prefix, func(key string) bool {
+		matched, _ := path.Match(glob, key)
+		return matched
+	})
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Failed to list files: " + err.Error(),
+		})
+	}
+
+	return ctx.JSON(http.StatusOK, files)
+}
+
+// listFilesByTag 列出前缀下带有指定标签的文件
+// tag形如key:value；只有key（不含冒号）时匹配带有该标签键的任意值。
+// 每个对象都要单独获取一次标签，代价与前缀下的对象数成正比
+// 参数:
+//
+//	ctx: Echo上下文
+//	bucket: 存储桶名称
+//	tag: 标签条件
+//
+// 返回值:
+//
+//	error: 错误信息
+func (c *S3Controller) listFilesByTag(ctx echo.Context, bucket, tag string) error {
+	tagKey, tagValue, hasValue := strings.Cut(tag, ":")
+	if tagKey == "" {
+		return ctx.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Invalid tag: " + tag,
+		})
+	}
+
+	files, err := c.service.ListFilesByTag(ctx.Request().Context(), bucket, ctx.QueryParam("prefix"), tagKey, tagValue, !hasValue)
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Failed to list files: " + err.Error(),
+		})
+	}
+
+	return ctx.JSON(http.StatusOK, files)
+}
+
+// ListBuckets 列出所有S3存储桶
+// 参数:
+//
+//	ctx: Echo上下文
+//
+// 返回值:
+//
+//	error: 错误信息
+func (c *S3Controller) ListBuckets(ctx echo.Context) error {
+	buckets, incomplete, err := c.service.ListBuckets(ctx.Request().Context())
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Failed to list buckets: " + err.Error(),
+		})
+	}
+
+	// 无ListBuckets权限时返回的是由配置得出的回退列表
+	if incomplete {
+		ctx.Response().Header().Set("X-Bucket-List-Incomplete", "true")
+	}
+
+	return ctx.JSON(http.StatusOK, buckets)
+}
+
+// CreateBucket 创建新的S3存储桶
+// 参数:
+//
+//	ctx: Echo上下文
+//
+// 返回值:
+//
+//	error: 错误信息
+func (c *S3Controller) CreateBucket(ctx echo.Context) error {
+	bucketName := ctx.QueryParam("bucketName")
+	if bucketName == "" {
+		return ctx.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Bucket name is required",
+		})
+	}
+	if err := s3.ValidateBucketName(bucketName); err != nil {
+		return ctx.JSON(http.StatusBadRequest, map[string]string{
+			"error": err.Error(),
+		})
+	}
+
+	opts := s3.CreateBucketOptions{
+		ObjectOwnership: ctx.QueryParam("objectOwnership"),
+		ACL:             ctx.QueryParam("acl"),
+		Region:          ctx.QueryParam("region"),
+	}
+
+	if err := c.service.CreateBucket(ctx.Request().Context(), bucketName, opts); err != nil {
+		if err.Error() == "bucket already exists" {
+			return ctx.JSON(http.StatusConflict, map[string]string{
+				"error": "Bucket already exists: " + bucketName,
+			})
+		}
+		if errors.Is(err, s3.ErrInvalidBucketName) || errors.Is(err, s3.ErrInvalidBucketOptions) || errors.Is(err, s3.ErrRegionMismatch) {
+			return ctx.JSON(http.StatusBadRequest, map[string]string{
+				"error": err.Error(),
+			})
+		}
+		if errors.Is(err, s3.ErrOwnershipUnsupported) {
+			return ctx.JSON(http.StatusNotImplemented, map[string]string{
+				"error": err.Error(),
+			})
 		}
 		return ctx.JSON(http.StatusInternalServerError, map[string]string{
 			"error": "Failed to create bucket: " + err.Error(),
@@ -234,4 +997,600 @@ func (c *S3Controller) CreateBucket(ctx echo.Context) error {
 	return ctx.JSON(http.StatusOK, map[string]string{
 		"message": "Bucket created successfully: " + bucketName,
 	})
+}
+
+// DeleteBucket 删除存储桶
+// 查询参数force=true时先删除存储桶中的全部对象（包括历史版本），否则存储桶非空时返回409
+// 参数:
+//
+//	ctx: Echo上下文
+//
+// 返回值:
+//
+//	error: 错误信息
+func (c *S3Controller) DeleteBucket(ctx echo.Context) error {
+	bucketName := ctx.Param("name")
+
+	if err := c.service.DeleteBucket(ctx.Request().Context(), bucketName, ctx.QueryParam("force") == "true"); err != nil {
+		if errors.Is(err, s3.ErrBucketNotEmpty) {
+			return ctx.JSON(http.StatusConflict, map[string]string{
+				"error": err.Error(),
+			})
+		}
+		if s3.IsNotFound(err) {
+			return ctx.JSON(http.StatusNotFound, map[string]string{
+				"error": "Bucket not found: " + bucketName,
+			})
+		}
+		return ctx.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Failed to delete bucket: " + err.Error(),
+		})
+	}
+
+	return ctx.JSON(http.StatusOK, map[string]string{
+		"message": "Bucket deleted successfully: " + bucketName,
+	})
+}
+
+// GetBucketConfig 以单个JSON文档返回存储桶的版本控制、CORS、生命周期、策略和对象所有权配置
+// 参数:
+//
+//	ctx: Echo上下文
+//
+// 返回值:
+//
+//	error: 错误信息
+func (c *S3Controller) GetBucketConfig(ctx echo.Context) error {
+	bucketName := ctx.Param("name")
+
+	snapshot, err := c.service.GetBucketConfig(ctx.Request().Context(), bucketName)
+	if err != nil {
+		if s3.IsNotFound(err) {
+			return ctx.JSON(http.StatusNotFound, map[string]string{
+				"error": "Bucket not found: " + bucketName,
+			})
+		}
+		return ctx.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Failed to get bucket config: " + err.Error(),
+		})
+	}
+
+	return ctx.JSON(http.StatusOK, snapshot)
+}
+
+// PutBucketConfig 将GetBucketConfig返回的配置快照应用到存储桶，用于在其他存储桶上重建相同配置
+// 参数:
+//
+//	ctx: Echo上下文
+//
+// 返回值:
+//
+//	error: 错误信息
+func (c *S3Controller) PutBucketConfig(ctx echo.Context) error {
+	bucketName := ctx.Param("name")
+
+	var snapshot s3.BucketConfig
+	if err := ctx.Bind(&snapshot); err != nil {
+		return ctx.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Invalid bucket config: " + err.Error(),
+		})
+	}
+
+	if err := c.service.PutBucketConfig(ctx.Request().Context(), bucketName, &snapshot); err != nil {
+		if errors.Is(err, s3.ErrInvalidBucketConfig) {
+			return ctx.JSON(http.StatusBadRequest, map[string]string{
+				"error": err.Error(),
+			})
+		}
+		if s3.IsNotFound(err) {
+			return ctx.JSON(http.StatusNotFound, map[string]string{
+				"error": "Bucket not found: " + bucketName,
+			})
+		}
+		return ctx.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Failed to apply bucket config: " + err.Error(),
+		})
+	}
+
+	return ctx.JSON(http.StatusOK, map[string]string{
+		"message": "Bucket config applied successfully: " + bucketName,
+	})
+}
+
+// SearchMetadata 按用户元数据查询默认存储桶中的对象
+// 查询参数形如meta.owner=alice，多个条件之间为“与”关系；
+// 结果来自元数据内存索引，是最终一致且仅限当前实例的
+// 参数:
+//
+//	ctx: Echo上下文
+//
+// 返回值:
+//
+//	error: 错误信息
+func (c *S3Controller) SearchMetadata(ctx echo.Context) error {
+	filters := make(map[string]string)
+	for name, values := range ctx.QueryParams() {
+		if !strings.HasPrefix(name, "meta.") || len(values) == 0 {
+			continue
+		}
+		filters[strings.TrimPrefix(name, "meta.")] = values[0]
+	}
+	if len(filters) == 0 {
+		return ctx.JSON(http.StatusBadRequest, map[string]string{
+			"error": "At least one meta.<name> query parameter is required",
+		})
+	}
+
+	results, err := c.service.SearchMetadata(filters)
+	if err != nil {
+		if errors.Is(err, s3.ErrMetadataIndexDisabled) {
+			return ctx.JSON(http.StatusServiceUnavailable, map[string]string{
+				"error": "Metadata index is disabled",
+			})
+		}
+		return ctx.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Failed to search metadata: " + err.Error(),
+		})
+	}
+
+	return ctx.JSON(http.StatusOK, results)
+}
+
+// AppendNDJSON 将流式上传的NDJSON数据写入按日期分区的S3对象
+// 请求体按行读取并缓冲到本地临时文件，达到大小/时间阈值时切分为新对象；
+// 查询参数maxBytes、maxAge可覆盖配置中的切分阈值
+// 参数:
+//
+//	ctx: Echo上下文
+//
+// 返回值:
+//
+//	error: 错误信息
+func (c *S3Controller) AppendNDJSON(ctx echo.Context) error {
+	bucket := ctx.QueryParam("bucket")
+	prefix := ctx.QueryParam("prefix")
+
+	maxBytes := c.cfg.NDJSONRolloverBytes
+	if value := ctx.QueryParam("maxBytes"); value != "" {
+		parsed, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || parsed <= 0 {
+			return ctx.JSON(http.StatusBadRequest, map[string]string{
+				"error": "Invalid maxBytes: " + value,
+			})
+		}
+		maxBytes = parsed
+	}
+
+	maxAge := c.cfg.NDJSONRolloverInterval
+	if value := ctx.QueryParam("maxAge"); value != "" {
+		parsed, err := time.ParseDuration(value)
+		if err != nil || parsed <= 0 {
+			return ctx.JSON(http.StatusBadRequest, map[string]string{
+				"error": "Invalid maxAge: " + value,
+			})
+		}
+		maxAge = parsed
+	}
+
+	keys, err := c.service.AppendNDJSON(ctx.Request().Context(), bucket, prefix, ctx.Request().Body, maxBytes, maxAge)
+	if err != nil {
+		var lineErr *s3.NDJSONLineError
+		if errors.As(err, &lineErr) {
+			return ctx.JSON(http.StatusBadRequest, map[string]interface{}{
+				"error": lineErr.Error(),
+				"keys":  keys,
+			})
+		}
+		return ctx.JSON(http.StatusInternalServerError, map[string]interface{}{
+			"error": "Failed to write NDJSON: " + err.Error(),
+			"keys":  keys,
+		})
+	}
+
+	return ctx.JSON(http.StatusOK, map[string]interface{}{
+		"keys": keys,
+	})
+}
+
+// RetentionReport 生成存储桶中对象的保留/合法保留状态报告
+// 查询参数：bucket、prefix、limit（每页对象数，默认且最大1000）、token（续传令牌）
+// 参数:
+//
+//	ctx: Echo上下文
+//
+// 返回值:
+//
+//	error: 错误信息
+func (c *S3Controller) RetentionReport(ctx echo.Context) error {
+	bucket := ctx.QueryParam("bucket")
+	prefix := ctx.QueryParam("prefix")
+	token := ctx.QueryParam("token")
+
+	limit := int32(1000)
+	if value := ctx.QueryParam("limit"); value != "" {
+		parsed, err := strconv.ParseInt(value, 10, 32)
+		if err != nil || parsed <= 0 || parsed > 1000 {
+			return ctx.JSON(http.StatusBadRequest, map[string]string{
+				"error": "Invalid limit (1-1000): " + value,
+			})
+		}
+		limit = int32(parsed)
+	}
+
+	report, err := c.service.RetentionReport(ctx.Request().Context(), bucket, prefix, limit, token)
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Failed to build retention report: " + err.Error(),
+		})
+	}
+
+	return ctx.JSON(http.StatusOK, report)
+}
+
+// CreateStaging 创建新的暂存ID
+// 之后在上传请求中携带表单字段stagingId即可将文件上传到该暂存区，
+// 再通过Publish一次性发布
+// 参数:
+//
+//	ctx: Echo上下文
+//
+// 返回值:
+//
+//	error: 错误信息
+func (c *S3Controller) CreateStaging(ctx echo.Context) error {
+	return ctx.JSON(http.StatusOK, map[string]string{
+		"stagingId": c.service.NewStagingID(),
+	})
+}
+
+// publishRequest 发布暂存对象的请求体
+type publishRequest struct {
+	StagingID string `json:"stagingId"` // 暂存ID
+	Bucket    string `json:"bucket"`    // 存储桶名称（为空时使用默认存储桶）
+}
+
+// Publish 将暂存区中的全部对象发布到最终键
+// 全部成功时返回200，部分对象失败时返回207并附带每个对象的结果
+// 参数:
+//
+//	ctx: Echo上下文
+//
+// 返回值:
+//
+//	error: 错误信息
+func (c *S3Controller) Publish(ctx echo.Context) error {
+	var req publishRequest
+	if err := ctx.Bind(&req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Invalid request body",
+		})
+	}
+
+	results, err := c.service.Publish(ctx.Request().Context(), req.Bucket, req.StagingID)
+	if err != nil {
+		if errors.Is(err, s3.ErrInvalidStagingID) {
+			return ctx.JSON(http.StatusBadRequest, map[string]string{
+				"error": "Invalid stagingId: " + req.StagingID,
+			})
+		}
+		if errors.Is(err, s3.ErrStagingEmpty) {
+			return ctx.JSON(http.StatusNotFound, map[string]string{
+				"error": "No objects staged for stagingId: " + req.StagingID,
+			})
+		}
+		return ctx.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Failed to publish staged objects: " + err.Error(),
+		})
+	}
+
+	status := http.StatusOK
+	for _, result := range results {
+		if result.Error != "" {
+			status = http.StatusMultiStatus
+			break
+		}
+	}
+
+	return ctx.JSON(status, results)
+}
+
+// DiffPrefixes 对比两个存储桶/前缀下的对象
+// 查询参数：bucket、prefix（A侧），otherBucket、otherPrefix（B侧）；
+// 返回仅存在于A侧、仅存在于B侧以及两侧ETag或大小不同的对象
+// 参数:
+//
+//	ctx: Echo上下文
+//
+// 返回值:
+//
+//	error: 错误信息
+func (c *S3Controller) DiffPrefixes(ctx echo.Context) error {
+	bucket := ctx.QueryParam("bucket")
+	prefix := ctx.QueryParam("prefix")
+	otherBucket := ctx.QueryParam("otherBucket")
+	otherPrefix := ctx.QueryParam("otherPrefix")
+
+	if bucket == otherBucket && prefix == otherPrefix {
+		return ctx.JSON(http.StatusBadRequest, map[string]string{
+			"error": "The two sides of the diff must differ in bucket or prefix",
+		})
+	}
+
+	diff, err := c.service.DiffPrefixes(ctx.Request().Context(), bucket, prefix, otherBucket, otherPrefix)
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Failed to diff prefixes: " + err.Error(),
+		})
+	}
+
+	return ctx.JSON(http.StatusOK, diff)
+}
+
+// PresignDownload 生成文件的预签名下载URL，客户端可直接从S3下载而无需经过本服务代理
+// 查询参数：bucket；expiry为有效期（如15m，默认同download_redirect_expiry），须在1秒到7天之间
+// 参数:
+//
+//	ctx: Echo上下文
+//
+// 返回值:
+//
+//	error: 错误信息
+func (c *S3Controller) PresignDownload(ctx echo.Context) error {
+	key := keyParam(ctx)
+	bucket := ctx.QueryParam("bucket")
+
+	expiry := c.cfg.DownloadRedirectExpiry
+	if value := ctx.QueryParam("expiry"); value != "" {
+		parsed, err := time.ParseDuration(value)
+		if err != nil || parsed < minPresignExpiry || parsed > maxPresignExpiry {
+			return ctx.JSON(http.StatusBadRequest, map[string]string{
+				"error": "Invalid expiry: must be between " + minPresignExpiry.String() + " and " + maxPresignExpiry.String(),
+			})
+		}
+		expiry = parsed
+	}
+
+	if status, err := c.checkPresignConstraints(ctx); err != nil {
+		return ctx.JSON(status, map[string]string{
+			"error": err.Error(),
+		})
+	}
+
+	expiresAt := time.Now().Add(expiry)
+	url, err := c.service.PresignDownloadURL(ctx.Request().Context(), bucket, key, expiry)
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Failed to presign download URL: " + err.Error(),
+		})
+	}
+
+	return ctx.JSON(http.StatusOK, map[string]interface{}{
+		"url":       url,
+		"expiresAt": expiresAt.UTC().Format(time.RFC3339),
+	})
+}
+
+// presignUploadRequest 预签名上传的请求体
+type presignUploadRequest struct {
+	Key         string `json:"key"`         // 文件键
+	Bucket      string `json:"bucket"`      // 存储桶名称（为空时使用默认存储桶）
+	ContentType string `json:"contentType"` // 内容类型（参与签名，上传时必须携带）
+	Expiry      int64  `json:"expiry"`      // 有效期（秒，默认同download_redirect_expiry）
+}
+
+// PresignUpload 生成文件的预签名上传请求，浏览器可直接上传到S3而无需经过本服务
+// 请求体：{"key": "...", "bucket": "...", "contentType": "...", "expiry": 900}，
+// expiry须在1秒到7天之间；响应包含URL、请求方法以及上传时必须携带的请求头
+// 参数:
+//
+//	ctx: Echo上下文
+//
+// 返回值:
+//
+//	error: 错误信息
+func (c *S3Controller) PresignUpload(ctx echo.Context) error {
+	var req presignUploadRequest
+	if err := ctx.Bind(&req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Invalid request body",
+		})
+	}
+	if req.Key == "" {
+		return ctx.JSON(http.StatusBadRequest, map[string]string{
+			"error": "key is required",
+		})
+	}
+
+	expiry := c.cfg.DownloadRedirectExpiry
+	if req.Expiry != 0 {
+		expiry = time.Duration(req.Expiry) * time.Second
+		if expiry < minPresignExpiry || expiry > maxPresignExpiry {
+			return ctx.JSON(http.StatusBadRequest, map[string]string{
+				"error": fmt.Sprintf("Invalid expiry: must be between %d and %d seconds", int64(minPresignExpiry/time.Second), int64(maxPresignExpiry/time.Second)),
+			})
+		}
+	}
+
+	if status, err := c.checkPresignConstraints(ctx); err != nil {
+		return ctx.JSON(status, map[string]string{
+			"error": err.Error(),
+		})
+	}
+
+	expiresAt := time.Now().Add(expiry)
+	upload, err := c.service.PresignUpload(ctx.Request().Context(), req.Bucket, c.service.NormalizeKey(req.Key), req.ContentType, expiry)
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Failed to presign upload URL: " + err.Error(),
+		})
+	}
+
+	return ctx.JSON(http.StatusOK, map[string]interface{}{
+		"url":       upload.URL,
+		"method":    upload.Method,
+		"headers":   upload.Headers,
+		"expiresAt": expiresAt.UTC().Format(time.RFC3339),
+	})
+}
+
+// checkPresignConstraints 校验请求中的预签名访问限制参数（sourceIp、referer）
+// 所有签发预签名URL的接口都应先调用该方法
+// 参数:
+//
+//	ctx: Echo上下文
+//
+// 返回值:
+//
+//	int: 校验失败时应返回的HTTP状态码
+//	error: 校验失败时的错误信息，校验通过时为nil
+func (c *S3Controller) checkPresignConstraints(ctx echo.Context) (int, error) {
+	constraints := s3.PresignConstraints{
+		SourceIP: ctx.QueryParam("sourceIp"),
+		Referer:  ctx.QueryParam("referer"),
+	}
+
+	if err := c.service.CheckPresignConstraints(constraints); err != nil {
+		if errors.Is(err, s3.ErrInvalidPresignConstraint) {
+			return http.StatusBadRequest, err
+		}
+		return http.StatusNotImplemented, err
+	}
+
+	return 0, nil
+}
+
+// ContentHash 计算存储桶/前缀下内容的确定性摘要，用于检测数据集是否发生变化
+// 查询参数：bucket、prefix
+// 参数:
+//
+//	ctx: Echo上下文
+//
+// 返回值:
+//
+//	error: 错误信息
+func (c *S3Controller) ContentHash(ctx echo.Context) error {
+	hash, err := c.service.ContentHash(ctx.Request().Context(), ctx.QueryParam("bucket"), ctx.QueryParam("prefix"))
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Failed to compute content hash: " + err.Error(),
+		})
+	}
+
+	return ctx.JSON(http.StatusOK, hash)
+}
+
+// Thumbnail 返回图片对象的缩略图
+// 宽度由查询参数width指定（默认200），上限为thumbnail_max_width；
+// 非图片对象返回415，缩略图缓存在对象存储中，重复请求只需读取缓存
+// 参数:
+//
+//	ctx: Echo上下文
+//
+// 返回值:
+//
+//	error: 错误信息
+func (c *S3Controller) Thumbnail(ctx echo.Context) error {
+	key := keyParam(ctx)
+	bucket := ctx.QueryParam("bucket")
+
+	width := 200
+	if value := ctx.QueryParam("width"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil {
+			return ctx.JSON(http.StatusBadRequest, map[string]string{
+				"error": "Invalid width: " + value,
+			})
+		}
+		width = parsed
+	}
+
+	thumb, err := c.service.Thumbnail(ctx.Request().Context(), bucket, key, width)
+	if err != nil {
+		if errors.Is(err, s3.ErrInvalidThumbnailWidth) {
+			return ctx.JSON(http.StatusBadRequest, map[string]string{
+				"error": err.Error(),
+			})
+		}
+		if errors.Is(err, s3.ErrNotImage) {
+			return ctx.JSON(http.StatusUnsupportedMediaType, map[string]string{
+				"error": err.Error(),
+			})
+		}
+		if s3.IsNotFound(err) {
+			return ctx.JSON(http.StatusNotFound, map[string]string{
+				"error": "File not found: " + key,
+			})
+		}
+		return ctx.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Failed to generate thumbnail: " + err.Error(),
+		})
+	}
+
+	return ctx.Blob(http.StatusOK, thumb.ContentType, thumb.Data)
+}
+
+// ApplyMetadataTemplate 对前缀下的对象原地应用配置的元数据模板
+// 查询参数：bucket、prefix；全部成功返回200，部分失败返回207
+// 参数:
+//
+//	ctx: Echo上下文
+//
+// 返回值:
+//
+//	error: 错误信息
+func (c *S3Controller) ApplyMetadataTemplate(ctx echo.Context) error {
+	results, err := c.service.ApplyMetadataTemplate(ctx.Request().Context(), ctx.QueryParam("bucket"), ctx.QueryParam("prefix"))
+	if err != nil {
+		if errors.Is(err, s3.ErrMetadataTemplateEmpty) {
+			return ctx.JSON(http.StatusBadRequest, map[string]string{
+				"error": err.Error(),
+			})
+		}
+		return ctx.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Failed to apply metadata template: " + err.Error(),
+		})
+	}
+
+	status := http.StatusOK
+	for _, result := range results {
+		if result.Error != "" {
+			status = http.StatusMultiStatus
+			break
+		}
+	}
+
+	return ctx.JSON(status, results)
+}
+
+// Touch 在不改变内容的情况下更新对象的最后修改时间
+// 参数:
+//
+//	ctx: Echo上下文
+//
+// 返回值:
+//
+//	error: 错误信息
+func (c *S3Controller) Touch(ctx echo.Context) error {
+	key := keyParam(ctx)
+	bucket := ctx.QueryParam("bucket")
+
+	lastModified, err := c.service.Touch(ctx.Request().Context(), bucket, key)
+	if err != nil {
+		if body, ok := immutableError(err); ok {
+			return ctx.JSON(http.StatusForbidden, body)
+		}
+		if s3.IsNotFound(err) {
+			return ctx.JSON(http.StatusNotFound, map[string]string{
+				"error": "File not found: " + key,
+			})
+		}
+		return ctx.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Failed to touch file: " + err.Error(),
+		})
+	}
+
+	return ctx.JSON(http.StatusOK, map[string]interface{}{
+		"key":          key,
+		"lastModified": lastModified,
+	})
 }
\ No newline at end of file
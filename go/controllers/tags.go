@@ -0,0 +1,89 @@
+// Package controllers 处理HTTP请求
+// 作者: KO
+// 创建时间: 2026-10-15
+// 修改时间: 2026-10-15
+package controllers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/example/s3service/s3"
+	"github.com/labstack/echo/v4"
+)
+
+// setTagsRequest 设置对象标签的请求体
+type setTagsRequest struct {
+	Tags map[string]string `json:"tags"` // 对象标签（替换已有的全部标签，为空时清除）
+}
+
+// GetTags 获取文件的标签
+// 参数:
+//
+//	ctx: Echo上下文
+//
+// 返回值:
+//
+//	error: 错误信息
+func (c *S3Controller) GetTags(ctx echo.Context) error {
+	key := keyParam(ctx)
+	bucket := ctx.QueryParam("bucket")
+
+	tags, err := c.service.GetObjectTags(ctx.Request().Context(), bucket, key)
+	if err != nil {
+		if s3.IsNotFound(err) {
+			return ctx.JSON(http.StatusNotFound, map[string]string{
+				"error": "File not found: " + key,
+			})
+		}
+		return ctx.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Failed to get tags: " + err.Error(),
+		})
+	}
+
+	return ctx.JSON(http.StatusOK, map[string]interface{}{
+		"key":  key,
+		"tags": tags,
+	})
+}
+
+// SetTags 替换文件的全部标签
+// 请求体：{"tags": {"name": "value", ...}}，最多10个标签，键不超过128个字符，值不超过256个字符
+// 参数:
+//
+//	ctx: Echo上下文
+//
+// 返回值:
+//
+//	error: 错误信息
+func (c *S3Controller) SetTags(ctx echo.Context) error {
+	key := keyParam(ctx)
+	bucket := ctx.QueryParam("bucket")
+
+	var req setTagsRequest
+	if err := ctx.Bind(&req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Invalid request body",
+		})
+	}
+
+	if err := c.service.SetObjectTags(ctx.Request().Context(), bucket, key, req.Tags); err != nil {
+		if errors.Is(err, s3.ErrInvalidTags) {
+			return ctx.JSON(http.StatusBadRequest, map[string]string{
+				"error": err.Error(),
+			})
+		}
+		if s3.IsNotFound(err) {
+			return ctx.JSON(http.StatusNotFound, map[string]string{
+				"error": "File not found: " + key,
+			})
+		}
+		return ctx.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Failed to set tags: " + err.Error(),
+		})
+	}
+
+	return ctx.JSON(http.StatusOK, map[string]string{
+		"message": "Tags updated for key: " + key,
+	})
+}
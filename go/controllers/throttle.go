@@ -0,0 +1,107 @@
+// Package controllers 处理HTTP请求
+// 作者: KO
+// 创建时间: 2026-10-15
+// 修改时间: 2026-10-15
+package controllers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+	"golang.org/x/time/rate"
+)
+
+// throttleChunkSize 限速写入时每次等待令牌的最大字节数（同时作为令牌桶容量的上限）
+const throttleChunkSize = 32 * 1024
+
+// throttledWriter 以令牌桶限制写入速率的响应写入器
+type throttledWriter struct {
+	http.ResponseWriter
+	ctx     context.Context // 请求上下文，客户端断开时停止等待
+	limiter *rate.Limiter   // 令牌桶（每个令牌对应1字节）
+}
+
+// Write 按令牌桶的速率分块写入
+// 参数:
+//
+//	p: 待写入的数据
+//
+// 返回值:
+//
+//	int: 已写入的字节数
+//	error: 写入失败或上下文取消时的错误信息
+func (w *throttledWriter) Write(p []byte) (int, error) {
+	written := 0
+	for len(p) > 0 {
+		n := len(p)
+		if burst := w.limiter.Burst(); n > burst {
+			n = burst
+		}
+		if err := w.limiter.WaitN(w.ctx, n); err != nil {
+			return written, err
+		}
+		m, err := w.ResponseWriter.Write(p[:n])
+		written += m
+		if err != nil {
+			return written, err
+		}
+		p = p[n:]
+	}
+	return written, nil
+}
+
+// Flush 刷新底层响应写入器
+func (w *throttledWriter) Flush() {
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Unwrap 返回底层响应写入器（供http.ResponseController使用）
+// 返回值:
+//
+//	http.ResponseWriter: 底层响应写入器
+func (w *throttledWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// throttleDownload 按download_rate_limit和请求的maxRate限制下载响应的速率
+// maxRate（字节/秒）只能降低速率：配置了download_rate_limit时取两者中较小的值；
+// 两者都未设置时不限速。等待令牌时遵循请求上下文，客户端断开后立即停止
+// 参数:
+//
+//	ctx: Echo上下文
+//
+// 返回值:
+//
+//	error: maxRate无效时的错误信息（应以400返回给客户端）
+func (c *S3Controller) throttleDownload(ctx echo.Context) error {
+	limit := c.cfg.DownloadRateLimit
+	if raw := ctx.QueryParam("maxRate"); raw != "" {
+		maxRate, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil || maxRate <= 0 {
+			return errors.New("Invalid maxRate: must be a positive number of bytes per second")
+		}
+		if limit <= 0 || maxRate < limit {
+			limit = maxRate
+		}
+	}
+	if limit <= 0 {
+		return nil
+	}
+
+	burst := throttleChunkSize
+	if limit < int64(burst) {
+		burst = int(limit)
+	}
+	res := ctx.Response()
+	res.Writer = &throttledWriter{
+		ResponseWriter: res.Writer,
+		ctx:            ctx.Request().Context(),
+		limiter:        rate.NewLimiter(rate.Limit(limit), burst),
+	}
+	return nil
+}
@@ -0,0 +1,177 @@
+// Package controllers 处理HTTP请求
+// 作者: KO
+// 创建时间: 2026-10-15
+// 修改时间: 2026-10-15
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"golang.org/x/net/websocket"
+)
+
+// wsEndMessage 客户端发送该文本帧表示数据发送完毕
+const wsEndMessage = "end"
+
+// errClientDisconnected 客户端在上传完成前断开了连接
+var errClientDisconnected = errors.New("client disconnected before upload completed")
+
+// wsFrame 收到的WebSocket帧
+type wsFrame struct {
+	payloadType byte   // 帧类型（文本/二进制）
+	data        []byte // 帧数据
+}
+
+// wsFrameCodec 保留帧类型的编解码器，用于区分数据帧和控制消息
+var wsFrameCodec = websocket.Codec{
+	Unmarshal: func(data []byte, payloadType byte, v interface{}) error {
+		frame := v.(*wsFrame)
+		frame.payloadType = payloadType
+		frame.data = data
+		return nil
+	},
+}
+
+// wsMessage 服务端推送给客户端的消息
+type wsMessage struct {
+	Type    string  `json:"type"`              // 消息类型：progress、complete、error
+	Bytes   int64   `json:"bytes"`             // 已上传的字节数
+	Percent float64 `json:"percent,omitempty"` // 上传百分比（客户端声明了size时提供）
+	Key     string  `json:"key,omitempty"`     // 文件键（complete时提供）
+	ETag    string  `json:"etag,omitempty"`    // 对象ETag（complete时提供）
+	Error   string  `json:"error,omitempty"`   // 错误信息（error时提供）
+}
+
+// UploadWebSocket 通过WebSocket上传文件并实时推送上传进度
+// 协议：查询参数key（必填）、bucket、size（可选，用于计算百分比）；
+// 客户端以二进制帧发送文件数据，发送完毕后发送文本帧"end"；
+// 服务端在每个分段上传完成后推送progress消息，最后推送complete或error消息。
+// 底层使用分段上传，客户端中途断开时会中止分段上传。
+// 参数:
+//
+//	ctx: Echo上下文
+//
+// 返回值:
+//
+//	error: 错误信息
+func (c *S3Controller) UploadWebSocket(ctx echo.Context) error {
+	key := c.service.NormalizeKey(ctx.QueryParam("key"))
+	if key == "" {
+		return ctx.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Key is required",
+		})
+	}
+	bucket := ctx.QueryParam("bucket")
+
+	var size int64
+	if value := ctx.QueryParam("size"); value != "" {
+		parsed, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || parsed < 0 {
+			return ctx.JSON(http.StatusBadRequest, map[string]string{
+				"error": "Invalid size: " + value,
+			})
+		}
+		size = parsed
+	}
+
+	// 浏览器不会对WebSocket握手做CORS预检，CORS中间件也不会拦截，必须在握手时自行校验Origin
+	server := websocket.Server{
+		Handshake: func(config *websocket.Config, req *http.Request) error {
+			origin, err := websocket.Origin(config, req)
+			if err != nil {
+				return err
+			}
+			return c.checkWebSocketOrigin(origin, req)
+		},
+		Handler: func(ws *websocket.Conn) {
+			defer ws.Close()
+			c.handleWebSocketUpload(ws, bucket, key, size)
+		},
+	}
+	server.ServeHTTP(ctx.Response(), ctx.Request())
+
+	return nil
+}
+
+// checkWebSocketOrigin 校验WebSocket握手的Origin，防止跨站WebSocket劫持
+// 任意网站都可以让访问者的浏览器向本服务发起WebSocket连接，因此只接受同源页面和websocket_allowed_origins中的来源；
+// 没有Origin头的请求来自非浏览器客户端（如CLI），不受跨站攻击影响，允许连接。校验失败时握手返回403
+// 参数:
+//
+//	origin: 握手请求的Origin（没有Origin头时为nil）
+//	req: 握手请求
+//
+// 返回值:
+//
+//	error: 来源不被允许时的错误信息
+func (c *S3Controller) checkWebSocketOrigin(origin *url.URL, req *http.Request) error {
+	if origin == nil || strings.EqualFold(origin.Host, req.Host) {
+		return nil
+	}
+
+	value := origin.Scheme + "://" + origin.Host
+	for _, allowed := range c.cfg.WebSocketAllowedOrigins {
+		if allowed == "*" || strings.EqualFold(strings.TrimSuffix(allowed, "/"), value) {
+			return nil
+		}
+	}
+	return fmt.Errorf("origin %s is not allowed", value)
+}
+
+// handleWebSocketUpload 从WebSocket读取数据并通过分段上传写入S3
+// 参数:
+//
+//	ws: WebSocket连接
+//	bucket: 存储桶名称
+//	key: 文件键
+//	size: 客户端声明的文件大小（0表示未知）
+func (c *S3Controller) handleWebSocketUpload(ws *websocket.Conn, bucket, key string, size int64) {
+	pr, pw := io.Pipe()
+
+	// 读取客户端数据帧并写入管道，出错或断开时以错误关闭管道使上传中止
+	go func() {
+		for {
+			var frame wsFrame
+			if err := wsFrameCodec.Receive(ws, &frame); err != nil {
+				pw.CloseWithError(errClientDisconnected)
+				return
+			}
+			if frame.payloadType == websocket.TextFrame {
+				if string(frame.data) == wsEndMessage {
+					pw.Close()
+					return
+				}
+				continue
+			}
+			if _, err := pw.Write(frame.data); err != nil {
+				return
+			}
+		}
+	}()
+
+	var total int64
+	etag, err := c.service.UploadWithProgress(ws.Request().Context(), bucket, key, pr, c.cfg.MultipartPartSize, func(uploaded int64) {
+		total = uploaded
+		message := wsMessage{Type: "progress", Bytes: uploaded}
+		if size > 0 {
+			message.Percent = float64(uploaded) * 100 / float64(size)
+		}
+		_ = websocket.JSON.Send(ws, message)
+	})
+	// 上传结束后关闭读取端，使读取协程不再阻塞
+	pr.Close()
+
+	if err != nil {
+		_ = websocket.JSON.Send(ws, wsMessage{Type: "error", Error: "Failed to upload file: " + err.Error()})
+		return
+	}
+
+	_ = websocket.JSON.Send(ws, wsMessage{Type: "complete", Bytes: total, Key: key, ETag: etag})
+}
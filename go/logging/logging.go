@@ -0,0 +1,88 @@
+// Package logging 提供结构化日志相关功能
+// 作者: KO
+// 创建时间: 2026-10-15
+// 修改时间: 2026-10-15
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+)
+
+// New 按格式和级别创建结构化日志记录器
+// 参数:
+//
+//	w: 日志输出目标
+//	format: 日志格式（text、json）
+//	level: 最低日志级别（debug、info、warn、error）
+//
+// 返回值:
+//
+//	*slog.Logger: 日志记录器
+//	error: 格式或级别无效时的错误信息
+func New(w io.Writer, format, level string) (*slog.Logger, error) {
+	var lvl slog.Level
+	if err := lvl.UnmarshalText([]byte(level)); err != nil {
+		return nil, fmt.Errorf("invalid log_level %q: must be debug, info, warn or error", level)
+	}
+
+	opts := &slog.HandlerOptions{Level: lvl}
+	switch strings.ToLower(format) {
+	case "text":
+		return slog.New(slog.NewTextHandler(w, opts)), nil
+	case "json":
+		return slog.New(slog.NewJSONHandler(w, opts)), nil
+	}
+	return nil, fmt.Errorf("invalid log_format %q: must be text or json", format)
+}
+
+// Middleware 返回以结构化日志记录每个HTTP请求的中间件
+// 基于Echo的RequestLogger中间件，记录方法、路径、状态码、耗时、响应大小和请求ID，
+// 5xx响应和处理出错的请求以Error级别记录
+// 参数:
+//
+//	logger: 日志记录器
+//
+// 返回值:
+//
+//	echo.MiddlewareFunc: Echo中间件
+func Middleware(logger *slog.Logger) echo.MiddlewareFunc {
+	return middleware.RequestLoggerWithConfig(middleware.RequestLoggerConfig{
+		LogMethod:       true,
+		LogURI:          true,
+		LogStatus:       true,
+		LogLatency:      true,
+		LogResponseSize: true,
+		LogRequestID:    true,
+		LogRemoteIP:     true,
+		LogError:        true,
+		HandleError:     true,
+		LogValuesFunc: func(c echo.Context, v middleware.RequestLoggerValues) error {
+			attrs := []slog.Attr{
+				slog.String("method", v.Method),
+				slog.String("uri", v.URI),
+				slog.Int("status", v.Status),
+				slog.Duration("duration", v.Latency),
+				slog.Int64("bytes", v.ResponseSize),
+				slog.String("remote_ip", v.RemoteIP),
+				slog.String("request_id", v.RequestID),
+			}
+
+			level := slog.LevelInfo
+			if v.Error != nil {
+				attrs = append(attrs, slog.String("error", v.Error.Error()))
+			}
+			if v.Error != nil || v.Status >= 500 {
+				level = slog.LevelError
+			}
+			logger.LogAttrs(context.Background(), level, "request", attrs...)
+			return nil
+		},
+	})
+}
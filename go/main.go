@@ -1,14 +1,27 @@
 // Package main 是S3服务的主入口
 // 作者: KO
 // 创建时间: 2026-01-27
-// 修改时间: 2026-01-27
+// 修改时间: 2026-10-15
 package main
 
 import (
+	"context"
+	"errors"
+	"flag"
 	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
 
+	"github.com/example/s3service/apidoc"
+	"github.com/example/s3service/compress"
 	"github.com/example/s3service/config"
 	"github.com/example/s3service/controllers"
+	"github.com/example/s3service/logging"
+	"github.com/example/s3service/metrics"
+	"github.com/example/s3service/readiness"
 	"github.com/example/s3service/s3"
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
@@ -16,59 +29,392 @@ import (
 
 // main 函数是S3服务的主入口
 func main() {
-	// 加载配置
-	cfg, err := config.LoadConfig()
+	// 加载配置：--config参数优先，其次是环境变量CONFIG_PATH，都未指定时在默认位置查找config.yaml
+	configPath := flag.String("config", os.Getenv("CONFIG_PATH"), "path to the config file (default: config.yaml in ., /etc/s3service/ or $HOME/.s3service/)")
+	flag.Parse()
+	cfg, err := config.LoadConfig(*configPath)
 	if err != nil {
-		fmt.Printf("Failed to load config: %v\n", err)
+		slog.Error("Failed to load config", "error", err)
 		return
 	}
 
+	// 创建结构化日志记录器，并作为默认记录器（log包的输出也经由它写出）
+	logger, err := logging.New(os.Stderr, cfg.LogFormat, cfg.LogLevel)
+	if err != nil {
+		slog.Error("Failed to create logger", "error", err)
+		return
+	}
+	slog.SetDefault(logger)
+
 	// 初始化S3服务
 	service, err := s3.NewService(cfg)
 	if err != nil {
-		fmt.Printf("Failed to initialize S3 service: %v\n", err)
+		logger.Error("Failed to initialize S3 service", "error", err)
 		return
 	}
+	service.SetLogger(logger)
+
+	// 收到SIGINT/SIGTERM时取消，停止后台任务并开始优雅关闭
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// 启动后台任务
+	service.StartBackgroundJobs(ctx)
+
+	// 确认S3可用之前，业务接口返回503
+	gate := readiness.NewGate(cfg.ReadinessInterval, "/api/s3/health", "/api/s3/ready", "/api/s3/openapi.json")
+	go gate.Run(ctx, service.CheckReady)
 
 	// 创建Echo实例
 	e := echo.New()
 
-	// 配置CORS
+	// 配置CORS（非预检的OPTIONS请求交给路由说明处理）
 	e.Use(middleware.CORSWithConfig(middleware.CORSConfig{
+		Skipper: func(c echo.Context) bool {
+			return apidoc.IsDiscoveryRequest(c.Request())
+		},
 		AllowOrigins: []string{"*"},
 		AllowMethods: []string{echo.GET, echo.POST, echo.PUT, echo.DELETE, echo.OPTIONS},
-		AllowHeaders: []string{echo.HeaderOrigin, echo.HeaderContentType, echo.HeaderAccept},
+		AllowHeaders: []string{echo.HeaderOrigin, echo.HeaderContentType, echo.HeaderAccept, controllers.HeaderEncryptionKey},
 	}))
 
+	// 配置响应压缩（跳过已压缩的内容）
+	if cfg.GzipEnabled {
+		e.Use(compress.Middleware(compress.NewExclusions(cfg.GzipExcludeContentTypes, cfg.GzipExcludeExtensions)))
+	}
+
+	// 为每个请求分配请求ID（客户端已提供X-Request-ID时沿用），用于关联请求日志和S3操作日志，
+	// 启用request_id_metadata时还记录到上传的对象上
+	e.Use(middleware.RequestIDWithConfig(middleware.RequestIDConfig{
+		RequestIDHandler: func(c echo.Context, id string) {
+			c.SetRequest(c.Request().WithContext(s3.WithRequestID(c.Request().Context(), id)))
+		},
+	}))
+
+	// 记录请求日志
+	e.Use(logging.Middleware(logger))
+
+	// 记录请求指标
+	e.Use(metrics.Middleware(metrics.NewBucketLabeler(cfg.Bucket, cfg.MetricsBucketAllowlist)))
+
+	// 暴露Prometheus指标
+	e.GET("/metrics", metrics.Handler())
+
 	// 创建S3控制器
-	controller := controllers.NewS3Controller(service)
+	controller := controllers.NewS3Controller(service, cfg)
 
-	// 配置API路由
-	api := e.Group("/api/s3")
+	// 配置API路由，并登记各路由的说明供OPTIONS请求查询
+	docs := apidoc.NewRegistry()
+	e.Use(docs.Middleware())
+
+	bucketParam := apidoc.QueryParam("bucket", "Bucket name (defaults to the configured bucket)")
+	prefixParam := apidoc.QueryParam("prefix", "Key prefix")
+
+	api := e.Group("/api/s3", gate.Middleware())
 	{
 		// 健康检查
-		api.GET("/health", controller.HealthCheck)
+		docs.Handle(api, http.MethodGet, "/health", controller.HealthCheck, "Health check",
+			apidoc.QueryParam("deep", "true to check S3 connectivity (cached for health_cache_ttl)"))
+
+		// 就绪探测
+		docs.Handle(api, http.MethodGet, "/ready", gate.Handler, "Readiness probe: 200 once S3 connectivity is confirmed, 503 with Retry-After before")
+
+		// 由登记的路由生成的OpenAPI文档
+		docs.Handle(api, http.MethodGet, "/openapi.json", docs.OpenAPIHandler("S3 Service API", "1.0.0"), "OpenAPI 3 description of all endpoints")
 
 		// 文件上传
-		api.POST("/upload", controller.UploadFile)
+		docs.Handle(api, http.MethodPost, "/upload", controller.UploadFile, "Upload a file (multipart/form-data)",
+			apidoc.FormParam("file", "File content").Require(),
+			apidoc.FormParam("key", "Object key (defaults to the file name)"),
+			apidoc.FormParam("bucket", "Bucket name (defaults to the configured bucket)"),
+			apidoc.FormParam("stagingId", "Upload into this staging area instead of the final key"),
+			apidoc.FormParam("skipIfUnchanged", "true to skip the upload (304) when the stored content is unchanged; cannot be combined with client-side encryption"),
+			apidoc.FormParam("checksumAlgorithm", "CRC32, CRC32C, SHA1 or SHA256; S3 computes and stores the checksum"),
+			apidoc.FormParam("checksum", "Precomputed base64 checksum; S3 rejects the upload if it does not match"),
+			apidoc.FormParam("x-meta-<name>", "User metadata stored as x-amz-meta-<name>"),
+			apidoc.FormParam("metadata", `User metadata as a JSON object, e.g. {"owner": "..."}`),
+			apidoc.HeaderParam(controllers.HeaderEncryptionKey, "Base64 AES key for client-side encryption"))
+
+		// 以原始请求体上传文件（支持chunked传输）
+		docs.Handle(api, http.MethodPut, "/upload/*", controller.UploadRaw, "Upload a file from the raw request body; Transfer-Encoding: chunked is supported",
+			apidoc.PathParam("*", "Object key"),
+			bucketParam,
+			apidoc.HeaderParam("Content-Type", "Content type of the file"),
+			apidoc.BodyParam("File content"))
+		// 与S3原生PUT一致的对象路径，便于curl --data-binary等客户端使用
+		docs.Handle(api, http.MethodPut, "/object/*", controller.UploadRaw, "Upload a file from the raw request body (S3-style PUT)",
+			apidoc.PathParam("*", "Object key"),
+			bucketParam,
+			apidoc.HeaderParam("Content-Type", "Content type of the file"),
+			apidoc.BodyParam("File content"))
+
+		// 大文件分段上传
+		docs.Handle(api, http.MethodPost, "/upload-multipart", controller.UploadMultipart,
+			"Upload a file, using multipart upload above multipart_threshold (multipart/form-data)",
+			apidoc.FormParam("file", "File content").Require(),
+			apidoc.FormParam("key", "Object key (defaults to the file name)"),
+			apidoc.FormParam("bucket", "Bucket name (defaults to the configured bucket)"),
+			apidoc.HeaderParam("Accept", "text/event-stream or application/x-ndjson to stream multipart progress"))
 
 		// 文件下载
-		api.GET("/download/:key", controller.DownloadFile)
+		docs.Handle(api, http.MethodGet, "/download/*", controller.DownloadFile, "Download a file",
+			apidoc.PathParam("*", "Object key"),
+			bucketParam,
+			apidoc.QueryParam("versionId", "Download this object version"),
+			apidoc.QueryParam("maxRate", "Download rate limit in bytes per second (capped by download_rate_limit)"),
+			apidoc.QueryParam("precompressed", "true to serve a sibling .br or .gz variant matching Accept-Encoding"),
+			apidoc.HeaderParam("Range", "Byte ranges to download"),
+			apidoc.HeaderParam(controllers.HeaderEncryptionKey, "Base64 AES key to decrypt a client-side-encrypted object"))
+
+		// 读取文件头部字节
+		docs.Handle(api, http.MethodGet, "/head-bytes/*", controller.HeadBytes, "Read the first N bytes of a file",
+			apidoc.PathParam("*", "Object key"),
+			bucketParam,
+			apidoc.QueryParam("n", "Number of bytes to read (default 1024)"))
 
 		// 文件删除
-		api.DELETE("/delete/:key", controller.DeleteFile)
+		docs.Handle(api, http.MethodDelete, "/delete/*", controller.DeleteFile, "Delete a file",
+			apidoc.PathParam("*", "Object key"),
+			bucketParam,
+			apidoc.QueryParam("versionId", "Delete this object version"))
+
+		// 文件复制
+		docs.Handle(api, http.MethodPost, "/copy", controller.CopyFile, "Copy a file server-side",
+			apidoc.BodyParam(`{"srcBucket": "...", "srcKey": "...", "dstBucket": "...", "dstKey": "..."}`).Require())
+
+		// 文件移动（复制后删除源对象，非原子操作）
+		docs.Handle(api, http.MethodPost, "/move", controller.MoveFile, "Move or rename a file (copy, verify, then delete the source; not atomic)",
+			apidoc.BodyParam(`{"srcBucket": "...", "srcKey": "...", "dstBucket": "...", "dstKey": "..."}`).Require())
+
+		// 创建文件夹标记
+		docs.Handle(api, http.MethodPost, "/folder", controller.CreateFolder, "Create a zero-byte folder marker so an empty folder persists",
+			apidoc.BodyParam(`{"prefix": "...", "bucket": "..."}`).Require())
+
+		// 删除前缀下的全部对象
+		docs.Handle(api, http.MethodDelete, "/prefix", controller.DeletePrefix, "Delete all files under a prefix",
+			apidoc.QueryParam("prefix", "Key prefix").Require(),
+			bucketParam,
+			apidoc.QueryParam("removeMarker", "true to also delete the folder marker of the prefix itself"))
+
+		// 批量删除/复制/移动（Accept: text/event-stream时以SSE返回进度）
+		batchParams := []apidoc.Param{
+			apidoc.BodyParam(`{"keys": [...], "bucket": "..."}`).Require(),
+			apidoc.HeaderParam("Accept", "text/event-stream for progress and summary events"),
+		}
+		copyParams := []apidoc.Param{
+			apidoc.BodyParam(`{"keys": [...], "bucket": "...", "destBucket": "...", "destPrefix": "..."}`).Require(),
+			apidoc.HeaderParam("Accept", "text/event-stream for progress and summary events"),
+		}
+		docs.Handle(api, http.MethodPost, "/batch/delete", controller.BatchDelete, "Delete many files", batchParams...)
+		docs.Handle(api, http.MethodPost, "/batch/copy", controller.BatchCopy, "Copy many files under destBucket/destPrefix", copyParams...)
+		docs.Handle(api, http.MethodPost, "/batch/move", controller.BatchMove, "Move many files under destBucket/destPrefix", copyParams...)
+
+		// 批量获取文件存在性和元数据（NDJSON流式返回）
+		docs.Handle(api, http.MethodPost, "/stat-batch", controller.StatBatch, "Stream existence and metadata of many files as NDJSON",
+			apidoc.BodyParam(`{"keys": [...], "bucket": "..."}`).Require())
+
+		// 预签名下载URL
+		docs.Handle(api, http.MethodGet, "/presign/download/*", controller.PresignDownload, "Get a presigned download URL for direct access to S3",
+			apidoc.PathParam("*", "Object key"),
+			bucketParam,
+			apidoc.QueryParam("expiry", "URL lifetime between 1s and 168h (default download_redirect_expiry)"),
+			apidoc.QueryParam("sourceIp", "Restrict the URL to this source IP"),
+			apidoc.QueryParam("referer", "Restrict the URL to this referer"))
+
+		// 预签名上传URL
+		docs.Handle(api, http.MethodPost, "/presign/upload", controller.PresignUpload, "Get a presigned PUT URL and the headers to send for direct upload to S3",
+			apidoc.BodyParam(`{"key": "...", "bucket": "...", "contentType": "...", "expiry": seconds}`).Require(),
+			apidoc.QueryParam("sourceIp", "Restrict the URL to this source IP"),
+			apidoc.QueryParam("referer", "Restrict the URL to this referer"))
+
+		// 浏览器直传的分段上传：创建、获取各分段的预签名URL、提交ETag完成
+		docs.Handle(api, http.MethodPost, "/presign/multipart/init", controller.InitDirectUpload, "Start a multipart upload whose parts are sent directly to S3",
+			apidoc.BodyParam(`{"key": "...", "bucket": "...", "contentType": "...", "totalSize": 0}`).Require())
+		docs.Handle(api, http.MethodGet, "/presign/multipart/:id/part/:num", controller.PresignDirectUploadPart, "Get a presigned PUT URL for one part",
+			apidoc.PathParam("id", "Upload ID"),
+			apidoc.PathParam("num", "Part number (1-10000)"),
+			apidoc.QueryParam("expiry", "URL lifetime between 1s and 168h (default download_redirect_expiry)"),
+			apidoc.QueryParam("sourceIp", "Restrict the URL to this source IP"),
+			apidoc.QueryParam("referer", "Restrict the URL to this referer"))
+		docs.Handle(api, http.MethodPost, "/presign/multipart/:id/complete", controller.CompleteDirectUpload, "Complete the upload from the part ETags",
+			apidoc.PathParam("id", "Upload ID"),
+			apidoc.BodyParam(`{"parts": [{"partNumber": 1, "etag": "..."}]}`).Require())
+		docs.Handle(api, http.MethodDelete, "/presign/multipart/:id", controller.AbortDirectUpload, "Abort the upload",
+			apidoc.PathParam("id", "Upload ID"))
+
+		// 获取文件元数据（含校验和）
+		docs.Handle(api, http.MethodGet, "/stat/*", controller.StatFile, "Get file metadata including stored checksums",
+			apidoc.PathParam("*", "Object key"),
+			bucketParam)
+
+		// 获取文件的用户自定义元数据
+		docs.Handle(api, http.MethodGet, "/metadata/*", controller.GetMetadata, "Get the user metadata (x-amz-meta-*) of a file",
+			apidoc.PathParam("*", "Object key"),
+			bucketParam)
+
+		// 汇总文件的全部信息（HEAD、元数据、标签、ACL、对象锁定、版本）
+		docs.Handle(api, http.MethodGet, "/info/*", controller.GetObjectDetails,
+			"Get HEAD info, user metadata, tags, ACL, retention, legal hold and versions of a file in one call; failed sections are reported in errors",
+			apidoc.PathParam("*", "Object key"),
+			bucketParam)
+
+		// 获取和替换文件标签
+		docs.Handle(api, http.MethodGet, "/tags/*", controller.GetTags, "Get the tags of a file",
+			apidoc.PathParam("*", "Object key"),
+			bucketParam)
+		docs.Handle(api, http.MethodPut, "/tags/*", controller.SetTags, "Replace the tags of a file (at most 10; keys up to 128 and values up to 256 characters)",
+			apidoc.PathParam("*", "Object key"),
+			bucketParam,
+			apidoc.BodyParam(`{"tags": {"name": "value"}}`).Require())
 
 		// 检查文件是否存在
-		api.GET("/exists/:key", controller.CheckFileExists)
+		docs.Handle(api, http.MethodGet, "/exists/*", controller.CheckFileExists, "Check whether a file exists",
+			apidoc.PathParam("*", "Object key"),
+			bucketParam,
+			apidoc.QueryParam("versionId", "Check this object version"))
 
 		// 列出文件
-		api.GET("/list", controller.ListFiles)
+		docs.Handle(api, http.MethodGet, "/list", controller.ListFiles, "List files",
+			bucketParam,
+			prefixParam,
+			apidoc.QueryParam("glob", "Only list keys matching this glob pattern"),
+			apidoc.QueryParam("regex", "Only list keys matching this regular expression"),
+			apidoc.QueryParam("tag", "Only list files tagged key:value (or key for any value); fetches tags per object, combine with prefix"),
+			apidoc.QueryParam("delimiter", "Group keys below prefix by this delimiter into commonPrefixes, e.g. /"),
+			apidoc.QueryParam("includeVersions", "true to list all object versions"),
+			apidoc.QueryParam("format", "jsonapi for a JSON:API paginated document"),
+			apidoc.QueryParam("cursor", "StartAfter cursor pagination; pass the previous nextCursor (empty for the first page)"),
+			apidoc.QueryParam("token", "Continuation token pagination; pass the previous nextToken (empty for the first page)"),
+			apidoc.QueryParam("limit", "Page size for cursor or token pagination (1-1000)"))
 
 		// 列出存储桶
-		api.GET("/buckets", controller.ListBuckets)
+		docs.Handle(api, http.MethodGet, "/buckets", controller.ListBuckets, "List buckets")
 
 		// 创建存储桶
-		api.POST("/bucket", controller.CreateBucket)
+		docs.Handle(api, http.MethodPost, "/bucket", controller.CreateBucket, "Create a bucket",
+			apidoc.QueryParam("bucketName", "Bucket name").Require(),
+			apidoc.QueryParam("objectOwnership", "BucketOwnerEnforced, BucketOwnerPreferred or ObjectWriter"),
+			apidoc.QueryParam("acl", "Canned ACL"),
+			apidoc.QueryParam("region", "Bucket region"))
+
+		// 删除存储桶
+		docs.Handle(api, http.MethodDelete, "/bucket/:name", controller.DeleteBucket, "Delete a bucket",
+			apidoc.PathParam("name", "Bucket name"),
+			apidoc.QueryParam("force", "true to first delete all objects and versions in the bucket (irreversible)"))
+
+		// 导出和应用存储桶配置快照
+		docs.Handle(api, http.MethodGet, "/bucket/:name/config", controller.GetBucketConfig,
+			"Get versioning, CORS, lifecycle, policy and object ownership as one document",
+			apidoc.PathParam("name", "Bucket name"))
+		docs.Handle(api, http.MethodPut, "/bucket/:name/config", controller.PutBucketConfig,
+			"Apply a bucket config snapshot",
+			apidoc.PathParam("name", "Bucket name"),
+			apidoc.BodyParam("Snapshot returned by GET"))
+
+		// 按元数据搜索文件
+		docs.Handle(api, http.MethodGet, "/search", controller.SearchMetadata, "Search the default bucket by user metadata",
+			apidoc.QueryParam("meta.<name>", "Required metadata value; multiple conditions are ANDed").Require())
+
+		// 追加写入NDJSON数据
+		docs.Handle(api, http.MethodPost, "/append-ndjson", controller.AppendNDJSON, "Append NDJSON records with rollover",
+			bucketParam,
+			prefixParam,
+			apidoc.QueryParam("maxBytes", "Roll over to a new object after this many bytes"),
+			apidoc.QueryParam("maxAge", "Roll over to a new object after this duration"),
+			apidoc.BodyParam("NDJSON records"))
+
+		// 通过WebSocket上传文件并推送进度
+		docs.Handle(api, http.MethodGet, "/ws/upload", controller.UploadWebSocket, "Upload over WebSocket with progress messages",
+			apidoc.QueryParam("key", "Object key").Require(),
+			bucketParam,
+			apidoc.QueryParam("size", "Expected total size in bytes"))
+
+		// 对象保留状态报告
+		docs.Handle(api, http.MethodGet, "/retention-report", controller.RetentionReport, "Report object lock retention status",
+			bucketParam,
+			prefixParam,
+			apidoc.QueryParam("limit", "Page size"),
+			apidoc.QueryParam("token", "Continuation token"))
+
+		// 创建暂存区并发布暂存对象
+		docs.Handle(api, http.MethodPost, "/staging", controller.CreateStaging, "Create a staging area")
+		docs.Handle(api, http.MethodPost, "/publish", controller.Publish, "Publish all staged objects",
+			apidoc.BodyParam(`{"stagingId": "...", "bucket": "..."}`))
+
+		// 对比两个前缀下的对象
+		docs.Handle(api, http.MethodGet, "/diff", controller.DiffPrefixes, "Compare objects under two prefixes",
+			bucketParam,
+			prefixParam,
+			apidoc.QueryParam("otherBucket", "Bucket of the other side"),
+			apidoc.QueryParam("otherPrefix", "Prefix of the other side"))
+
+		// 计算前缀下内容的摘要
+		docs.Handle(api, http.MethodGet, "/content-hash", controller.ContentHash, "Deterministic digest of the content under a prefix",
+			bucketParam,
+			prefixParam)
+
+		// 获取图片对象的缩略图
+		docs.Handle(api, http.MethodGet, "/thumbnail/*", controller.Thumbnail, "Get a cached thumbnail of an image",
+			apidoc.PathParam("*", "Object key"),
+			bucketParam,
+			apidoc.QueryParam("width", "Thumbnail width in pixels"))
+
+		// 按偏移写入的分段上传会话
+		docs.Handle(api, http.MethodPost, "/sessions", controller.CreatePartSession, "Create an offset-addressed upload session",
+			apidoc.BodyParam(`{"bucket": "...", "key": "...", "totalSize": 0, "partSize": 0}`))
+		docs.Handle(api, http.MethodPut, "/sessions/:id/parts", controller.UploadSessionPart, "Upload one part of a session",
+			apidoc.PathParam("id", "Session ID"),
+			apidoc.QueryParam("number", "Part number"),
+			apidoc.QueryParam("offset", "Byte offset of the part"),
+			apidoc.BodyParam("Part content"))
+		docs.Handle(api, http.MethodPost, "/sessions/:id/complete", controller.CompletePartSession, "Complete a session",
+			apidoc.PathParam("id", "Session ID"))
+		docs.Handle(api, http.MethodDelete, "/sessions/:id", controller.AbortPartSession, "Abort a session",
+			apidoc.PathParam("id", "Session ID"))
+
+		// 前缀下对象的下载URL清单
+		docs.Handle(api, http.MethodGet, "/manifest-urls", controller.ManifestURLs, "Download URL manifest for a prefix",
+			bucketParam,
+			prefixParam,
+			apidoc.QueryParam("presign", "true for presigned URLs instead of proxy URLs"),
+			apidoc.QueryParam("expiry", "Presigned URL lifetime, e.g. 1h"),
+			apidoc.QueryParam("store", "true to store the manifest as a report object and return its URL (202)"))
+
+		// 前缀下对象的tar归档下载
+		docs.Handle(api, http.MethodGet, "/download-tar", controller.DownloadTar, "Download objects under a prefix as a tar archive",
+			bucketParam,
+			apidoc.QueryParam("prefix", "Key prefix").Require(),
+			apidoc.QueryParam("gzip", "true for a gzip-compressed archive (.tar.gz)"))
+
+		// 支持拖动进度的媒体流
+		docs.Handle(api, http.MethodGet, "/stream/*", controller.StreamMedia, "Stream media with range support",
+			apidoc.PathParam("*", "Object key"),
+			bucketParam,
+			apidoc.QueryParam("maxRate", "Download rate limit in bytes per second (capped by download_rate_limit)"),
+			apidoc.HeaderParam("Range", "Byte range"))
+
+		// 对前缀下的对象应用元数据模板
+		docs.Handle(api, http.MethodPost, "/metadata-template", controller.ApplyMetadataTemplate, "Apply the configured metadata template to a prefix",
+			bucketParam,
+			prefixParam)
+
+		// 更新对象的最后修改时间
+		docs.Handle(api, http.MethodPost, "/touch/*", controller.Touch, "Bump an object's last-modified time",
+			apidoc.PathParam("*", "Object key"),
+			bucketParam)
+
+		// 按CSV映射批量重命名对象
+		docs.Handle(api, http.MethodPost, "/rename-map", controller.RenameMap, "Rename objects from an old-key,new-key CSV",
+			apidoc.FormParam("file", "CSV file"),
+			apidoc.FormParam("csvKey", "Key of an already uploaded CSV (instead of file)"),
+			apidoc.FormParam("bucket", "Bucket name (defaults to the configured bucket)"))
+
+		// 记录的S3调用，仅用于集成测试，未启用debug_record_calls时不注册
+		if cfg.DebugRecordCalls {
+			docs.Handle(api, http.MethodGet, "/debug/calls", controller.RecordedCalls, "TEST ONLY, not for production: list recorded S3 calls with their inputs",
+				apidoc.QueryParam("operation", "Only list calls of this operation, e.g. PutObject"))
+			docs.Handle(api, http.MethodDelete, "/debug/calls", controller.ResetRecordedCalls, "TEST ONLY, not for production: clear recorded S3 calls")
+		}
 	}
 
 	// 配置静态文件服务
@@ -80,9 +426,23 @@ func main() {
 	})
 
 	// 启动服务器
-	port := "8080"
-	fmt.Printf("S3 Service is running on http://localhost:%s\n", port)
-	if err := e.Start(fmt.Sprintf(":%s", port)); err != nil {
-		fmt.Printf("Failed to start server: %v\n", err)
+	go func() {
+		logger.Info("S3 Service is running", "addr", "http://localhost:"+cfg.Port)
+		if err := e.Start(fmt.Sprintf(":%s", cfg.Port)); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logger.Error("Failed to start server", "error", err)
+			stop()
+		}
+	}()
+
+	// 停止接受新连接，等待进行中的请求（如上传）完成，超过shutdown_timeout后强制关闭
+	<-ctx.Done()
+	stop()
+	logger.Info("Shutting down S3 Service")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
+	defer cancel()
+	if err := e.Shutdown(shutdownCtx); err != nil {
+		logger.Error("Failed to shut down gracefully", "error", err)
 	}
+	// 后台报告已随ctx取消，等待其中止未完成的分段上传
+	service.WaitReports(shutdownCtx)
 }
\ No newline at end of file
@@ -0,0 +1,177 @@
+// Package metrics 提供Prometheus监控指标
+// 作者: KO
+// 创建时间: 2026-10-15
+// 修改时间: 2026-10-15
+package metrics
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// otherBucketLabel 不在白名单中的存储桶统一使用的标签值
+const otherBucketLabel = "other"
+
+var (
+	// RequestsTotal HTTP请求总数
+	RequestsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "s3service_http_requests_total",
+		Help: "Total number of HTTP requests handled, by method, route, status and bucket.",
+	}, []string{"method", "route", "status", "bucket"})
+
+	// UpstreamOperationsTotal 调用S3的总次数（包括失败的调用）
+	UpstreamOperationsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "s3service_upstream_operations_total",
+		Help: "Total number of S3 API calls, including failed ones, by operation and bucket.",
+	}, []string{"operation", "bucket"})
+
+	// UpstreamDuration S3调用的耗时（包括SDK重试）
+	UpstreamDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "s3service_upstream_duration_seconds",
+		Help:    "Latency of S3 API calls in seconds, including SDK retries, by operation and bucket.",
+		Buckets: prometheus.ExponentialBuckets(0.005, 2, 15),
+	}, []string{"operation", "bucket"})
+
+	// UpstreamBytesTotal 与S3之间传输的对象内容字节数
+	UpstreamBytesTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "s3service_upstream_bytes_total",
+		Help: "Total bytes of object content sent to (upload) or received from (download) S3, by direction and bucket.",
+	}, []string{"direction", "bucket"})
+
+	// UpstreamErrorsTotal 调用S3失败的总次数
+	UpstreamErrorsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "s3service_upstream_errors_total",
+		Help: "Total number of failed S3 API calls, by operation and bucket.",
+	}, []string{"operation", "bucket"})
+
+	// SDKRetriesTotal AWS SDK重试S3请求的总次数
+	SDKRetriesTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "s3_sdk_retries_total",
+		Help: "Total number of S3 request attempts retried by the AWS SDK, by operation.",
+	}, []string{"operation"})
+
+	// SDKThrottlesTotal S3返回限流错误的总次数
+	SDKThrottlesTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "s3_sdk_throttles_total",
+		Help: "Total number of S3 request attempts rejected with a throttling error, by operation.",
+	}, []string{"operation"})
+
+	// UpstreamInFlight 当前占用全局并发限制的S3调用数
+	UpstreamInFlight = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "s3service_upstream_in_flight",
+		Help: "Number of S3 API calls currently holding a slot of the global upstream concurrency limit.",
+	})
+
+	// ReadFailoversTotal 读取操作转移到备用端点的总次数
+	ReadFailoversTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "s3service_read_failovers_total",
+		Help: "Total number of read operations retried against the failover endpoint after the primary was unreachable, by operation.",
+	}, []string{"operation"})
+
+	// IntegrityChecksTotal 后台完整性校验的对象数
+	IntegrityChecksTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "s3service_integrity_checks_total",
+		Help: "Total number of objects verified by the background integrity checker, by result (ok, mismatch, skipped, error).",
+	}, []string{"result"})
+)
+
+// BucketLabeler 将存储桶名称映射为指标标签值
+// 调用方可以传入任意存储桶名称，直接用作标签会导致时间序列数量失控，
+// 因此只有默认存储桶和白名单中的存储桶保留原名，其余统一标记为other
+type BucketLabeler struct {
+	defaultBucket string              // 默认存储桶
+	allowed       map[string]struct{} // 保留原名的存储桶
+}
+
+// NewBucketLabeler 创建存储桶标签映射器
+// 参数:
+//
+//	defaultBucket: 默认存储桶（始终保留原名）
+//	allowlist: 保留原名的存储桶白名单
+//
+// 返回值:
+//
+//	*BucketLabeler: 存储桶标签映射器
+func NewBucketLabeler(defaultBucket string, allowlist []string) *BucketLabeler {
+	allowed := make(map[string]struct{}, len(allowlist)+1)
+	allowed[defaultBucket] = struct{}{}
+	for _, bucket := range allowlist {
+		allowed[bucket] = struct{}{}
+	}
+
+	return &BucketLabeler{
+		defaultBucket: defaultBucket,
+		allowed:       allowed,
+	}
+}
+
+// Label 返回存储桶对应的标签值
+// 参数:
+//
+//	bucket: 存储桶名称（为空表示请求不涉及具体存储桶）
+//
+// 返回值:
+//
+//	string: 标签值
+func (l *BucketLabeler) Label(bucket string) string {
+	if bucket == "" {
+		return ""
+	}
+	if _, ok := l.allowed[bucket]; ok {
+		return bucket
+	}
+	return otherBucketLabel
+}
+
+// Middleware 返回记录HTTP请求指标的Echo中间件
+// 存储桶取自查询参数bucket或路径参数name，未指定时记为默认存储桶
+// 参数:
+//
+//	labeler: 存储桶标签映射器
+//
+// 返回值:
+//
+//	echo.MiddlewareFunc: Echo中间件
+func Middleware(labeler *BucketLabeler) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			err := next(c)
+
+			status := c.Response().Status
+			if err != nil {
+				var httpErr *echo.HTTPError
+				if errors.As(err, &httpErr) {
+					status = httpErr.Code
+				} else {
+					status = http.StatusInternalServerError
+				}
+			}
+
+			bucket := c.QueryParam("bucket")
+			if bucket == "" {
+				bucket = c.Param("name")
+			}
+			if bucket == "" {
+				bucket = labeler.defaultBucket
+			}
+
+			RequestsTotal.WithLabelValues(c.Request().Method, c.Path(), strconv.Itoa(status), labeler.Label(bucket)).Inc()
+
+			return err
+		}
+	}
+}
+
+// Handler 返回暴露Prometheus指标的Echo处理函数
+// 返回值:
+//
+//	echo.HandlerFunc: 处理函数
+func Handler() echo.HandlerFunc {
+	return echo.WrapHandler(promhttp.Handler())
+}
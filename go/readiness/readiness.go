@@ -0,0 +1,134 @@
+// Package readiness 提供启动阶段的就绪闸门：确认S3可用之前拒绝业务请求
+// 作者: KO
+// 创建时间: 2026-10-15
+// 修改时间: 2026-10-15
+package readiness
+
+import (
+	"context"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// probeTimeout 单次就绪探测的超时时间
+const probeTimeout = 10 * time.Second
+
+// Gate 就绪闸门
+// 探测通过之前，除豁免路由外的请求都返回503和Retry-After，客户端可以据此退避重试，
+// 而不是在滚动重启期间收到各种上游错误；探测通过后闸门保持打开
+type Gate struct {
+	ready    atomic.Bool         // 是否已就绪
+	interval time.Duration       // 探测间隔，同时作为Retry-After
+	exempt   map[string]struct{} // 豁免的路由路径（如健康检查）
+}
+
+// NewGate 创建关闭状态的就绪闸门
+// 参数:
+//
+//	interval: 探测间隔（同时作为Retry-After）
+//	exemptPaths: 不受闸门限制的路由路径（如/api/s3/health）
+//
+// 返回值:
+//
+//	*Gate: 就绪闸门
+func NewGate(interval time.Duration, exemptPaths ...string) *Gate {
+	if interval <= 0 {
+		interval = time.Second
+	}
+	g := &Gate{
+		interval: interval,
+		exempt:   make(map[string]struct{}, len(exemptPaths)),
+	}
+	for _, p := range exemptPaths {
+		g.exempt[p] = struct{}{}
+	}
+	return g
+}
+
+// Ready 返回是否已就绪
+// 返回值:
+//
+//	bool: 是否已就绪
+func (g *Gate) Ready() bool {
+	return g.ready.Load()
+}
+
+// Run 按间隔执行探测，直到探测通过（打开闸门）或上下文取消
+// 参数:
+//
+//	ctx: 上下文
+//	probe: 就绪探测函数，返回nil表示就绪
+func (g *Gate) Run(ctx context.Context, probe func(ctx context.Context) error) {
+	ticker := time.NewTicker(g.interval)
+	defer ticker.Stop()
+
+	for attempt := 1; ; attempt++ {
+		probeCtx, cancel := context.WithTimeout(ctx, probeTimeout)
+		err := probe(probeCtx)
+		cancel()
+		if err == nil {
+			g.ready.Store(true)
+			log.Printf("Service is ready after %d readiness probe(s)", attempt)
+			return
+		}
+		log.Printf("Readiness probe %d failed: %v", attempt, err)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// retryAfter 返回Retry-After响应头的值（秒，至少为1）
+// 返回值:
+//
+//	string: Retry-After的值
+func (g *Gate) retryAfter() string {
+	return strconv.Itoa(int(math.Max(1, math.Ceil(g.interval.Seconds()))))
+}
+
+// Middleware 未就绪时对非豁免路由返回503
+// 返回值:
+//
+//	echo.MiddlewareFunc: Echo中间件
+func (g *Gate) Middleware() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if g.Ready() {
+				return next(c)
+			}
+			if _, ok := g.exempt[c.Path()]; ok {
+				return next(c)
+			}
+
+			c.Response().Header().Set("Retry-After", g.retryAfter())
+			return c.JSON(http.StatusServiceUnavailable, map[string]string{
+				"error": "Service starting: S3 connectivity has not been confirmed yet",
+			})
+		}
+	}
+}
+
+// Handler 就绪探测端点：就绪时返回200，否则返回503和Retry-After
+// 参数:
+//
+//	c: Echo上下文
+//
+// 返回值:
+//
+//	error: 错误信息
+func (g *Gate) Handler(c echo.Context) error {
+	if g.Ready() {
+		return c.JSON(http.StatusOK, map[string]string{"status": "ready"})
+	}
+	c.Response().Header().Set("Retry-After", g.retryAfter())
+	return c.JSON(http.StatusServiceUnavailable, map[string]string{"status": "starting"})
+}
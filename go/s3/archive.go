@@ -0,0 +1,165 @@
+// Package s3 提供S3服务相关功能
+// 作者: KO
+// 创建时间: 2026-10-15
+// 修改时间: 2026-10-15
+package s3
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"io"
+	"log"
+	"path"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// WriteTar 将前缀下的对象以tar归档（可选gzip压缩）流式写入w，不在本地暂存
+// 条目名为键去掉前缀后规范化的相对路径（会写到解压目录之外的键跳过并记录日志），修改时间取对象的LastModified；普通对象的权限为0644，
+// 以/结尾的文件夹标记写为0755的目录条目。对象逐个下载并直接写入归档，
+// 出错时归档不完整，调用方应中断响应而不是当作正常结束
+// 参数:
+//
+//	ctx: 上下文
+//	bucket: 存储桶名称（为空时使用默认存储桶）
+//	prefix: 键前缀
+//	w: 归档写入目标
+//	gzipped: 是否以gzip压缩归档
+//
+// 返回值:
+//
+//	int: 写入的条目数
+//	error: 错误信息，对象是客户端加密的时返回ErrEncryptionKeyRequired
+func (s *Service) WriteTar(ctx context.Context, bucket, prefix string, w io.Writer, gzipped bool) (int, error) {
+	if bucket == "" {
+		bucket = s.defaultBucket
+	}
+
+	var gz *gzip.Writer
+	if gzipped {
+		gz = gzip.NewWriter(w)
+		w = gz
+	}
+	tw := tar.NewWriter(w)
+
+	count := 0
+	err := s.listAllObjects(ctx, bucket, prefix, func(obj types.Object) error {
+		key := aws.ToString(obj.Key)
+		name, ok := tarEntryName(key, prefix)
+		if !ok {
+			log.Printf("Skipping %s/%s in tar archive: entry name would escape the extraction directory", bucket, key)
+			return nil
+		}
+		if name == "" {
+			return nil
+		}
+
+		if strings.HasSuffix(name, "/") {
+			count++
+			return tw.WriteHeader(&tar.Header{
+				Typeflag: tar.TypeDir,
+				Name:     name,
+				Mode:     0755,
+				ModTime:  aws.ToTime(obj.LastModified),
+			})
+		}
+
+		if err := s.writeTarEntry(ctx, tw, bucket, key, name, obj); err != nil {
+			return err
+		}
+		count++
+		return nil
+	})
+	if err != nil {
+		return count, err
+	}
+
+	if err := tw.Close(); err != nil {
+		return count, err
+	}
+	if gz != nil {
+		if err := gz.Close(); err != nil {
+			return count, err
+		}
+	}
+	return count, nil
+}
+
+// tarEntryName 计算对象在tar归档中的条目名：键去掉前缀后规范化的相对路径，文件夹标记以/结尾
+// 键中可以包含..等路径片段，原样写入会让解压工具把文件写到目标目录之外，此类条目应跳过
+// 参数:
+//
+//	key: 对象键
+//	prefix: 键前缀
+//
+// 返回值:
+//
+//	string: 条目名（为空时表示前缀本身，无需写入）
+//	bool: 条目名是否安全（不是绝对路径，也不指向上级目录）
+func tarEntryName(key, prefix string) (string, bool) {
+	name := strings.TrimLeft(strings.TrimPrefix(key, prefix), "/")
+	if name == "" {
+		return "", true
+	}
+
+	cleaned := path.Clean(name)
+	if path.IsAbs(cleaned) || cleaned == ".." || strings.HasPrefix(cleaned, "../") {
+		return "", false
+	}
+	if cleaned == "." {
+		return "", true
+	}
+	if strings.HasSuffix(name, "/") {
+		cleaned += "/"
+	}
+	return cleaned, true
+}
+
+// writeTarEntry 下载一个对象并写为tar归档中的普通文件条目
+// 参数:
+//
+//	ctx: 上下文
+//	tw: tar写入器
+//	bucket: 存储桶名称
+//	key: 对象键
+//	name: 条目名
+//	obj: 列举得到的对象信息
+//
+// 返回值:
+//
+//	error: 错误信息
+func (s *Service) writeTarEntry(ctx context.Context, tw *tar.Writer, bucket, key, name string, obj types.Object) error {
+	output, err := s.client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return err
+	}
+	defer output.Body.Close()
+
+	if output.Metadata[MetaClientEncryption] != "" {
+		return ErrEncryptionKeyRequired
+	}
+
+	modTime := aws.ToTime(obj.LastModified)
+	if output.LastModified != nil {
+		modTime = *output.LastModified
+	}
+	if err := tw.WriteHeader(&tar.Header{
+		Typeflag: tar.TypeReg,
+		Name:     name,
+		Mode:     0644,
+		Size:     aws.ToInt64(output.ContentLength),
+		ModTime:  modTime,
+	}); err != nil {
+		return err
+	}
+
+	_, err = io.Copy(tw, output.Body)
+	return err
+}
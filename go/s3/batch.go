@@ -0,0 +1,297 @@
+// Package s3 提供S3服务相关功能
+// 作者: KO
+// 创建时间: 2026-10-15
+// 修改时间: 2026-10-15
+package s3
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// ErrInvalidBatchDestination 批量复制/移动的目标与源相同
+var ErrInvalidBatchDestination = errors.New("invalid batch destination")
+
+// BatchResult 批量操作中单个键的处理结果
+type BatchResult struct {
+	Key         string `json:"key"`                   // 文件键
+	Destination string `json:"destination,omitempty"` // 复制/移动的目标键
+	Error       string `json:"error,omitempty"`       // 错误信息
+}
+
+// runBatch 按worker_concurrency并发对每个键执行fn
+// 每个键完成后将其结果发送到progress（为nil时不发送），调用方负责在返回后关闭progress；
+// 上下文取消后未启动的键以错误结果返回，不发送到progress
+// 参数:
+//
+//	ctx: 上下文
+//	keys: 文件键列表
+//	progress: 进度通道（可为nil）
+//	fn: 处理单个键的函数
+//
+// 返回值:
+//
+//	[]BatchResult: 每个键的处理结果（与keys顺序一致）
+func (s *Service) runBatch(ctx context.Context, keys []string, progress chan<- BatchResult, fn func(ctx context.Context, key string) BatchResult) []BatchResult {
+	results := make([]BatchResult, len(keys))
+	started := make([]bool, len(keys))
+	forEachConcurrent(ctx, s.concurrency, len(keys), func(ctx context.Context, i int) {
+		started[i] = true
+		results[i] = fn(ctx, keys[i])
+		if progress != nil {
+			progress <- results[i]
+		}
+	})
+
+	for i := range results {
+		if !started[i] {
+			results[i] = BatchResult{Key: keys[i], Error: "Not attempted: " + context.Cause(ctx).Error()}
+		}
+	}
+	return results
+}
+
+// maxDeleteObjects 单次DeleteObjects请求最多删除的对象数
+const maxDeleteObjects = 1000
+
+// DeleteError 批量删除中单个键的失败信息（来自DeleteObjectsOutput.Errors）
+type DeleteError struct {
+	Key     string `json:"key"`     // 文件键
+	Code    string `json:"code"`    // S3错误码（如AccessDenied）
+	Message string `json:"message"` // 错误信息
+}
+
+// DeleteResult 批量删除的结果
+type DeleteResult struct {
+	Deleted []string      `json:"deleted"` // 已删除的键
+	Errors  []DeleteError `json:"errors"`  // 删除失败的键
+}
+
+// BatchDelete 以DeleteObjects批量删除多个文件
+// 每1000个键一次请求，多次请求按worker_concurrency并发执行；键按原样删除，不做大小写不敏感解析。
+// 单个键的失败在Errors中报告；某次请求整体失败时，其中的键以该请求的错误码报告，
+// 所有请求都整体失败时返回错误
+// 参数:
+//
+//	ctx: 上下文
+//	bucket: 存储桶名称（为空时使用默认存储桶）
+//	keys: 文件键列表
+//	progress: 每次请求完成后接收其中每个键结果的通道（可为nil）
+//
+// 返回值:
+//
+//	*DeleteResult: 删除结果
+//	error: 所有DeleteObjects请求都失败时的错误信息
+func (s *Service) BatchDelete(ctx context.Context, bucket string, keys []string, progress chan<- BatchResult) (*DeleteResult, error) {
+	if bucket == "" {
+		bucket = s.defaultBucket
+	}
+
+	chunks := chunkKeys(keys, maxDeleteObjects)
+	results := make([]DeleteResult, len(chunks))
+	errs := make([]error, len(chunks))
+	forEachConcurrent(ctx, s.concurrency, len(chunks), func(ctx context.Context, i int) {
+		chunk := chunks[i]
+		results[i], errs[i] = s.deleteObjects(ctx, bucket, chunk)
+		if errs[i] != nil {
+			code := errorCode(errs[i])
+			if code == "" {
+				code = "InternalError"
+			}
+			for _, key := range chunk {
+				results[i].Errors = append(results[i].Errors, DeleteError{Key: key, Code: code, Message: errs[i].Error()})
+			}
+		}
+		if progress != nil {
+			for _, key := range results[i].Deleted {
+				progress <- BatchResult{Key: key}
+			}
+			for _, e := range results[i].Errors {
+				progress <- BatchResult{Key: e.Key, Error: e.Code + ": " + e.Message}
+			}
+		}
+	})
+
+	result := &DeleteResult{Deleted: []string{}, Errors: []DeleteError{}}
+	var firstErr error
+	failed := 0
+	for i := range results {
+		if errs[i] == nil && results[i].Deleted == nil && results[i].Errors == nil {
+			// 上下文取消后未启动的请求
+			errs[i] = context.Cause(ctx)
+			for _, key := range chunks[i] {
+				results[i].Errors = append(results[i].Errors, DeleteError{Key: key, Code: "NotAttempted", Message: errs[i].Error()})
+			}
+		}
+		if errs[i] != nil {
+			failed++
+			if firstErr == nil {
+				firstErr = errs[i]
+			}
+		}
+		result.Deleted = append(result.Deleted, results[i].Deleted...)
+		result.Errors = append(result.Errors, results[i].Errors...)
+	}
+	if len(chunks) > 0 && failed == len(chunks) {
+		return nil, firstErr
+	}
+
+	return result, nil
+}
+
+// chunkKeys 将键列表按size切分
+// 参数:
+//
+//	keys: 文件键列表
+//	size: 每组的最大数量
+//
+// 返回值:
+//
+//	[][]string: 切分后的键列表
+func chunkKeys(keys []string, size int) [][]string {
+	chunks := make([][]string, 0, (len(keys)+size-1)/size)
+	for len(keys) > size {
+		chunks = append(chunks, keys[:size])
+		keys = keys[size:]
+	}
+	if len(keys) > 0 {
+		chunks = append(chunks, keys)
+	}
+	return chunks
+}
+
+// deleteObjects 以一次DeleteObjects请求删除最多1000个键
+// 参数:
+//
+//	ctx: 上下文
+//	bucket: 存储桶名称
+//	keys: 文件键列表
+//
+// 返回值:
+//
+//	DeleteResult: 删除结果
+//	error: 请求整体失败时的错误信息
+func (s *Service) deleteObjects(ctx context.Context, bucket string, keys []string) (DeleteResult, error) {
+	objects := make([]types.ObjectIdentifier, len(keys))
+	for i, key := range keys {
+		objects[i] = types.ObjectIdentifier{Key: aws.String(key)}
+	}
+
+	output, err := s.client.DeleteObjects(ctx, &s3.DeleteObjectsInput{
+		Bucket: aws.String(bucket),
+		Delete: &types.Delete{Objects: objects},
+	})
+	if err != nil {
+		return DeleteResult{}, err
+	}
+
+	result := DeleteResult{Deleted: make([]string, 0, len(output.Deleted))}
+	for _, deleted := range output.Deleted {
+		key := aws.ToString(deleted.Key)
+		result.Deleted = append(result.Deleted, key)
+		if s.index != nil {
+			s.index.remove(bucket, key)
+		}
+	}
+	for _, e := range output.Errors {
+		result.Errors = append(result.Errors, DeleteError{
+			Key:     aws.ToString(e.Key),
+			Code:    aws.ToString(e.Code),
+			Message: aws.ToString(e.Message),
+		})
+	}
+
+	return result, nil
+}
+
+// BatchCopy 并发将多个文件复制（move为true时移动）到目标存储桶的dstPrefix下
+// 目标键为dstPrefix+原键；移动以服务端复制后删除源对象的方式完成，
+// 复制成功但删除失败时源对象保留，并在结果中报告
+// 参数:
+//
+//	ctx: 上下文
+//	bucket: 源存储桶名称（为空时使用默认存储桶）
+//	keys: 文件键列表
+//	dstBucket: 目标存储桶名称（为空时使用源存储桶）
+//	dstPrefix: 目标键前缀
+//	move: 是否在复制后删除源对象
+//	progress: 每个键完成后接收其结果的通道（可为nil）
+//
+// 返回值:
+//
+//	[]BatchResult: 每个键的处理结果
+//	error: 目标与源相同时返回ErrInvalidBatchDestination
+func (s *Service) BatchCopy(ctx context.Context, bucket string, keys []string, dstBucket, dstPrefix string, move bool, progress chan<- BatchResult) ([]BatchResult, error) {
+	if bucket == "" {
+		bucket = s.defaultBucket
+	}
+	if dstBucket == "" {
+		dstBucket = bucket
+	}
+	// 目标与源相同时，移动会在复制后删除对象本身
+	if dstBucket == bucket && dstPrefix == "" {
+		return nil, fmt.Errorf("%w: destination bucket or prefix must differ from the source", ErrInvalidBatchDestination)
+	}
+
+	return s.runBatch(ctx, keys, progress, func(ctx context.Context, key string) BatchResult {
+		result := BatchResult{Key: key, Destination: dstPrefix + key}
+		if err := s.CopyObject(ctx, bucket, key, dstBucket, result.Destination); err != nil {
+			result.Error = "Failed to copy object: " + err.Error()
+			return result
+		}
+		if move {
+			if err := s.DeleteFile(ctx, bucket, key); err != nil {
+				result.Error = "Copied, but failed to delete source: " + err.Error()
+			}
+		}
+		return result
+	}), nil
+}
+
+// StatResult 批量获取元数据时单个键的结果
+type StatResult struct {
+	Key          string     `json:"key"`                    // 文件键
+	Exists       bool       `json:"exists"`                 // 是否存在
+	Size         int64      `json:"size,omitempty"`         // 文件大小（字节）
+	ContentType  string     `json:"contentType,omitempty"`  // 内容类型
+	ETag         string     `json:"etag,omitempty"`         // 实体标签
+	LastModified *time.Time `json:"lastModified,omitempty"` // 最后修改时间
+	Error        string     `json:"error,omitempty"`        // 上游错误（不存在不视为错误）
+}
+
+// StatObjects 按worker_concurrency并发获取多个文件的元数据
+// 每个键完成后立即将结果发送到results（按完成顺序，而不是keys的顺序），调用方负责在返回后关闭results；
+// 不存在的键Exists为false且没有Error，其他失败在Error中报告。上下文取消后未启动的键不再发送
+// 参数:
+//
+//	ctx: 上下文
+//	bucket: 存储桶名称（为空时使用默认存储桶）
+//	keys: 文件键列表
+//	results: 接收结果的通道
+func (s *Service) StatObjects(ctx context.Context, bucket string, keys []string, results chan<- StatResult) {
+	if bucket == "" {
+		bucket = s.defaultBucket
+	}
+
+	forEachConcurrent(ctx, s.concurrency, len(keys), func(ctx context.Context, i int) {
+		result := StatResult{Key: keys[i]}
+		info, err := s.StatObject(ctx, bucket, keys[i])
+		switch {
+		case err == nil:
+			result.Exists = true
+			result.Size = info.Size
+			result.ContentType = info.ContentType
+			result.ETag = info.ETag
+			result.LastModified = info.LastModified
+		case !IsNotFound(err):
+			result.Error = err.Error()
+		}
+		results <- result
+	})
+}
@@ -0,0 +1,418 @@
+// Package s3 提供S3服务相关功能
+// 作者: KO
+// 创建时间: 2026-10-15
+// 修改时间: 2026-10-15
+package s3
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// ErrInvalidBucketConfig 存储桶配置快照中的取值无效
+var ErrInvalidBucketConfig = errors.New("invalid bucket config")
+
+// BucketConfig 存储桶配置快照
+type BucketConfig struct {
+	Versioning      string          `json:"versioning"`       // 版本控制状态（Enabled、Suspended，从未启用时为空）
+	CORS            []CORSRule      `json:"cors"`             // CORS规则
+	Lifecycle       []LifecycleRule `json:"lifecycle"`        // 生命周期规则
+	Policy          json.RawMessage `json:"policy,omitempty"` // 存储桶策略（原样的JSON文档）
+	ObjectOwnership string          `json:"objectOwnership"`  // 对象所有权
+}
+
+// CORSRule CORS规则
+type CORSRule struct {
+	ID             string   `json:"id,omitempty"`            // 规则ID
+	AllowedHeaders []string `json:"allowedHeaders"`          // 允许的请求头
+	AllowedMethods []string `json:"allowedMethods"`          // 允许的方法
+	AllowedOrigins []string `json:"allowedOrigins"`          // 允许的来源
+	ExposeHeaders  []string `json:"exposeHeaders"`           // 暴露给浏览器的响应头
+	MaxAgeSeconds  *int32   `json:"maxAgeSeconds,omitempty"` // 预检结果缓存时间
+}
+
+// LifecycleFilter 生命周期规则的过滤条件，多个条件同时设置时表示“且”
+type LifecycleFilter struct {
+	Prefix                string            `json:"prefix,omitempty"`                // 键前缀
+	Tags                  map[string]string `json:"tags,omitempty"`                  // 对象标签
+	ObjectSizeGreaterThan *int64            `json:"objectSizeGreaterThan,omitempty"` // 对象大小下限（字节）
+	ObjectSizeLessThan    *int64            `json:"objectSizeLessThan,omitempty"`    // 对象大小上限（字节）
+}
+
+// LifecycleTransition 当前版本的存储类别转换
+type LifecycleTransition struct {
+	Days         *int32     `json:"days,omitempty"` // 创建后的天数
+	Date         *time.Time `json:"date,omitempty"` // 指定日期
+	StorageClass string     `json:"storageClass"`   // 目标存储类别
+}
+
+// NoncurrentTransition 非当前版本的存储类别转换
+type NoncurrentTransition struct {
+	NoncurrentDays          *int32 `json:"noncurrentDays,omitempty"`          // 成为非当前版本后的天数
+	NewerNoncurrentVersions *int32 `json:"newerNoncurrentVersions,omitempty"` // 保留的较新非当前版本数
+	StorageClass            string `json:"storageClass"`                      // 目标存储类别
+}
+
+// LifecycleRule 生命周期规则
+type LifecycleRule struct {
+	ID                        string                 `json:"id,omitempty"`                        // 规则ID
+	Status                    string                 `json:"status"`                              // Enabled或Disabled
+	Filter                    LifecycleFilter        `json:"filter"`                              // 过滤条件
+	ExpirationDays            *int32                 `json:"expirationDays,omitempty"`            // 创建后过期的天数
+	ExpirationDate            *time.Time             `json:"expirationDate,omitempty"`            // 过期日期
+	ExpiredObjectDeleteMarker *bool                  `json:"expiredObjectDeleteMarker,omitempty"` // 是否清理过期的删除标记
+	NoncurrentExpirationDays  *int32                 `json:"noncurrentExpirationDays,omitempty"`  // 非当前版本过期的天数
+	NewerNoncurrentVersions   *int32                 `json:"newerNoncurrentVersions,omitempty"`   // 过期时保留的较新非当前版本数
+	AbortIncompleteUploadDays *int32                 `json:"abortIncompleteUploadDays,omitempty"` // 中止未完成分段上传的天数
+	Transitions               []LifecycleTransition  `json:"transitions,omitempty"`               // 当前版本的存储类别转换
+	NoncurrentTransitions     []NoncurrentTransition `json:"noncurrentTransitions,omitempty"`     // 非当前版本的存储类别转换
+}
+
+// isMissingConfig 判断错误是否表示该项配置不存在或后端不支持（视为空配置）
+// 参数:
+//
+//	err: 错误
+//
+// 返回值:
+//
+//	bool: 是否视为空配置
+func isMissingConfig(err error) bool {
+	switch errorCode(err) {
+	case "NoSuchCORSConfiguration", "NoSuchLifecycleConfiguration", "NoSuchBucketPolicy",
+		"OwnershipControlsNotFoundError", "NotImplemented":
+		return true
+	}
+	return false
+}
+
+// GetBucketConfig 汇总存储桶的版本控制、CORS、生命周期、策略和对象所有权配置
+// 各项通过对应的Get接口读取，不存在（NoSuch*）或后端不支持的项视为空
+// 参数:
+//
+//	ctx: 上下文
+//	bucket: 存储桶名称
+//
+// 返回值:
+//
+//	*BucketConfig: 配置快照
+//	error: 错误信息
+func (s *Service) GetBucketConfig(ctx context.Context, bucket string) (*BucketConfig, error) {
+	snapshot := &BucketConfig{
+		CORS:      []CORSRule{},
+		Lifecycle: []LifecycleRule{},
+	}
+
+	versioning, err := s.client.GetBucketVersioning(ctx, &s3.GetBucketVersioningInput{Bucket: aws.String(bucket)})
+	if err != nil && !isMissingConfig(err) {
+		return nil, fmt.Errorf("get versioning: %w", err)
+	}
+	if err == nil {
+		snapshot.Versioning = string(versioning.Status)
+	}
+
+	cors, err := s.client.GetBucketCors(ctx, &s3.GetBucketCorsInput{Bucket: aws.String(bucket)})
+	if err != nil && !isMissingConfig(err) {
+		return nil, fmt.Errorf("get cors: %w", err)
+	}
+	if err == nil {
+		for _, rule := range cors.CORSRules {
+			snapshot.CORS = append(snapshot.CORS, CORSRule{
+				ID:             aws.ToString(rule.ID),
+				AllowedHeaders: rule.AllowedHeaders,
+				AllowedMethods: rule.AllowedMethods,
+				AllowedOrigins: rule.AllowedOrigins,
+				ExposeHeaders:  rule.ExposeHeaders,
+				MaxAgeSeconds:  rule.MaxAgeSeconds,
+			})
+		}
+	}
+
+	lifecycle, err := s.client.GetBucketLifecycleConfiguration(ctx, &s3.GetBucketLifecycleConfigurationInput{Bucket: aws.String(bucket)})
+	if err != nil && !isMissingConfig(err) {
+		return nil, fmt.Errorf("get lifecycle: %w", err)
+	}
+	if err == nil {
+		for _, rule := range lifecycle.Rules {
+			snapshot.Lifecycle = append(snapshot.Lifecycle, lifecycleRuleFromSDK(rule))
+		}
+	}
+
+	policy, err := s.client.GetBucketPolicy(ctx, &s3.GetBucketPolicyInput{Bucket: aws.String(bucket)})
+	if err != nil && !isMissingConfig(err) {
+		return nil, fmt.Errorf("get policy: %w", err)
+	}
+	if err == nil && aws.ToString(policy.Policy) != "" {
+		snapshot.Policy = json.RawMessage(aws.ToString(policy.Policy))
+	}
+
+	ownership, err := s.client.GetBucketOwnershipControls(ctx, &s3.GetBucketOwnershipControlsInput{Bucket: aws.String(bucket)})
+	if err != nil && !isMissingConfig(err) {
+		return nil, fmt.Errorf("get ownership controls: %w", err)
+	}
+	if err == nil && ownership.OwnershipControls != nil && len(ownership.OwnershipControls.Rules) > 0 {
+		snapshot.ObjectOwnership = string(ownership.OwnershipControls.Rules[0].ObjectOwnership)
+	}
+
+	return snapshot, nil
+}
+
+// PutBucketConfig 将配置快照应用到存储桶
+// 快照中为空的CORS、生命周期、策略和对象所有权会删除存储桶上的对应配置；
+// 版本控制为空时保持不变（已启用的版本控制只能暂停，不能关闭）。
+// 策略原样应用，其中引用源存储桶ARN的语句需要先按目标存储桶修改
+// 参数:
+//
+//	ctx: 上下文
+//	bucket: 存储桶名称
+//	snapshot: 配置快照
+//
+// 返回值:
+//
+//	error: 错误信息，取值无效时包装ErrInvalidBucketConfig
+func (s *Service) PutBucketConfig(ctx context.Context, bucket string, snapshot *BucketConfig) error {
+	if snapshot.Versioning != "" && !containsString(types.BucketVersioningStatus("").Values(), types.BucketVersioningStatus(snapshot.Versioning)) {
+		return fmt.Errorf("%w: unknown versioning %q", ErrInvalidBucketConfig, snapshot.Versioning)
+	}
+	if snapshot.ObjectOwnership != "" && !containsString(types.ObjectOwnership("").Values(), types.ObjectOwnership(snapshot.ObjectOwnership)) {
+		return fmt.Errorf("%w: unknown objectOwnership %q", ErrInvalidBucketConfig, snapshot.ObjectOwnership)
+	}
+	if len(snapshot.Policy) > 0 && !json.Valid(snapshot.Policy) {
+		return fmt.Errorf("%w: policy is not valid JSON", ErrInvalidBucketConfig)
+	}
+
+	if snapshot.Versioning != "" {
+		_, err := s.client.PutBucketVersioning(ctx, &s3.PutBucketVersioningInput{
+			Bucket:                  aws.String(bucket),
+			VersioningConfiguration: &types.VersioningConfiguration{Status: types.BucketVersioningStatus(snapshot.Versioning)},
+		})
+		if err != nil {
+			return fmt.Errorf("put versioning: %w", err)
+		}
+	}
+
+	if len(snapshot.CORS) > 0 {
+		rules := make([]types.CORSRule, 0, len(snapshot.CORS))
+		for _, rule := range snapshot.CORS {
+			sdkRule := types.CORSRule{
+				AllowedHeaders: rule.AllowedHeaders,
+				AllowedMethods: rule.AllowedMethods,
+				AllowedOrigins: rule.AllowedOrigins,
+				ExposeHeaders:  rule.ExposeHeaders,
+				MaxAgeSeconds:  rule.MaxAgeSeconds,
+			}
+			if rule.ID != "" {
+				sdkRule.ID = aws.String(rule.ID)
+			}
+			rules = append(rules, sdkRule)
+		}
+		if _, err := s.client.PutBucketCors(ctx, &s3.PutBucketCorsInput{
+			Bucket:            aws.String(bucket),
+			CORSConfiguration: &types.CORSConfiguration{CORSRules: rules},
+		}); err != nil {
+			return fmt.Errorf("put cors: %w", err)
+		}
+	} else if _, err := s.client.DeleteBucketCors(ctx, &s3.DeleteBucketCorsInput{Bucket: aws.String(bucket)}); err != nil && !isMissingConfig(err) {
+		return fmt.Errorf("delete cors: %w", err)
+	}
+
+	if len(snapshot.Lifecycle) > 0 {
+		rules := make([]types.LifecycleRule, 0, len(snapshot.Lifecycle))
+		for _, rule := range snapshot.Lifecycle {
+			rules = append(rules, rule.toSDK())
+		}
+		if _, err := s.client.PutBucketLifecycleConfiguration(ctx, &s3.PutBucketLifecycleConfigurationInput{
+			Bucket:                 aws.String(bucket),
+			LifecycleConfiguration: &types.BucketLifecycleConfiguration{Rules: rules},
+		}); err != nil {
+			return fmt.Errorf("put lifecycle: %w", err)
+		}
+	} else if _, err := s.client.DeleteBucketLifecycle(ctx, &s3.DeleteBucketLifecycleInput{Bucket: aws.String(bucket)}); err != nil && !isMissingConfig(err) {
+		return fmt.Errorf("delete lifecycle: %w", err)
+	}
+
+	if len(snapshot.Policy) > 0 {
+		if _, err := s.client.PutBucketPolicy(ctx, &s3.PutBucketPolicyInput{
+			Bucket: aws.String(bucket),
+			Policy: aws.String(string(snapshot.Policy)),
+		}); err != nil {
+			return fmt.Errorf("put policy: %w", err)
+		}
+	} else if _, err := s.client.DeleteBucketPolicy(ctx, &s3.DeleteBucketPolicyInput{Bucket: aws.String(bucket)}); err != nil && !isMissingConfig(err) {
+		return fmt.Errorf("delete policy: %w", err)
+	}
+
+	if snapshot.ObjectOwnership != "" {
+		if _, err := s.client.PutBucketOwnershipControls(ctx, &s3.PutBucketOwnershipControlsInput{
+			Bucket: aws.String(bucket),
+			OwnershipControls: &types.OwnershipControls{Rules: []types.OwnershipControlsRule{
+				{ObjectOwnership: types.ObjectOwnership(snapshot.ObjectOwnership)},
+			}},
+		}); err != nil {
+			return fmt.Errorf("put ownership controls: %w", err)
+		}
+	} else if _, err := s.client.DeleteBucketOwnershipControls(ctx, &s3.DeleteBucketOwnershipControlsInput{Bucket: aws.String(bucket)}); err != nil && !isMissingConfig(err) {
+		return fmt.Errorf("delete ownership controls: %w", err)
+	}
+
+	return nil
+}
+
+// lifecycleRuleFromSDK 将SDK的生命周期规则转换为快照格式
+// 参数:
+//
+//	rule: SDK生命周期规则
+//
+// 返回值:
+//
+//	LifecycleRule: 快照中的生命周期规则
+func lifecycleRuleFromSDK(rule types.LifecycleRule) LifecycleRule {
+	result := LifecycleRule{
+		ID:     aws.ToString(rule.ID),
+		Status: string(rule.Status),
+	}
+
+	switch filter := rule.Filter.(type) {
+	case *types.LifecycleRuleFilterMemberPrefix:
+		result.Filter.Prefix = filter.Value
+	case *types.LifecycleRuleFilterMemberTag:
+		result.Filter.Tags = map[string]string{aws.ToString(filter.Value.Key): aws.ToString(filter.Value.Value)}
+	case *types.LifecycleRuleFilterMemberObjectSizeGreaterThan:
+		result.Filter.ObjectSizeGreaterThan = aws.Int64(filter.Value)
+	case *types.LifecycleRuleFilterMemberObjectSizeLessThan:
+		result.Filter.ObjectSizeLessThan = aws.Int64(filter.Value)
+	case *types.LifecycleRuleFilterMemberAnd:
+		result.Filter.Prefix = aws.ToString(filter.Value.Prefix)
+		result.Filter.ObjectSizeGreaterThan = filter.Value.ObjectSizeGreaterThan
+		result.Filter.ObjectSizeLessThan = filter.Value.ObjectSizeLessThan
+		if len(filter.Value.Tags) > 0 {
+			result.Filter.Tags = make(map[string]string, len(filter.Value.Tags))
+			for _, tag := range filter.Value.Tags {
+				result.Filter.Tags[aws.ToString(tag.Key)] = aws.ToString(tag.Value)
+			}
+		}
+	case nil:
+		// 旧格式的规则直接在规则上指定前缀
+		result.Filter.Prefix = aws.ToString(rule.Prefix)
+	}
+
+	if rule.Expiration != nil {
+		result.ExpirationDays = rule.Expiration.Days
+		result.ExpirationDate = rule.Expiration.Date
+		result.ExpiredObjectDeleteMarker = rule.Expiration.ExpiredObjectDeleteMarker
+	}
+	if rule.NoncurrentVersionExpiration != nil {
+		result.NoncurrentExpirationDays = rule.NoncurrentVersionExpiration.NoncurrentDays
+		result.NewerNoncurrentVersions = rule.NoncurrentVersionExpiration.NewerNoncurrentVersions
+	}
+	if rule.AbortIncompleteMultipartUpload != nil {
+		result.AbortIncompleteUploadDays = rule.AbortIncompleteMultipartUpload.DaysAfterInitiation
+	}
+	for _, t := range rule.Transitions {
+		result.Transitions = append(result.Transitions, LifecycleTransition{
+			Days:         t.Days,
+			Date:         t.Date,
+			StorageClass: string(t.StorageClass),
+		})
+	}
+	for _, t := range rule.NoncurrentVersionTransitions {
+		result.NoncurrentTransitions = append(result.NoncurrentTransitions, NoncurrentTransition{
+			NoncurrentDays:          t.NoncurrentDays,
+			NewerNoncurrentVersions: t.NewerNoncurrentVersions,
+			StorageClass:            string(t.StorageClass),
+		})
+	}
+
+	return result
+}
+
+// toSDK 将快照中的生命周期规则转换为SDK格式
+// 只有一个过滤条件时使用对应的单一条件，多个条件时使用And组合
+// 返回值:
+//
+//	types.LifecycleRule: SDK生命周期规则
+func (r LifecycleRule) toSDK() types.LifecycleRule {
+	rule := types.LifecycleRule{
+		Status: types.ExpirationStatus(r.Status),
+	}
+	if r.ID != "" {
+		rule.ID = aws.String(r.ID)
+	}
+
+	conditions := len(r.Filter.Tags)
+	if r.Filter.Prefix != "" {
+		conditions++
+	}
+	if r.Filter.ObjectSizeGreaterThan != nil {
+		conditions++
+	}
+	if r.Filter.ObjectSizeLessThan != nil {
+		conditions++
+	}
+
+	switch {
+	case conditions > 1:
+		and := types.LifecycleRuleAndOperator{
+			ObjectSizeGreaterThan: r.Filter.ObjectSizeGreaterThan,
+			ObjectSizeLessThan:    r.Filter.ObjectSizeLessThan,
+		}
+		if r.Filter.Prefix != "" {
+			and.Prefix = aws.String(r.Filter.Prefix)
+		}
+		for key, value := range r.Filter.Tags {
+			and.Tags = append(and.Tags, types.Tag{Key: aws.String(key), Value: aws.String(value)})
+		}
+		rule.Filter = &types.LifecycleRuleFilterMemberAnd{Value: and}
+	case len(r.Filter.Tags) == 1:
+		for key, value := range r.Filter.Tags {
+			rule.Filter = &types.LifecycleRuleFilterMemberTag{Value: types.Tag{Key: aws.String(key), Value: aws.String(value)}}
+		}
+	case r.Filter.ObjectSizeGreaterThan != nil:
+		rule.Filter = &types.LifecycleRuleFilterMemberObjectSizeGreaterThan{Value: *r.Filter.ObjectSizeGreaterThan}
+	case r.Filter.ObjectSizeLessThan != nil:
+		rule.Filter = &types.LifecycleRuleFilterMemberObjectSizeLessThan{Value: *r.Filter.ObjectSizeLessThan}
+	default:
+		rule.Filter = &types.LifecycleRuleFilterMemberPrefix{Value: r.Filter.Prefix}
+	}
+
+	if r.ExpirationDays != nil || r.ExpirationDate != nil || r.ExpiredObjectDeleteMarker != nil {
+		rule.Expiration = &types.LifecycleExpiration{
+			Days:                      r.ExpirationDays,
+			Date:                      r.ExpirationDate,
+			ExpiredObjectDeleteMarker: r.ExpiredObjectDeleteMarker,
+		}
+	}
+	if r.NoncurrentExpirationDays != nil || r.NewerNoncurrentVersions != nil {
+		rule.NoncurrentVersionExpiration = &types.NoncurrentVersionExpiration{
+			NoncurrentDays:          r.NoncurrentExpirationDays,
+			NewerNoncurrentVersions: r.NewerNoncurrentVersions,
+		}
+	}
+	if r.AbortIncompleteUploadDays != nil {
+		rule.AbortIncompleteMultipartUpload = &types.AbortIncompleteMultipartUpload{
+			DaysAfterInitiation: r.AbortIncompleteUploadDays,
+		}
+	}
+	for _, t := range r.Transitions {
+		rule.Transitions = append(rule.Transitions, types.Transition{
+			Days:         t.Days,
+			Date:         t.Date,
+			StorageClass: types.TransitionStorageClass(t.StorageClass),
+		})
+	}
+	for _, t := range r.NoncurrentTransitions {
+		rule.NoncurrentVersionTransitions = append(rule.NoncurrentVersionTransitions, types.NoncurrentVersionTransition{
+			NoncurrentDays:          t.NoncurrentDays,
+			NewerNoncurrentVersions: t.NewerNoncurrentVersions,
+			StorageClass:            types.TransitionStorageClass(t.StorageClass),
+		})
+	}
+
+	return rule
+}
@@ -0,0 +1,75 @@
+// Package s3 提供S3服务相关功能
+// 作者: KO
+// 创建时间: 2026-10-15
+// 修改时间: 2026-10-15
+package s3
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+)
+
+// ErrInvalidBucketName 存储桶名称不符合S3的命名规则
+var ErrInvalidBucketName = errors.New("invalid bucket name")
+
+// S3保留的存储桶名称前缀和后缀
+var (
+	reservedBucketPrefixes = []string{"xn--", "sthree-", "amzn-s3-demo-"}
+	reservedBucketSuffixes = []string{"-s3alias", "--ol-s3", ".mrap", "--x-s3", "--table-s3"}
+)
+
+// ValidateBucketName 按S3的通用存储桶命名规则检查名称
+// 长度为3到63个字符；只包含小写字母、数字、点和连字符；以字母或数字开头和结尾；
+// 不包含连续的点；不是IP地址形式（如192.168.5.4）；不使用S3保留的前缀和后缀
+// 参数:
+//
+//	name: 存储桶名称
+//
+// 返回值:
+//
+//	error: 不符合规则时返回包装了ErrInvalidBucketName的错误，说明违反的规则
+func ValidateBucketName(name string) error {
+	if len(name) < 3 || len(name) > 63 {
+		return fmt.Errorf("%w %q: must be between 3 and 63 characters long", ErrInvalidBucketName, name)
+	}
+	for i := 0; i < len(name); i++ {
+		c := name[i]
+		if !(c >= 'a' && c <= 'z' || c >= '0' && c <= '9' || c == '.' || c == '-') {
+			return fmt.Errorf("%w %q: may only contain lowercase letters, digits, dots and hyphens", ErrInvalidBucketName, name)
+		}
+	}
+	if !isLowerAlnum(name[0]) || !isLowerAlnum(name[len(name)-1]) {
+		return fmt.Errorf("%w %q: must begin and end with a letter or digit", ErrInvalidBucketName, name)
+	}
+	if strings.Contains(name, "..") {
+		return fmt.Errorf("%w %q: must not contain two adjacent dots", ErrInvalidBucketName, name)
+	}
+	if net.ParseIP(name) != nil {
+		return fmt.Errorf("%w %q: must not be formatted as an IP address", ErrInvalidBucketName, name)
+	}
+	for _, prefix := range reservedBucketPrefixes {
+		if strings.HasPrefix(name, prefix) {
+			return fmt.Errorf("%w %q: the prefix %s is reserved", ErrInvalidBucketName, name, prefix)
+		}
+	}
+	for _, suffix := range reservedBucketSuffixes {
+		if strings.HasSuffix(name, suffix) {
+			return fmt.Errorf("%w %q: the suffix %s is reserved", ErrInvalidBucketName, name, suffix)
+		}
+	}
+	return nil
+}
+
+// isLowerAlnum 判断字节是否为小写字母或数字
+// 参数:
+//
+//	c: 字节
+//
+// 返回值:
+//
+//	bool: 是否为小写字母或数字
+func isLowerAlnum(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= '0' && c <= '9'
+}
@@ -0,0 +1,120 @@
+// Package s3 提供S3服务相关功能
+// 作者: KO
+// 创建时间: 2026-10-15
+// 修改时间: 2026-10-15
+package s3
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+var (
+	// ErrInvalidChecksum 校验和算法或客户端提供的校验和无效
+	ErrInvalidChecksum = errors.New("invalid checksum")
+	// ErrChecksumMismatch S3计算的校验和与客户端提供的不一致，上传被拒绝
+	ErrChecksumMismatch = errors.New("checksum mismatch")
+)
+
+// checksumSizes 各校验和算法的摘要长度（字节）
+var checksumSizes = map[types.ChecksumAlgorithm]int{
+	types.ChecksumAlgorithmCrc32:  4,
+	types.ChecksumAlgorithmCrc32c: 4,
+	types.ChecksumAlgorithmSha1:   20,
+	types.ChecksumAlgorithmSha256: 32,
+}
+
+// applyChecksum 为PutObject设置校验和算法和客户端预先计算的校验和
+// 只设置算法时由S3计算并保存校验和；同时提供校验和时S3会在内容不一致时拒绝上传
+// 参数:
+//
+//	input: PutObject请求
+//	algorithm: 校验和算法（CRC32、CRC32C、SHA1、SHA256，不区分大小写，为空时不设置）
+//	checksum: Base64编码的校验和（可为空）
+//
+// 返回值:
+//
+//	error: 参数无效时返回包装了ErrInvalidChecksum的错误
+func applyChecksum(input *s3.PutObjectInput, algorithm, checksum string) error {
+	if algorithm == "" {
+		if checksum != "" {
+			return fmt.Errorf("%w: checksum requires checksumAlgorithm", ErrInvalidChecksum)
+		}
+		return nil
+	}
+
+	algo := types.ChecksumAlgorithm(strings.ToUpper(algorithm))
+	size, ok := checksumSizes[algo]
+	if !ok {
+		return fmt.Errorf("%w: unknown checksumAlgorithm %q (supported: CRC32, CRC32C, SHA1, SHA256)", ErrInvalidChecksum, algorithm)
+	}
+	input.ChecksumAlgorithm = algo
+	if checksum == "" {
+		return nil
+	}
+
+	decoded, err := base64.StdEncoding.DecodeString(checksum)
+	if err != nil || len(decoded) != size {
+		return fmt.Errorf("%w: %s checksum must be %d bytes, base64-encoded", ErrInvalidChecksum, algo, size)
+	}
+	switch algo {
+	case types.ChecksumAlgorithmCrc32:
+		input.ChecksumCRC32 = aws.String(checksum)
+	case types.ChecksumAlgorithmCrc32c:
+		input.ChecksumCRC32C = aws.String(checksum)
+	case types.ChecksumAlgorithmSha1:
+		input.ChecksumSHA1 = aws.String(checksum)
+	case types.ChecksumAlgorithmSha256:
+		input.ChecksumSHA256 = aws.String(checksum)
+	}
+	return nil
+}
+
+// headChecksums 从HeadObject的响应中取出S3保存的校验和（需要请求时启用ChecksumMode）
+// 参数:
+//
+//	output: HeadObject响应
+//
+// 返回值:
+//
+//	map[string]string: 算法到Base64编码校验和的映射（没有时为nil）
+func headChecksums(output *s3.HeadObjectOutput) map[string]string {
+	var checksums map[string]string
+	for algo, value := range map[types.ChecksumAlgorithm]*string{
+		types.ChecksumAlgorithmCrc32:  output.ChecksumCRC32,
+		types.ChecksumAlgorithmCrc32c: output.ChecksumCRC32C,
+		types.ChecksumAlgorithmSha1:   output.ChecksumSHA1,
+		types.ChecksumAlgorithmSha256: output.ChecksumSHA256,
+	} {
+		if aws.ToString(value) == "" {
+			continue
+		}
+		if checksums == nil {
+			checksums = make(map[string]string)
+		}
+		checksums[string(algo)] = aws.ToString(value)
+	}
+	return checksums
+}
+
+// isChecksumMismatch 判断PutObject的错误是否为校验和不一致
+// 参数:
+//
+//	err: 错误
+//
+// 返回值:
+//
+//	bool: 是否为校验和不一致
+func isChecksumMismatch(err error) bool {
+	switch errorCode(err) {
+	case "BadDigest", "XAmzContentChecksumMismatch":
+		return true
+	}
+	return false
+}
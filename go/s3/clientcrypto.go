@@ -0,0 +1,411 @@
+// Package s3 提供S3服务相关功能
+// 作者: KO
+// 创建时间: 2026-10-15
+// 修改时间: 2026-10-15
+package s3
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// 客户端加密对象的格式（分块AES-GCM，可流式加解密）：
+//
+//	头部: 魔数"SCE1"(4字节) | 明文分块大小(4字节，大端) | 随机nonce前缀(8字节)
+//	分块: 密文 | 认证标签(16字节)，除最后一块外每块的明文都是分块大小
+//
+// 第i块的nonce为nonce前缀 | i(4字节，大端)，附加数据为头部 | 是否最后一块(1字节)，
+// 因此篡改、重排、截断或拼接分块都会导致认证失败。
+const (
+	clientCryptoMagic      = "SCE1"
+	clientCryptoHeaderSize = 16
+	clientCryptoTagSize    = 16
+	clientCryptoChunkSize  = 64 * 1024
+	clientCryptoMaxChunk   = 16 * 1024 * 1024
+)
+
+// MetaClientEncryption 标记客户端加密对象的元数据键，值为加密格式
+const MetaClientEncryption = "client-encryption"
+
+// clientEncryptionScheme 本服务写入的加密格式
+const clientEncryptionScheme = "aes-gcm-chunked-v1"
+
+var (
+	// ErrInvalidEncryptionKey 加密密钥无效（需要Base64编码的16、24或32字节AES密钥）
+	ErrInvalidEncryptionKey = errors.New("invalid encryption key")
+	// ErrNotClientEncrypted 对象不是客户端加密格式
+	ErrNotClientEncrypted = errors.New("object is not client-side encrypted")
+	// ErrDecryptionFailed 认证标签校验失败（密钥错误或数据被篡改、损坏）
+	ErrDecryptionFailed = errors.New("decryption failed: authentication tag mismatch (wrong key or tampered/corrupt data)")
+	// ErrEncryptionKeyRequired 对象是客户端加密的，下载时必须提供密钥
+	ErrEncryptionKeyRequired = errors.New("object is client-side encrypted; encryption key required")
+)
+
+// ParseEncryptionKey 解析请求中提供的Base64编码的AES密钥
+// 返回的错误不包含密钥内容，可以安全地记录或返回给客户端
+// 参数:
+//
+//	encoded: Base64编码的密钥
+//
+// 返回值:
+//
+//	[]byte: 密钥
+//	error: 错误信息，无效时包装ErrInvalidEncryptionKey
+func ParseEncryptionKey(encoded string) ([]byte, error) {
+	key, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("%w: not valid base64", ErrInvalidEncryptionKey)
+	}
+	switch len(key) {
+	case 16, 24, 32:
+		return key, nil
+	}
+	return nil, fmt.Errorf("%w: must be 16, 24 or 32 bytes, got %d", ErrInvalidEncryptionKey, len(key))
+}
+
+// newAEAD 使用密钥创建AES-GCM
+// 参数:
+//
+//	key: AES密钥
+//
+// 返回值:
+//
+//	cipher.AEAD: AES-GCM
+//	error: 错误信息
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidEncryptionKey, err)
+	}
+	return cipher.NewGCM(block)
+}
+
+// chunkAAD 返回分块认证时使用的附加数据
+// 参数:
+//
+//	header: 格式头部
+//	final: 是否最后一块
+//
+// 返回值:
+//
+//	[]byte: 附加数据
+func chunkAAD(header []byte, final bool) []byte {
+	aad := make([]byte, len(header)+1)
+	copy(aad, header)
+	if final {
+		aad[len(header)] = 1
+	}
+	return aad
+}
+
+// chunkNonce 返回第index块使用的nonce
+// 参数:
+//
+//	prefix: nonce前缀
+//	index: 分块序号
+//
+// 返回值:
+//
+//	[]byte: nonce
+func chunkNonce(prefix []byte, index uint32) []byte {
+	nonce := make([]byte, 12)
+	copy(nonce, prefix)
+	binary.BigEndian.PutUint32(nonce[8:], index)
+	return nonce
+}
+
+// encryptReader 按分块流式加密内容的读取器，读出的是完整的加密格式（头部和各分块）
+type encryptReader struct {
+	src    *bufio.Reader // 明文
+	aead   cipher.AEAD   // AES-GCM
+	header []byte        // 格式头部
+	index  uint32        // 下一块的序号
+	plain  []byte        // 明文分块缓冲区
+	sealed []byte        // 密文分块缓冲区
+	out    []byte        // 尚未读出的密文
+	done   bool          // 最后一块已加密
+	err    error         // 遇到的错误，之后的读取都返回该错误
+}
+
+// newEncryptReader 生成随机nonce前缀，返回加密读取器
+// 参数:
+//
+//	src: 明文
+//	key: AES密钥
+//
+// 返回值:
+//
+//	*encryptReader: 加密读取器
+//	error: 错误信息
+func newEncryptReader(src io.Reader, key []byte) (*encryptReader, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+
+	header := make([]byte, clientCryptoHeaderSize)
+	copy(header, clientCryptoMagic)
+	binary.BigEndian.PutUint32(header[4:8], clientCryptoChunkSize)
+	if _, err := rand.Read(header[8:]); err != nil {
+		return nil, err
+	}
+
+	return &encryptReader{
+		src:    bufio.NewReader(src),
+		aead:   aead,
+		header: header,
+		plain:  make([]byte, clientCryptoChunkSize),
+		sealed: make([]byte, 0, clientCryptoChunkSize+clientCryptoTagSize),
+		out:    header,
+	}, nil
+}
+
+// Read 实现io.Reader
+// 参数:
+//
+//	p: 读取缓冲区
+//
+// 返回值:
+//
+//	int: 读取的字节数
+//	error: 错误信息
+func (e *encryptReader) Read(p []byte) (int, error) {
+	for len(e.out) == 0 {
+		if e.err != nil {
+			return 0, e.err
+		}
+		if e.done {
+			return 0, io.EOF
+		}
+		e.err = e.nextChunk()
+	}
+
+	n := copy(p, e.out)
+	e.out = e.out[n:]
+	return n, nil
+}
+
+// nextChunk 读取并加密下一个分块
+// 读满一块后再预读一个字节判断是否为最后一块，空内容也会产生一个空的最后一块
+// 返回值:
+//
+//	error: 错误信息
+func (e *encryptReader) nextChunk() error {
+	n, err := io.ReadFull(e.src, e.plain)
+	final := false
+	switch {
+	case errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF):
+		final = true
+	case err != nil:
+		return err
+	default:
+		if _, err := e.src.Peek(1); errors.Is(err, io.EOF) {
+			final = true
+		} else if err != nil {
+			return err
+		}
+	}
+
+	e.out = e.aead.Seal(e.sealed[:0], chunkNonce(e.header[8:], e.index), e.plain[:n], chunkAAD(e.header, final))
+	e.index++
+	e.done = final
+	return nil
+}
+
+// encryptedSize 返回明文加密后的大小
+// 参数:
+//
+//	plainSize: 明文大小（字节）
+//
+// 返回值:
+//
+//	int64: 密文大小（字节）
+func encryptedSize(plainSize int64) int64 {
+	chunks := (plainSize + clientCryptoChunkSize - 1) / clientCryptoChunkSize
+	if chunks == 0 {
+		chunks = 1
+	}
+	return clientCryptoHeaderSize + plainSize + chunks*clientCryptoTagSize
+}
+
+// decryptReader 按分块流式解密客户端加密对象的读取器
+type decryptReader struct {
+	src       *bufio.Reader // 密文
+	aead      cipher.AEAD   // AES-GCM
+	header    []byte        // 格式头部
+	chunkSize int           // 明文分块大小
+	index     uint32        // 下一块的序号
+	buf       []byte        // 密文分块缓冲区
+	plain     []byte        // 当前分块中尚未读出的明文
+	done      bool          // 最后一块已解密
+	err       error         // 遇到的错误，之后的读取都返回该错误
+}
+
+// newDecryptReader 读取并校验格式头部，返回解密读取器
+// 参数:
+//
+//	src: 密文
+//	key: AES密钥
+//
+// 返回值:
+//
+//	*decryptReader: 解密读取器
+//	error: 错误信息
+func newDecryptReader(src io.Reader, key []byte) (*decryptReader, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+
+	r := bufio.NewReader(src)
+	header := make([]byte, clientCryptoHeaderSize)
+	if _, err := io.ReadFull(r, header); err != nil {
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			return nil, ErrNotClientEncrypted
+		}
+		return nil, err
+	}
+	if !bytes.Equal(header[:4], []byte(clientCryptoMagic)) {
+		return nil, ErrNotClientEncrypted
+	}
+	chunkSize := int(binary.BigEndian.Uint32(header[4:8]))
+	if chunkSize <= 0 || chunkSize > clientCryptoMaxChunk {
+		return nil, fmt.Errorf("%w: invalid chunk size %d", ErrNotClientEncrypted, chunkSize)
+	}
+
+	return &decryptReader{
+		src:       r,
+		aead:      aead,
+		header:    header,
+		chunkSize: chunkSize,
+		buf:       make([]byte, chunkSize+clientCryptoTagSize),
+	}, nil
+}
+
+// Read 实现io.Reader，每次解密并校验一个完整分块后才返回其中的明文
+// 参数:
+//
+//	p: 读取缓冲区
+//
+// 返回值:
+//
+//	int: 读取的字节数
+//	error: 错误信息，认证失败时为ErrDecryptionFailed
+func (d *decryptReader) Read(p []byte) (int, error) {
+	for len(d.plain) == 0 {
+		if d.err != nil {
+			return 0, d.err
+		}
+		if d.done {
+			return 0, io.EOF
+		}
+		d.err = d.nextChunk()
+	}
+
+	n := copy(p, d.plain)
+	d.plain = d.plain[n:]
+	return n, nil
+}
+
+// nextChunk 读取并解密下一个分块
+// 读满一块后再预读一个字节判断是否为最后一块
+// 返回值:
+//
+//	error: 错误信息
+func (d *decryptReader) nextChunk() error {
+	n, err := io.ReadFull(d.src, d.buf)
+	final := false
+	switch {
+	case errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF):
+		final = true
+	case err != nil:
+		return err
+	default:
+		if _, err := d.src.Peek(1); errors.Is(err, io.EOF) {
+			final = true
+		} else if err != nil {
+			return err
+		}
+	}
+
+	if n < clientCryptoTagSize {
+		return ErrDecryptionFailed
+	}
+	plain, err := d.aead.Open(d.buf[:0], chunkNonce(d.header[8:], d.index), d.buf[:n], chunkAAD(d.header, final))
+	if err != nil {
+		return ErrDecryptionFailed
+	}
+
+	d.index++
+	d.plain = plain
+	d.done = final
+	return nil
+}
+
+// decryptedBody 解密后的对象内容，关闭时同时关闭底层的密文流
+type decryptedBody struct {
+	io.Reader
+	body io.Closer
+}
+
+// Close 关闭底层的密文流
+// 返回值:
+//
+//	error: 错误信息
+func (b *decryptedBody) Close() error {
+	return b.body.Close()
+}
+
+// DownloadDecrypted 下载客户端加密的对象，并在读取时流式解密
+// 密文按分块读取，不会把整个对象缓存在内存中；每块在校验认证标签后才交给调用方，
+// 因此调用方读到ErrDecryptionFailed时，此前返回的明文都是真实的，但内容不完整。
+// 密钥只用于本次请求，不会被记录或保存
+// 参数:
+//
+//	ctx: 上下文
+//	bucket: 存储桶名称（为空时使用默认存储桶）
+//	key: 文件键
+//	versionID: 版本ID（为空时下载最新版本）
+//	encryptionKey: AES密钥
+//
+// 返回值:
+//
+//	io.ReadCloser: 明文内容，调用方负责关闭
+//	error: 错误信息，不是加密格式时包装ErrNotClientEncrypted
+func (s *Service) DownloadDecrypted(ctx context.Context, bucket, key, versionID string, encryptionKey []byte) (io.ReadCloser, error) {
+	if bucket == "" {
+		bucket = s.defaultBucket
+	}
+
+	var body io.ReadCloser
+	if versionID == "" {
+		var err error
+		body, _, err = s.DownloadRange(ctx, bucket, key, "")
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		output, err := s.getObjectVersion(ctx, bucket, key, versionID)
+		if err != nil {
+			return nil, err
+		}
+		body = output.Body
+	}
+
+	reader, err := newDecryptReader(body, encryptionKey)
+	if err != nil {
+		body.Close()
+		return nil, err
+	}
+
+	return &decryptedBody{Reader: reader, body: body}, nil
+}
@@ -0,0 +1,77 @@
+// Package s3 提供S3服务相关功能
+// 作者: KO
+// 创建时间: 2026-10-15
+// 修改时间: 2026-10-15
+package s3
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"sort"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// ContentHash 存储桶/前缀的内容摘要
+type ContentHash struct {
+	Bucket  string `json:"bucket"`  // 存储桶名称
+	Prefix  string `json:"prefix"`  // 键前缀
+	Objects int    `json:"objects"` // 参与计算的对象数
+	Digest  string `json:"digest"`  // SHA-256摘要（十六进制）
+}
+
+// ContentHash 计算前缀下全部对象的确定性摘要
+// 分页列举前缀下的对象，按键排序后对每个对象的键和ETag求SHA-256。
+// 前缀下没有任何变化时摘要保持不变；增加、删除或修改对象都会改变摘要。
+// 注意：只依赖ETag，重新上传相同内容但分段方式不同的对象也会改变摘要
+// 参数:
+//
+//	ctx: 上下文
+//	bucket: 存储桶名称（为空时使用默认存储桶）
+//	prefix: 键前缀（为空时计算整个存储桶）
+//
+// 返回值:
+//
+//	*ContentHash: 内容摘要
+//	error: 错误信息
+func (s *Service) ContentHash(ctx context.Context, bucket, prefix string) (*ContentHash, error) {
+	if bucket == "" {
+		bucket = s.defaultBucket
+	}
+
+	type entry struct {
+		key  string
+		etag string
+	}
+	var entries []entry
+	err := s.listAllObjects(ctx, bucket, prefix, func(obj types.Object) error {
+		entries = append(entries, entry{key: aws.ToString(obj.Key), etag: aws.ToString(obj.ETag)})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// ListObjectsV2已按键排序，这里再次排序以免依赖后端实现
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].key < entries[j].key
+	})
+
+	h := sha256.New()
+	for _, e := range entries {
+		// 以NUL和换行分隔，键和ETag都不会包含这两个字符
+		h.Write([]byte(e.key))
+		h.Write([]byte{0})
+		h.Write([]byte(e.etag))
+		h.Write([]byte{'\n'})
+	}
+
+	return &ContentHash{
+		Bucket:  bucket,
+		Prefix:  prefix,
+		Objects: len(entries),
+		Digest:  hex.EncodeToString(h.Sum(nil)),
+	}, nil
+}
@@ -0,0 +1,187 @@
+// Package s3 提供S3服务相关功能
+// 作者: KO
+// 创建时间: 2026-10-15
+// 修改时间: 2026-10-15
+package s3
+
+import (
+	"bufio"
+	"io"
+	"mime"
+	"net/http"
+	"path"
+	"strings"
+)
+
+// sniffLength http.DetectContentType最多检查的字节数
+const sniffLength = 512
+
+// UploadOptions 上传对象时的可选参数
+type UploadOptions struct {
+	ContentType string            // 客户端声明的内容类型（可能被按扩展名纠正，仍无法确定时按内容检测）
+	Metadata    map[string]string // 用户自定义元数据
+
+	// SkipIfUnchanged 已有对象的ETag与内容的MD5相同时跳过上传；比较的是上传转换之后实际存储的内容，
+	// 不能与EncryptionKey同时使用（密文每次都不同）
+	SkipIfUnchanged bool
+
+	// EncryptionKey 客户端加密密钥，非空时在存储前以分块AES-GCM流式加密内容，
+	// 并在元数据中标记加密格式；密钥只用于本次上传，服务不会保存
+	EncryptionKey []byte
+
+	// ChecksumAlgorithm 由S3计算并保存的校验和算法（CRC32、CRC32C、SHA1、SHA256）
+	ChecksumAlgorithm string
+	// Checksum 客户端预先计算的Base64编码校验和，与S3计算的不一致时上传被拒绝；
+	// 内容在存储前会被转换或加密时无法使用
+	Checksum string
+}
+
+// CorrectContentType 按配置的规则纠正客户端声明的内容类型
+// 声明的类型为空或属于不可信集合（如application/octet-stream）时，先按content_type_prefixes中
+// 与键匹配的最长前缀确定，再按文件扩展名推断：
+// 优先使用配置的扩展名映射，其次使用系统的MIME类型表；无法推断时保留声明的类型
+// 参数:
+//
+//	declared: 客户端声明的内容类型
+//	key: 文件键
+//
+// 返回值:
+//
+//	string: 纠正后的内容类型
+func (s *Service) CorrectContentType(declared, key string) string {
+	if declared != "" && !s.isUntrustedContentType(declared) {
+		return declared
+	}
+	if contentType := s.prefixContentType(key); contentType != "" {
+		return contentType
+	}
+
+	ext := strings.ToLower(strings.TrimPrefix(path.Ext(key), "."))
+	if ext == "" {
+		return declared
+	}
+	if contentType, ok := s.cfg.ContentTypeOverrides[ext]; ok {
+		return contentType
+	}
+	if contentType := mime.TypeByExtension("." + ext); contentType != "" {
+		return contentType
+	}
+
+	return declared
+}
+
+// prefixContentType 返回content_type_prefixes中与键匹配的最长前缀对应的内容类型
+// 配置的映射键会被转为小写，因此前缀按大小写不敏感的方式匹配
+// 参数:
+//
+//	key: 文件键
+//
+// 返回值:
+//
+//	string: 内容类型（没有匹配的前缀时为空）
+func (s *Service) prefixContentType(key string) string {
+	lowerKey := strings.ToLower(key)
+	longest, contentType := -1, ""
+	for prefix, value := range s.cfg.ContentTypePrefixes {
+		if len(prefix) > longest && strings.HasPrefix(lowerKey, strings.ToLower(prefix)) {
+			longest, contentType = len(prefix), value
+		}
+	}
+	return contentType
+}
+
+// sniffContentType 按内容的前512字节检测内容类型（http.DetectContentType）
+// 可Seek的body读取后回到起始位置并原样返回，以保持PutObject对可Seek body的优化；
+// 否则返回预读了前512字节的包装读取器
+// 参数:
+//
+//	body: 文件内容
+//
+// 返回值:
+//
+//	string: 检测到的内容类型（无法识别时为application/octet-stream）
+//	io.Reader: 用于后续读取完整内容的读取器
+//	error: 错误信息
+func sniffContentType(body io.Reader) (string, io.Reader, error) {
+	if seeker, ok := body.(io.ReadSeeker); ok {
+		head := make([]byte, sniffLength)
+		n, err := io.ReadFull(seeker, head)
+		if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+			return "", nil, err
+		}
+		if _, err := seeker.Seek(-int64(n), io.SeekCurrent); err != nil {
+			return "", nil, err
+		}
+		return http.DetectContentType(head[:n]), seeker, nil
+	}
+
+	buffered := bufio.NewReaderSize(body, sniffLength)
+	head, err := buffered.Peek(sniffLength)
+	if err != nil && err != io.EOF {
+		return "", nil, err
+	}
+	return http.DetectContentType(head), buffered, nil
+}
+
+// isUntrustedContentType 判断声明的内容类型是否属于不可信集合（忽略参数和大小写）
+// 参数:
+//
+//	declared: 客户端声明的内容类型
+//
+// 返回值:
+//
+//	bool: 是否不可信
+func (s *Service) isUntrustedContentType(declared string) bool {
+	mediaType, _, err := mime.ParseMediaType(declared)
+	if err != nil {
+		// 无法解析的类型同样不可信
+		return true
+	}
+
+	for _, untrusted := range s.cfg.UntrustedContentTypes {
+		if strings.EqualFold(mediaType, untrusted) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// IsRiskyContentType 判断内容类型是否可能被浏览器当作页面或脚本执行（如text/html、image/svg+xml）
+// 参数:
+//
+//	contentType: 内容类型
+//
+// 返回值:
+//
+//	bool: 是否为高风险类型
+func (s *Service) IsRiskyContentType(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+
+	for _, risky := range s.cfg.RiskyContentTypes {
+		if strings.EqualFold(mediaType, risky) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// DownloadContentType 返回下载响应应使用的内容类型
+// 启用force_download和force_download_override时，高风险类型改为application/octet-stream
+// 参数:
+//
+//	contentType: 对象的内容类型
+//
+// 返回值:
+//
+//	string: 响应使用的内容类型
+func (s *Service) DownloadContentType(contentType string) string {
+	if s.cfg.ForceDownload && s.cfg.ForceDownloadOverride && s.IsRiskyContentType(contentType) {
+		return "application/octet-stream"
+	}
+	return contentType
+}
@@ -0,0 +1,69 @@
+// Package s3 提供S3服务相关功能
+// 作者: KO
+// 创建时间: 2026-10-15
+// 修改时间: 2026-10-15
+package s3
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/credentials/stscreds"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/example/s3service/config"
+)
+
+// defaultRoleSessionName 未配置role_session_name时使用的会话名称
+const defaultRoleSessionName = "s3service"
+
+// loadAWSConfig 按配置加载AWS配置和凭证
+// 配置了access_key_id且未启用use_iam_role时使用静态凭证，否则使用SDK默认凭证链
+// （环境变量、共享配置文件、EKS的IRSA、EC2实例角色等）；
+// 配置了assume_role_arn时再以上述凭证通过STS AssumeRole获取临时凭证，
+// 临时凭证由凭证缓存在过期前自动刷新
+// 参数:
+//
+//	ctx: 上下文
+//	cfg: S3配置信息
+//
+// 返回值:
+//
+//	aws.Config: AWS配置
+//	error: 错误信息
+func loadAWSConfig(ctx context.Context, cfg *config.S3Config) (aws.Config, error) {
+	opts := []func(*awsconfig.LoadOptions) error{
+		awsconfig.WithRegion(cfg.Region),
+		awsconfig.WithHTTPClient(newHTTPClient(cfg)),
+	}
+	opts = append(opts, retryOptions(cfg)...)
+	if cfg.AccessKeyID != "" && !cfg.UseIAMRole {
+		opts = append(opts, awsconfig.WithCredentialsProvider(credentials.NewStaticCredentialsProvider(
+			cfg.AccessKeyID,
+			cfg.SecretAccessKey,
+			"",
+		)))
+	}
+
+	awsCfg, err := awsconfig.LoadDefaultConfig(ctx, opts...)
+	if err != nil {
+		return aws.Config{}, err
+	}
+
+	if cfg.AssumeRoleArn != "" {
+		sessionName := cfg.RoleSessionName
+		if sessionName == "" {
+			sessionName = defaultRoleSessionName
+		}
+		provider := stscreds.NewAssumeRoleProvider(sts.NewFromConfig(awsCfg), cfg.AssumeRoleArn, func(o *stscreds.AssumeRoleOptions) {
+			o.RoleSessionName = sessionName
+			if cfg.ExternalID != "" {
+				o.ExternalID = aws.String(cfg.ExternalID)
+			}
+		})
+		awsCfg.Credentials = aws.NewCredentialsCache(provider)
+	}
+
+	return awsCfg, nil
+}
@@ -0,0 +1,136 @@
+// Package s3 提供S3服务相关功能
+// 作者: KO
+// 创建时间: 2026-10-15
+// 修改时间: 2026-10-15
+package s3
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// ErrBucketNotEmpty 存储桶中仍有对象，无法删除
+var ErrBucketNotEmpty = errors.New("bucket is not empty")
+
+// DeleteBucket 删除存储桶
+// force为false时存储桶必须为空；force为true时先以BatchDelete删除全部对象，
+// 再删除剩余的历史版本和删除标记（启用过版本控制的存储桶），最后删除存储桶。
+// 强制删除不可恢复，且不是原子操作：中途失败时已删除的对象不会恢复
+// 参数:
+//
+//	ctx: 上下文
+//	bucket: 存储桶名称
+//	force: 是否先删除存储桶中的全部对象
+//
+// 返回值:
+//
+//	error: 存储桶非空（或强制删除时有对象删除失败）时返回包装了ErrBucketNotEmpty的错误，
+//	       存储桶不存在时可用IsNotFound判断
+func (s *Service) DeleteBucket(ctx context.Context, bucket string, force bool) error {
+	if force {
+		if err := s.emptyBucket(ctx, bucket); err != nil {
+			return err
+		}
+	}
+
+	_, err := s.client.DeleteBucket(ctx, &s3.DeleteBucketInput{
+		Bucket: aws.String(bucket),
+	})
+	if errorCode(err) == "BucketNotEmpty" {
+		return fmt.Errorf("%w: %s (use force to delete its objects first)", ErrBucketNotEmpty, bucket)
+	}
+	return err
+}
+
+// emptyBucket 删除存储桶中的全部对象、历史版本和删除标记
+// 参数:
+//
+//	ctx: 上下文
+//	bucket: 存储桶名称
+//
+// 返回值:
+//
+//	error: 有对象删除失败时返回包装了ErrBucketNotEmpty的错误
+func (s *Service) emptyBucket(ctx context.Context, bucket string) error {
+	var keys []string
+	err := s.listAllObjects(ctx, bucket, "", func(obj types.Object) error {
+		keys = append(keys, aws.ToString(obj.Key))
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if len(keys) > 0 {
+		result, err := s.BatchDelete(ctx, bucket, keys, nil)
+		if err != nil {
+			return err
+		}
+		if len(result.Errors) > 0 {
+			first := result.Errors[0]
+			return fmt.Errorf("%w: %d objects could not be deleted (first: %s: %s %s)",
+				ErrBucketNotEmpty, len(result.Errors), first.Key, first.Code, first.Message)
+		}
+	}
+
+	return s.deleteAllVersions(ctx, bucket)
+}
+
+// deleteAllVersions 按版本ID删除存储桶中剩余的全部版本和删除标记
+// 未启用过版本控制或后端不支持版本列举时没有需要删除的内容
+// 参数:
+//
+//	ctx: 上下文
+//	bucket: 存储桶名称
+//
+// 返回值:
+//
+//	error: 有版本删除失败时返回包装了ErrBucketNotEmpty的错误
+func (s *Service) deleteAllVersions(ctx context.Context, bucket string) error {
+	var objects []types.ObjectIdentifier
+	input := &s3.ListObjectVersionsInput{Bucket: aws.String(bucket)}
+	for {
+		output, err := s.client.ListObjectVersions(ctx, input)
+		if isNotImplemented(err) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		for _, v := range output.Versions {
+			objects = append(objects, types.ObjectIdentifier{Key: v.Key, VersionId: v.VersionId})
+		}
+		for _, m := range output.DeleteMarkers {
+			objects = append(objects, types.ObjectIdentifier{Key: m.Key, VersionId: m.VersionId})
+		}
+		if !aws.ToBool(output.IsTruncated) {
+			break
+		}
+		input.KeyMarker = output.NextKeyMarker
+		input.VersionIdMarker = output.NextVersionIdMarker
+	}
+
+	for start := 0; start < len(objects); start += maxDeleteObjects {
+		end := start + maxDeleteObjects
+		if end > len(objects) {
+			end = len(objects)
+		}
+		output, err := s.client.DeleteObjects(ctx, &s3.DeleteObjectsInput{
+			Bucket: aws.String(bucket),
+			Delete: &types.Delete{Objects: objects[start:end], Quiet: aws.Bool(true)},
+		})
+		if err != nil {
+			return err
+		}
+		if len(output.Errors) > 0 {
+			first := output.Errors[0]
+			return fmt.Errorf("%w: %d object versions could not be deleted (first: %s: %s %s)",
+				ErrBucketNotEmpty, len(output.Errors), aws.ToString(first.Key), aws.ToString(first.Code), aws.ToString(first.Message))
+		}
+	}
+	return nil
+}
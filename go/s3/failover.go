@@ -0,0 +1,107 @@
+// Package s3 提供S3服务相关功能
+// 作者: KO
+// 创建时间: 2026-10-15
+// 修改时间: 2026-10-15
+package s3
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/smithy-go/middleware"
+	smithyhttp "github.com/aws/smithy-go/transport/http"
+	"github.com/example/s3service/config"
+	"github.com/example/s3service/metrics"
+)
+
+// newFailoverClient 创建读取故障转移使用的备用S3客户端
+// 备用端点使用与主端点相同的存储桶名称和键（如副本集群），
+// 未单独配置failover_access_key_id时使用与主端点相同的凭证
+// 参数:
+//
+//	awsCfg: AWS配置
+//	cfg: S3配置信息
+//	apiOptions: 备用客户端使用的SDK中间件
+//
+// 返回值:
+//
+//	*s3.Client: 备用S3客户端
+func newFailoverClient(awsCfg aws.Config, cfg *config.S3Config, apiOptions ...func(*middleware.Stack) error) *s3.Client {
+	return s3.NewFromConfig(awsCfg, func(o *s3.Options) {
+		o.BaseEndpoint = aws.String(cfg.FailoverEndpoint)
+		o.UsePathStyle = cfg.UsePathStyle
+		o.APIOptions = append(o.APIOptions, apiOptions...)
+		if cfg.FailoverAccessKeyID != "" {
+			o.Credentials = credentials.NewStaticCredentialsProvider(cfg.FailoverAccessKeyID, cfg.FailoverSecretAccessKey, "")
+		}
+	})
+}
+
+// readFailover 返回读取故障转移的SDK中间件
+// 只作用于幂等的读取操作（GetObject、HeadObject）：主端点在重试耗尽后仍因连接错误或5xx失败时，
+// 以相同的输入向备用端点重新发起请求；404等明确的响应不转移。预签名请求的中间件栈中没有Retry中间件，此时不挂载
+// 参数:
+//
+//	secondary: 备用S3客户端
+//
+// 返回值:
+//
+//	func(*middleware.Stack) error: 用于s3.Options.APIOptions的中间件注册函数
+func readFailover(secondary *s3.Client) func(*middleware.Stack) error {
+	return func(stack *middleware.Stack) error {
+		if _, ok := stack.Finalize.Get("Retry"); !ok {
+			return nil
+		}
+
+		// 位于外层时SDK尚未在上下文中记录操作名，取中间件栈的ID（即操作名）
+		operation := stack.ID()
+		return stack.Initialize.Add(middleware.InitializeMiddlewareFunc("ReadFailover",
+			func(ctx context.Context, in middleware.InitializeInput, next middleware.InitializeHandler) (middleware.InitializeOutput, middleware.Metadata, error) {
+				out, metadata, err := next.HandleInitialize(ctx, in)
+				if err == nil || ctx.Err() != nil || !isConnectivityError(err) {
+					return out, metadata, err
+				}
+
+				var result interface{}
+				var failoverErr error
+				switch input := in.Parameters.(type) {
+				case *s3.GetObjectInput:
+					result, failoverErr = secondary.GetObject(ctx, input)
+				case *s3.HeadObjectInput:
+					result, failoverErr = secondary.HeadObject(ctx, input)
+				default:
+					return out, metadata, err
+				}
+
+				metrics.ReadFailoversTotal.WithLabelValues(operation).Inc()
+				// 以备用端点的错误为准（如对象尚未复制时的404），主端点的错误只作为说明
+				if failoverErr != nil {
+					return out, metadata, fmt.Errorf("%w (primary endpoint failed: %v)", failoverErr, err)
+				}
+				return middleware.InitializeOutput{Result: result}, metadata, nil
+			}), middleware.Before)
+	}
+}
+
+// isConnectivityError 判断错误是否表示端点不可用
+// 请求未能发出或未收到响应（连接被拒绝、DNS解析失败、超时等），或者端点返回5xx
+// 参数:
+//
+//	err: 错误
+//
+// 返回值:
+//
+//	bool: 是否为端点不可用错误
+func isConnectivityError(err error) bool {
+	var sendErr *smithyhttp.RequestSendError
+	if errors.As(err, &sendErr) {
+		return true
+	}
+	var respErr *smithyhttp.ResponseError
+	return errors.As(err, &respErr) && respErr.HTTPStatusCode() >= http.StatusInternalServerError
+}
@@ -0,0 +1,88 @@
+// Package s3 提供S3服务相关功能
+// 作者: KO
+// 创建时间: 2026-10-15
+// 修改时间: 2026-10-15
+package s3
+
+import (
+	"fmt"
+	"strings"
+)
+
+// backendQuirks 不同S3兼容后端之间的行为差异
+// 所有按后端区分的代码路径都通过这里的开关选择，而不是在各处匹配错误字符串
+type backendQuirks struct {
+	// ignoresLocationConstraint 后端忽略创建存储桶时的LocationConstraint（如MinIO），
+	// 存储桶总是位于服务端的区域，无需校验请求的区域
+	ignoresLocationConstraint bool
+	// ownershipControls 后端支持对象所有权控制；不支持时直接拒绝带objectOwnership的请求
+	ownershipControls bool
+	// objectAttributes 后端支持GetObjectAttributes（完整性校验依赖它读取存储的校验和）
+	objectAttributes bool
+	// looseNotFound 后端的404错误可能既没有错误码也没有规范的状态，需要回退到匹配错误消息
+	looseNotFound bool
+}
+
+// backendFlavors 各后端类型的行为差异
+var backendFlavors = map[string]backendQuirks{
+	"aws": {
+		ownershipControls: true,
+		objectAttributes:  true,
+	},
+	"minio": {
+		ignoresLocationConstraint: true,
+		objectAttributes:          true,
+	},
+	// Ceph RGW较早的版本不支持GetObjectAttributes和对象所有权控制；
+	// 所有权控制仍照常请求，由CreateBucket把NotImplemented映射为ErrOwnershipUnsupported
+	"ceph": {
+		ownershipControls: true,
+	},
+	// generic对未知的兼容实现尽量宽容：功能照常尝试，404按错误消息兜底识别
+	"generic": {
+		ownershipControls: true,
+		objectAttributes:  true,
+		looseNotFound:     true,
+	},
+}
+
+// quirksFor 返回后端类型对应的行为差异
+// 参数:
+//
+//	flavor: 后端类型（为空时按generic处理）
+//
+// 返回值:
+//
+//	backendQuirks: 行为差异
+//	error: 未知的后端类型时返回错误
+func quirksFor(flavor string) (backendQuirks, error) {
+	if flavor == "" {
+		flavor = "generic"
+	}
+	quirks, ok := backendFlavors[flavor]
+	if !ok {
+		return backendQuirks{}, fmt.Errorf("unknown backend_flavor %q (supported: aws, minio, ceph, generic)", flavor)
+	}
+	return quirks, nil
+}
+
+// isBucketNotFound 判断HeadBucket的错误是否表示存储桶不存在
+// HeadBucket的响应没有消息体，AWS和MinIO都只能通过404状态识别；
+// 对于looseNotFound的后端，再回退到匹配错误消息中的"NotFound"或"StatusCode: 404"
+// 参数:
+//
+//	err: HeadBucket返回的错误
+//
+// 返回值:
+//
+//	bool: 是否为存储桶不存在
+func (q backendQuirks) isBucketNotFound(err error) bool {
+	if IsNotFound(err) || errorCode(err) == "NoSuchBucket" {
+		return true
+	}
+	if q.looseNotFound {
+		errMsg := err.Error()
+		return strings.Contains(errMsg, "NotFound") || strings.Contains(errMsg, "StatusCode: 404")
+	}
+	return false
+}
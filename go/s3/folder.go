@@ -0,0 +1,120 @@
+// Package s3 提供S3服务相关功能
+// 作者: KO
+// 创建时间: 2026-10-15
+// 修改时间: 2026-10-15
+package s3
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// ErrEmptyPrefix 删除前缀时未指定前缀（不允许清空整个存储桶）
+var ErrEmptyPrefix = errors.New("prefix must not be empty")
+
+// isFolderMarker 判断对象是否为文件夹标记（以/结尾的0字节对象）
+// S3控制台和许多文件管理器以这种对象表示空文件夹，列举文件时应将其视为文件夹而不是文件
+// 参数:
+//
+//	obj: S3对象
+//
+// 返回值:
+//
+//	bool: 是否为文件夹标记
+func isFolderMarker(obj types.Object) bool {
+	return strings.HasSuffix(aws.ToString(obj.Key), "/") && aws.ToInt64(obj.Size) == 0
+}
+
+// folderKey 返回前缀对应的文件夹标记键（确保以/结尾）
+// 参数:
+//
+//	prefix: 文件夹前缀
+//
+// 返回值:
+//
+//	string: 文件夹标记键
+func folderKey(prefix string) string {
+	if strings.HasSuffix(prefix, "/") {
+		return prefix
+	}
+	return prefix + "/"
+}
+
+// CreateFolder 创建文件夹标记，使空文件夹在列举时以公共前缀的形式显示
+// 参数:
+//
+//	ctx: 上下文
+//	bucket: 存储桶名称（为空时使用默认存储桶）
+//	prefix: 文件夹前缀（不以/结尾时自动补上）
+//
+// 返回值:
+//
+//	string: 文件夹标记键
+//	error: 错误信息
+func (s *Service) CreateFolder(ctx context.Context, bucket, prefix string) (string, error) {
+	if bucket == "" {
+		bucket = s.defaultBucket
+	}
+	if strings.Trim(prefix, "/") == "" {
+		return "", ErrEmptyPrefix
+	}
+
+	key := folderKey(prefix)
+	_, err := s.client.PutObject(ctx, &s3.PutObjectInput{
+		Bucket:        aws.String(bucket),
+		Key:           aws.String(key),
+		Body:          bytes.NewReader(nil),
+		ContentLength: aws.Int64(0),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return key, nil
+}
+
+// DeletePrefix 删除前缀下的全部对象
+// prefix为文件夹（以/结尾）时，其文件夹标记默认保留，使清空后的文件夹仍然存在；
+// removeMarker为true时一并删除。前缀下更深层的文件夹标记总是被删除
+// 参数:
+//
+//	ctx: 上下文
+//	bucket: 存储桶名称（为空时使用默认存储桶）
+//	prefix: 键前缀（不能为空）
+//	removeMarker: 是否删除prefix本身的文件夹标记
+//
+// 返回值:
+//
+//	*DeleteResult: 删除结果
+//	error: 错误信息
+func (s *Service) DeletePrefix(ctx context.Context, bucket, prefix string, removeMarker bool) (*DeleteResult, error) {
+	if bucket == "" {
+		bucket = s.defaultBucket
+	}
+	if prefix == "" {
+		return nil, ErrEmptyPrefix
+	}
+
+	var keys []string
+	err := s.listAllObjects(ctx, bucket, prefix, func(obj types.Object) error {
+		if !removeMarker && aws.ToString(obj.Key) == prefix && isFolderMarker(obj) {
+			return nil
+		}
+		keys = append(keys, aws.ToString(obj.Key))
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(keys) == 0 {
+		return &DeleteResult{Deleted: []string{}, Errors: []DeleteError{}}, nil
+	}
+
+	return s.BatchDelete(ctx, bucket, keys, nil)
+}
@@ -0,0 +1,292 @@
+// Package s3 提供S3服务相关功能
+// 作者: KO
+// 创建时间: 2026-10-15
+// 修改时间: 2026-10-15
+package s3
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"reflect"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/aws/smithy-go/middleware"
+)
+
+// maxHashPrefixBytes hash_prefix_bytes允许的最大值
+const maxHashPrefixBytes = 8
+
+// keyHasher 在存储的键前加上键的哈希前缀，将顺序写入分散到不同的S3分区
+// 存储的键为hex(SHA256(key))[:2n] + "/" + key，如n为1时a/b.txt存储为55/a/b.txt
+type keyHasher struct {
+	bytes int // 哈希前缀的字节数（0表示不加前缀）
+}
+
+// newKeyHasher 创建键哈希前缀处理器
+// 参数:
+//
+//	bytes: 哈希前缀的字节数（0表示不加前缀）
+//
+// 返回值:
+//
+//	keyHasher: 键哈希前缀处理器
+//	error: bytes超出范围时的错误信息
+func newKeyHasher(bytes int) (keyHasher, error) {
+	if bytes < 0 || bytes > maxHashPrefixBytes {
+		return keyHasher{}, fmt.Errorf("hash_prefix_bytes must be between 0 and %d: %d", maxHashPrefixBytes, bytes)
+	}
+	return keyHasher{bytes: bytes}, nil
+}
+
+// enabled 返回是否启用了哈希前缀
+// 返回值:
+//
+//	bool: 是否启用
+func (h keyHasher) enabled() bool {
+	return h.bytes > 0
+}
+
+// prefix 返回键的哈希前缀（不含分隔符）
+// 参数:
+//
+//	key: 逻辑键
+//
+// 返回值:
+//
+//	string: 哈希前缀
+func (h keyHasher) prefix(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:h.bytes])
+}
+
+// stored 返回逻辑键在S3中实际存储的键
+// 参数:
+//
+//	key: 逻辑键
+//
+// 返回值:
+//
+//	string: 存储的键（未启用时原样返回）
+func (h keyHasher) stored(key string) string {
+	if !h.enabled() || key == "" {
+		return key
+	}
+	return h.prefix(key) + "/" + key
+}
+
+// logical 去掉存储的键上的哈希前缀
+// 只有前缀与其余部分的哈希一致时才去掉，启用前写入的对象原样返回
+// 参数:
+//
+//	stored: 存储的键
+//
+// 返回值:
+//
+//	string: 逻辑键
+func (h keyHasher) logical(stored string) string {
+	n := 2 * h.bytes
+	if !h.enabled() || len(stored) <= n || stored[n] != '/' {
+		return stored
+	}
+	key := stored[n+1:]
+	if stored[:n] != h.prefix(key) {
+		return stored
+	}
+	return key
+}
+
+// hashKeyPrefix 返回在所有S3调用上透明处理键哈希前缀的SDK中间件
+// 写入时为输入中的Key、CopySource、批量删除的键和列举游标加上哈希前缀，读取时去掉输出中键的哈希前缀。
+// 哈希前缀打乱了键的顺序，列举时无法再按逻辑前缀让S3过滤，因此带Prefix的列举会扫描
+// 整个存储桶并在本地过滤（每页返回的对象可能少于MaxKeys，续传令牌仍然有效）
+// 参数:
+//
+//	h: 键哈希前缀处理器
+//
+// 返回值:
+//
+//	func(*middleware.Stack) error: 用于s3.Options.APIOptions的中间件注册函数
+func hashKeyPrefix(h keyHasher) func(*middleware.Stack) error {
+	return func(stack *middleware.Stack) error {
+		return stack.Initialize.Add(middleware.InitializeMiddlewareFunc("HashKeyPrefix",
+			func(ctx context.Context, in middleware.InitializeInput, next middleware.InitializeHandler) (middleware.InitializeOutput, middleware.Metadata, error) {
+				var prefix string
+				in.Parameters, prefix = h.rewriteInput(in.Parameters)
+
+				out, metadata, err := next.HandleInitialize(ctx, in)
+				if err != nil {
+					return out, metadata, err
+				}
+				if last := h.rewriteOutput(out.Result, prefix); last != "" {
+					metadata.Set(lastListedKey{}, last)
+				}
+				return out, metadata, err
+			}), middleware.After)
+	}
+}
+
+// rewriteInput 复制输入参数并为其中的键加上哈希前缀
+// 调用方的输入结构体可能被复用（如分页器），因此不直接修改原参数
+// 参数:
+//
+//	params: 操作输入参数
+//
+// 返回值:
+//
+//	interface{}: 改写后的输入参数
+//	string: 列举时被移出请求、需要在本地过滤的逻辑前缀
+func (h keyHasher) rewriteInput(params interface{}) (interface{}, string) {
+	switch input := params.(type) {
+	case *s3.ListObjectsV2Input:
+		cp := *input
+		prefix := aws.ToString(cp.Prefix)
+		cp.Prefix = nil
+		if cp.StartAfter != nil {
+			cp.StartAfter = aws.String(h.stored(*cp.StartAfter))
+		}
+		return &cp, prefix
+	case *s3.ListObjectVersionsInput:
+		cp := *input
+		prefix := aws.ToString(cp.Prefix)
+		cp.Prefix = nil
+		if cp.KeyMarker != nil {
+			cp.KeyMarker = aws.String(h.stored(*cp.KeyMarker))
+		}
+		return &cp, prefix
+	case *s3.DeleteObjectsInput:
+		cp := *input
+		if cp.Delete != nil {
+			del := *cp.Delete
+			del.Objects = make([]types.ObjectIdentifier, len(input.Delete.Objects))
+			for i, obj := range input.Delete.Objects {
+				obj.Key = aws.String(h.stored(aws.ToString(obj.Key)))
+				del.Objects[i] = obj
+			}
+			cp.Delete = &del
+		}
+		return &cp, ""
+	case *s3.CopyObjectInput:
+		cp := *input
+		cp.Key = aws.String(h.stored(aws.ToString(cp.Key)))
+		cp.CopySource = aws.String(h.storedCopySource(aws.ToString(cp.CopySource)))
+		return &cp, ""
+	}
+
+	v := reflect.ValueOf(params)
+	if v.Kind() != reflect.Ptr || v.Elem().Kind() != reflect.Struct {
+		return params, ""
+	}
+	field := v.Elem().FieldByName("Key")
+	if !field.IsValid() || field.Type() != reflect.TypeOf((*string)(nil)) || field.IsNil() {
+		return params, ""
+	}
+
+	cp := reflect.New(v.Elem().Type())
+	cp.Elem().Set(v.Elem())
+	cp.Elem().FieldByName("Key").Set(reflect.ValueOf(aws.String(h.stored(field.Elem().String()))))
+	return cp.Interface(), ""
+}
+
+// storedCopySource 为CopySource（bucket/URL编码的键）中的键加上哈希前缀
+// 参数:
+//
+//	source: CopySource值
+//
+// 返回值:
+//
+//	string: 改写后的CopySource
+func (h keyHasher) storedCopySource(source string) string {
+	bucket, escaped, ok := strings.Cut(source, "/")
+	if !ok {
+		return source
+	}
+	key, err := url.PathUnescape(escaped)
+	if err != nil {
+		return source
+	}
+	return copySource(bucket, h.stored(key))
+}
+
+// lastListedKey 结果元数据中保存本页最后一个被列举（过滤前）对象的逻辑键的键
+// 本地过滤可能去掉页面末尾的对象，游标分页需要据此确定下一页的起点
+type lastListedKey struct{}
+
+// rewriteOutput 去掉输出中键的哈希前缀，列举时按逻辑前缀过滤
+// 参数:
+//
+//	result: 操作输出
+//	prefix: 列举的逻辑前缀
+//
+// 返回值:
+//
+//	string: ListObjectsV2过滤前最后一个对象的逻辑键（其他操作为空）
+func (h keyHasher) rewriteOutput(result interface{}, prefix string) string {
+	switch output := result.(type) {
+	case *s3.ListObjectsV2Output:
+		var last string
+		if len(output.Contents) > 0 {
+			last = h.logical(aws.ToString(output.Contents[len(output.Contents)-1].Key))
+		}
+		contents := output.Contents[:0]
+		for _, obj := range output.Contents {
+			key := h.logical(aws.ToString(obj.Key))
+			if strings.HasPrefix(key, prefix) {
+				obj.Key = aws.String(key)
+				contents = append(contents, obj)
+			}
+		}
+		output.Contents = contents
+		output.KeyCount = aws.Int32(int32(len(contents)))
+		if prefix != "" {
+			output.Prefix = aws.String(prefix)
+		}
+		return last
+	case *s3.DeleteObjectsOutput:
+		for i := range output.Deleted {
+			output.Deleted[i].Key = aws.String(h.logical(aws.ToString(output.Deleted[i].Key)))
+		}
+		for i := range output.Errors {
+			output.Errors[i].Key = aws.String(h.logical(aws.ToString(output.Errors[i].Key)))
+		}
+		return ""
+	case *s3.ListObjectVersionsOutput:
+		versions := output.Versions[:0]
+		for _, version := range output.Versions {
+			key := h.logical(aws.ToString(version.Key))
+			if strings.HasPrefix(key, prefix) {
+				version.Key = aws.String(key)
+				versions = append(versions, version)
+			}
+		}
+		output.Versions = versions
+		markers := make([]types.DeleteMarkerEntry, 0, len(output.DeleteMarkers))
+		for _, marker := range output.DeleteMarkers {
+			key := h.logical(aws.ToString(marker.Key))
+			if strings.HasPrefix(key, prefix) {
+				marker.Key = aws.String(key)
+				markers = append(markers, marker)
+			}
+		}
+		output.DeleteMarkers = markers
+		if output.NextKeyMarker != nil {
+			output.NextKeyMarker = aws.String(h.logical(*output.NextKeyMarker))
+		}
+		return ""
+	}
+
+	v := reflect.ValueOf(result)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return ""
+	}
+	field := v.Elem().FieldByName("Key")
+	if field.IsValid() && field.Type() == reflect.TypeOf((*string)(nil)) && !field.IsNil() && field.CanSet() {
+		field.Set(reflect.ValueOf(aws.String(h.logical(field.Elem().String()))))
+	}
+	return ""
+}
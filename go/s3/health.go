@@ -0,0 +1,58 @@
+// Package s3 提供S3服务相关功能
+// 作者: KO
+// 创建时间: 2026-10-15
+// 修改时间: 2026-10-15
+package s3
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// HealthStatus 深度健康检查的结果
+type HealthStatus struct {
+	Healthy   bool      `json:"healthy"`         // S3是否可用
+	Error     string    `json:"error,omitempty"` // 不可用时的错误信息
+	CheckedAt time.Time `json:"checkedAt"`       // 实际访问S3的时间
+	Cached    bool      `json:"cached"`          // 是否复用了缓存的结果
+}
+
+// healthCache 最近一次深度健康检查的结果
+// 检查期间持有锁，并发的探测会等待同一次检查完成，而不是各自访问S3
+type healthCache struct {
+	mu     sync.Mutex
+	status HealthStatus
+}
+
+// CheckHealth 深度健康检查：通过HeadBucket确认能够访问默认存储桶
+// 距上次检查不超过health_cache_ttl时直接返回缓存的结果，避免频繁探测产生大量S3请求
+// 参数:
+//
+//	ctx: 上下文
+//
+// 返回值:
+//
+//	HealthStatus: 检查结果
+func (s *Service) CheckHealth(ctx context.Context) HealthStatus {
+	s.health.mu.Lock()
+	defer s.health.mu.Unlock()
+
+	if !s.health.status.CheckedAt.IsZero() && time.Since(s.health.status.CheckedAt) < s.cfg.HealthCacheTTL {
+		status := s.health.status
+		status.Cached = true
+		return status
+	}
+
+	status := HealthStatus{Healthy: true, CheckedAt: time.Now()}
+	if err := s.CheckReady(ctx); err != nil {
+		status.Healthy = false
+		status.Error = err.Error()
+	}
+	// 请求被取消导致的失败不代表S3不可用，不缓存
+	if ctx.Err() == nil {
+		s.health.status = status
+	}
+
+	return status
+}
@@ -0,0 +1,217 @@
+// Package s3 提供S3服务相关功能
+// 作者: KO
+// 创建时间: 2026-10-15
+// 修改时间: 2026-10-15
+package s3
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/aws/smithy-go/middleware"
+)
+
+// ErrObjectImmutable 对象仍处于上传后的不可变窗口内，拒绝覆盖或删除
+var ErrObjectImmutable = errors.New("object is immutable")
+
+// immutableErrorCode 批量删除结果中表示对象不可变的错误码
+const immutableErrorCode = "ObjectImmutable"
+
+// ImmutableError 对象处于不可变窗口内的错误，包含解锁时间
+type ImmutableError struct {
+	Key      string    // 文件键
+	UnlockAt time.Time // 不可变窗口结束的时间
+}
+
+// Error 返回错误信息
+// 返回值:
+//
+//	string: 错误信息
+func (e *ImmutableError) Error() string {
+	return fmt.Sprintf("%s: %s cannot be overwritten or deleted until %s", ErrObjectImmutable, e.Key, e.UnlockAt.UTC().Format(time.RFC3339))
+}
+
+// Is 使errors.Is(err, ErrObjectImmutable)成立
+// 参数:
+//
+//	target: 目标错误
+//
+// 返回值:
+//
+//	bool: 是否匹配
+func (e *ImmutableError) Is(target error) bool {
+	return target == ErrObjectImmutable
+}
+
+// immutabilityBypassKey 上下文中跳过不可变检查的标记键
+type immutabilityBypassKey struct{}
+
+// withImmutabilityBypass 返回跳过不可变检查的上下文
+// 仅用于服务自身必须完成的清理（如移动失败后删除刚复制的目标对象）
+// 参数:
+//
+//	ctx: 上下文
+//
+// 返回值:
+//
+//	context.Context: 带有跳过标记的上下文
+func withImmutabilityBypass(ctx context.Context) context.Context {
+	return context.WithValue(ctx, immutabilityBypassKey{}, true)
+}
+
+// immutabilityGuard 上传后不可变窗口的检查器
+// 这是服务内的建议性规则：只对经过本服务的写入和删除生效，直接访问S3（包括预签名URL）不受限制；
+// 需要真正的WORM保证时应使用存储桶的对象锁定
+type immutabilityGuard struct {
+	window       time.Duration // 对象最后修改后不可覆盖或删除的时长
+	exemptPrefix string        // 不受限制的键前缀（暂存对象）
+	client       *s3.Client    // 用于HEAD对象的客户端（客户端创建后设置）
+}
+
+// check 检查对象是否处于不可变窗口内
+// 参数:
+//
+//	ctx: 上下文
+//	bucket: 存储桶名称
+//	key: 文件键
+//	versionID: 版本ID（为空时检查当前版本）
+//
+// 返回值:
+//
+//	error: 处于窗口内时返回*ImmutableError，对象不存在时返回nil
+func (g *immutabilityGuard) check(ctx context.Context, bucket, key, versionID string) error {
+	if key == "" || (g.exemptPrefix != "" && strings.HasPrefix(key, g.exemptPrefix)) {
+		return nil
+	}
+
+	input := &s3.HeadObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	}
+	if versionID != "" {
+		input.VersionId = aws.String(versionID)
+	}
+	output, err := g.client.HeadObject(withImmutabilityBypass(ctx), input)
+	if IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if output.LastModified == nil {
+		return nil
+	}
+	if unlockAt := output.LastModified.Add(g.window); time.Now().Before(unlockAt) {
+		return &ImmutableError{Key: key, UnlockAt: unlockAt}
+	}
+	return nil
+}
+
+// middleware 返回在覆盖或删除对象前检查不可变窗口的SDK中间件
+// 覆盖PutObject、CopyObject（目标）、CreateMultipartUpload、DeleteObject和DeleteObjects；
+// DeleteObjects中处于窗口内的键不会发送给S3，而是以ObjectImmutable错误出现在结果中。
+// 检查本身发起的HEAD请求带有跳过标记，不会再次触发检查。预签名请求的中间件栈中没有Retry中间件，此时不挂载
+// 返回值:
+//
+//	func(*middleware.Stack) error: 用于s3.Options.APIOptions的中间件注册函数
+func (g *immutabilityGuard) middleware() func(*middleware.Stack) error {
+	return func(stack *middleware.Stack) error {
+		if _, ok := stack.Finalize.Get("Retry"); !ok {
+			return nil
+		}
+
+		return stack.Initialize.Add(middleware.InitializeMiddlewareFunc("ImmutabilityWindow",
+			func(ctx context.Context, in middleware.InitializeInput, next middleware.InitializeHandler) (middleware.InitializeOutput, middleware.Metadata, error) {
+				if ctx.Value(immutabilityBypassKey{}) != nil {
+					return next.HandleInitialize(ctx, in)
+				}
+
+				var err error
+				switch input := in.Parameters.(type) {
+				case *s3.PutObjectInput:
+					err = g.check(ctx, aws.ToString(input.Bucket), aws.ToString(input.Key), "")
+				case *s3.CopyObjectInput:
+					err = g.check(ctx, aws.ToString(input.Bucket), aws.ToString(input.Key), "")
+				case *s3.CreateMultipartUploadInput:
+					err = g.check(ctx, aws.ToString(input.Bucket), aws.ToString(input.Key), "")
+				case *s3.DeleteObjectInput:
+					err = g.check(ctx, aws.ToString(input.Bucket), aws.ToString(input.Key), aws.ToString(input.VersionId))
+				case *s3.DeleteObjectsInput:
+					return g.handleDeleteObjects(ctx, in, input, next)
+				}
+				if err != nil {
+					return middleware.InitializeOutput{}, middleware.Metadata{}, err
+				}
+				return next.HandleInitialize(ctx, in)
+			}), middleware.Before)
+	}
+}
+
+// handleDeleteObjects 从DeleteObjects请求中移除处于不可变窗口内的键，并将它们作为错误加入结果
+// 参数:
+//
+//	ctx: 上下文
+//	in: 中间件输入
+//	input: DeleteObjects的输入参数
+//	next: 下一个处理器
+//
+// 返回值:
+//
+//	middleware.InitializeOutput: 中间件输出
+//	middleware.Metadata: 元数据
+//	error: 错误信息
+func (g *immutabilityGuard) handleDeleteObjects(ctx context.Context, in middleware.InitializeInput, input *s3.DeleteObjectsInput, next middleware.InitializeHandler) (middleware.InitializeOutput, middleware.Metadata, error) {
+	if input.Delete == nil {
+		return next.HandleInitialize(ctx, in)
+	}
+
+	bucket := aws.ToString(input.Bucket)
+	var allowed []types.ObjectIdentifier
+	var refused []types.Error
+	for _, obj := range input.Delete.Objects {
+		err := g.check(ctx, bucket, aws.ToString(obj.Key), aws.ToString(obj.VersionId))
+		var immutable *ImmutableError
+		if errors.As(err, &immutable) {
+			refused = append(refused, types.Error{
+				Key:       obj.Key,
+				VersionId: obj.VersionId,
+				Code:      aws.String(immutableErrorCode),
+				Message:   aws.String(immutable.Error()),
+			})
+			continue
+		}
+		if err != nil {
+			return middleware.InitializeOutput{}, middleware.Metadata{}, err
+		}
+		allowed = append(allowed, obj)
+	}
+	if len(refused) == 0 {
+		return next.HandleInitialize(ctx, in)
+	}
+	if len(allowed) == 0 {
+		return middleware.InitializeOutput{Result: &s3.DeleteObjectsOutput{Errors: refused}}, middleware.Metadata{}, nil
+	}
+
+	// 调用方的输入可能被复用，复制后再修改
+	cp := *input
+	del := *input.Delete
+	del.Objects = allowed
+	cp.Delete = &del
+	in.Parameters = &cp
+
+	out, metadata, err := next.HandleInitialize(ctx, in)
+	if err != nil {
+		return out, metadata, err
+	}
+	if output, ok := out.Result.(*s3.DeleteObjectsOutput); ok {
+		output.Errors = append(output.Errors, refused...)
+	}
+	return out, metadata, err
+}
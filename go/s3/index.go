@@ -0,0 +1,224 @@
+// Package s3 提供S3服务相关功能
+// 作者: KO
+// 创建时间: 2026-10-15
+// 修改时间: 2026-10-15
+package s3
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// ErrMetadataIndexDisabled 元数据索引未启用
+var ErrMetadataIndexDisabled = errors.New("metadata index is disabled")
+
+// IndexEntry 元数据索引中的一条记录
+type IndexEntry struct {
+	Key      string            `json:"key"`      // 文件键
+	Metadata map[string]string `json:"metadata"` // 用户自定义元数据
+}
+
+// MetadataIndex 默认存储桶中对象用户元数据的内存索引
+// 索引由后台任务按固定间隔全量重建（列举对象并逐个HeadObject），
+// 并在通过本服务上传/删除对象时增量更新。
+// 注意：索引是最终一致的——绕过本服务的写入要等到下一次重建才可见，
+// 且索引只存在于当前实例内存中，多实例部署时各实例之间互不同步。
+type MetadataIndex struct {
+	service  *Service      // S3服务实例
+	bucket   string        // 被索引的存储桶
+	interval time.Duration // 全量重建间隔
+
+	mu      sync.RWMutex                 // 保护entries
+	entries map[string]map[string]string // 文件键 -> 用户元数据
+}
+
+// newMetadataIndex 创建元数据索引
+// 参数:
+//
+//	service: S3服务实例
+//	bucket: 被索引的存储桶
+//	interval: 全量重建间隔
+//
+// 返回值:
+//
+//	*MetadataIndex: 元数据索引
+func newMetadataIndex(service *Service, bucket string, interval time.Duration) *MetadataIndex {
+	return &MetadataIndex{
+		service:  service,
+		bucket:   bucket,
+		interval: interval,
+		entries:  make(map[string]map[string]string),
+	}
+}
+
+// run 立即构建一次索引，之后按间隔定时重建，直到上下文取消
+// 参数:
+//
+//	ctx: 上下文
+func (idx *MetadataIndex) run(ctx context.Context) {
+	ticker := time.NewTicker(idx.interval)
+	defer ticker.Stop()
+
+	for {
+		if err := idx.rebuild(ctx); err != nil && ctx.Err() == nil {
+			log.Printf("Failed to rebuild metadata index for bucket %s: %v", idx.bucket, err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// rebuild 列举存储桶中的全部对象并获取其元数据，完成后整体替换索引
+// 参数:
+//
+//	ctx: 上下文
+//
+// 返回值:
+//
+//	error: 错误信息
+func (idx *MetadataIndex) rebuild(ctx context.Context) error {
+	var keys []string
+	err := idx.service.listAllObjects(ctx, idx.bucket, "", func(obj types.Object) error {
+		if !isFolderMarker(obj) {
+			keys = append(keys, aws.ToString(obj.Key))
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	metadata := make([]map[string]string, len(keys))
+	forEachConcurrent(ctx, idx.service.concurrency, len(keys), func(ctx context.Context, i int) {
+		output, err := idx.service.client.HeadObject(ctx, &s3.HeadObjectInput{
+			Bucket: aws.String(idx.bucket),
+			Key:    aws.String(keys[i]),
+		})
+		if err != nil {
+			// 对象可能在列举之后被删除，跳过即可
+			return
+		}
+		metadata[i] = output.Metadata
+	})
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	entries := make(map[string]map[string]string, len(keys))
+	for i, key := range keys {
+		if metadata[i] != nil {
+			entries[key] = metadata[i]
+		} else {
+			entries[key] = map[string]string{}
+		}
+	}
+
+	idx.mu.Lock()
+	idx.entries = entries
+	idx.mu.Unlock()
+
+	return nil
+}
+
+// put 上传对象后更新索引
+// 参数:
+//
+//	bucket: 存储桶名称
+//	key: 文件键
+//	metadata: 用户自定义元数据
+func (idx *MetadataIndex) put(bucket, key string, metadata map[string]string) {
+	if bucket != idx.bucket {
+		return
+	}
+	if metadata == nil {
+		metadata = map[string]string{}
+	}
+
+	idx.mu.Lock()
+	idx.entries[key] = metadata
+	idx.mu.Unlock()
+}
+
+// copy 复制对象后更新索引，复制的对象沿用源对象的元数据
+// 参数:
+//
+//	srcBucket: 源存储桶名称
+//	srcKey: 源文件键
+//	dstBucket: 目标存储桶名称
+//	dstKey: 目标文件键
+func (idx *MetadataIndex) copy(srcBucket, srcKey, dstBucket, dstKey string) {
+	if dstBucket != idx.bucket {
+		return
+	}
+
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	metadata := map[string]string{}
+	if srcBucket == idx.bucket {
+		if source, ok := idx.entries[srcKey]; ok {
+			metadata = source
+		}
+	}
+	idx.entries[dstKey] = metadata
+}
+
+// remove 删除对象后更新索引
+// 参数:
+//
+//	bucket: 存储桶名称
+//	key: 文件键
+func (idx *MetadataIndex) remove(bucket, key string) {
+	if bucket != idx.bucket {
+		return
+	}
+
+	idx.mu.Lock()
+	delete(idx.entries, key)
+	idx.mu.Unlock()
+}
+
+// search 查询元数据同时满足所有过滤条件的对象
+// 参数:
+//
+//	filters: 元数据过滤条件（元数据键 -> 期望值）
+//
+// 返回值:
+//
+//	[]IndexEntry: 匹配的记录（按文件键排序）
+func (idx *MetadataIndex) search(filters map[string]string) []IndexEntry {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	results := make([]IndexEntry, 0)
+	for key, metadata := range idx.entries {
+		matched := true
+		for name, value := range filters {
+			if metadata[name] != value {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			results = append(results, IndexEntry{Key: key, Metadata: metadata})
+		}
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].Key < results[j].Key
+	})
+
+	return results
+}
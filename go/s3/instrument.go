@@ -0,0 +1,124 @@
+// Package s3 提供S3服务相关功能
+// 作者: KO
+// 创建时间: 2026-10-15
+// 修改时间: 2026-10-15
+package s3
+
+import (
+	"context"
+	"reflect"
+	"sync/atomic"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsmiddleware "github.com/aws/aws-sdk-go-v2/aws/middleware"
+	"github.com/aws/aws-sdk-go-v2/aws/retry"
+	"github.com/aws/smithy-go/middleware"
+	"github.com/example/s3service/metrics"
+)
+
+// attemptCounterKey 上下文中保存单次操作尝试次数计数器的键
+type attemptCounterKey struct{}
+
+// throttleErrors 判断错误是否为限流错误（与SDK自适应重试模式使用的判定一致）
+var throttleErrors = retry.IsErrorThrottles(retry.DefaultThrottles)
+
+// upstreamMetrics 返回记录S3调用指标的SDK中间件
+// 以SDK中间件的形式挂载到客户端上，覆盖服务中的所有S3调用：按操作和存储桶统计调用次数、失败次数和耗时，
+// 按方向统计上传和下载的对象内容字节数。预签名请求不访问S3，其中间件栈中没有Retry中间件，此时不挂载
+// 参数:
+//
+//	labeler: 存储桶标签映射器
+//
+// 返回值:
+//
+//	func(*middleware.Stack) error: 用于s3.Options.APIOptions的中间件注册函数
+func upstreamMetrics(labeler *metrics.BucketLabeler) func(*middleware.Stack) error {
+	return func(stack *middleware.Stack) error {
+		if _, ok := stack.Finalize.Get("Retry"); !ok {
+			return nil
+		}
+
+		return stack.Initialize.Add(middleware.InitializeMiddlewareFunc("UpstreamMetrics",
+			func(ctx context.Context, in middleware.InitializeInput, next middleware.InitializeHandler) (middleware.InitializeOutput, middleware.Metadata, error) {
+				started := time.Now()
+				out, metadata, err := next.HandleInitialize(ctx, in)
+
+				operation := awsmiddleware.GetOperationName(ctx)
+				bucket := labeler.Label(inputBucket(in.Parameters))
+				metrics.UpstreamOperationsTotal.WithLabelValues(operation, bucket).Inc()
+				metrics.UpstreamDuration.WithLabelValues(operation, bucket).Observe(time.Since(started).Seconds())
+				if err != nil {
+					metrics.UpstreamErrorsTotal.WithLabelValues(operation, bucket).Inc()
+				} else if n, direction := transferredBytes(in.Parameters, out.Result); n > 0 {
+					metrics.UpstreamBytesTotal.WithLabelValues(direction, bucket).Add(float64(n))
+				}
+				return out, metadata, err
+			}), middleware.After)
+	}
+}
+
+// retryMetrics 返回记录SDK重试和限流指标的中间件
+// 在Initialize阶段为每次操作放入尝试计数器，在Finalize阶段紧跟SDK的Retry中间件之后统计每次尝试：
+// 第二次及以后的尝试计为一次重试，返回限流错误的尝试计为一次限流。
+// 预签名请求的中间件栈中没有Retry中间件，此时不挂载
+// 返回值:
+//
+//	func(*middleware.Stack) error: 用于s3.Options.APIOptions的中间件注册函数
+func retryMetrics() func(*middleware.Stack) error {
+	return func(stack *middleware.Stack) error {
+		if _, ok := stack.Finalize.Get("Retry"); !ok {
+			return nil
+		}
+
+		err := stack.Initialize.Add(middleware.InitializeMiddlewareFunc("RetryMetricsCounter",
+			func(ctx context.Context, in middleware.InitializeInput, next middleware.InitializeHandler) (middleware.InitializeOutput, middleware.Metadata, error) {
+				return next.HandleInitialize(context.WithValue(ctx, attemptCounterKey{}, new(int32)), in)
+			}), middleware.After)
+		if err != nil {
+			return err
+		}
+
+		return stack.Finalize.Insert(middleware.FinalizeMiddlewareFunc("RetryMetrics",
+			func(ctx context.Context, in middleware.FinalizeInput, next middleware.FinalizeHandler) (middleware.FinalizeOutput, middleware.Metadata, error) {
+				operation := awsmiddleware.GetOperationName(ctx)
+				if counter, ok := ctx.Value(attemptCounterKey{}).(*int32); ok && atomic.AddInt32(counter, 1) > 1 {
+					metrics.SDKRetriesTotal.WithLabelValues(operation).Inc()
+				}
+
+				out, metadata, err := next.HandleFinalize(ctx, in)
+				if err != nil && throttleErrors.IsErrorThrottle(err) == aws.TrueTernary {
+					metrics.SDKThrottlesTotal.WithLabelValues(operation).Inc()
+				}
+				return out, metadata, err
+			}), "Retry", middleware.After)
+	}
+}
+
+// inputBucket 从S3操作的输入参数中取出存储桶名称
+// 各操作的输入类型没有公共接口，这里通过反射读取Bucket字段
+// 参数:
+//
+//	params: 操作输入参数（如*s3.GetObjectInput）
+//
+// 返回值:
+//
+//	string: 存储桶名称，输入中没有存储桶时为空
+func inputBucket(params interface{}) string {
+	v := reflect.ValueOf(params)
+	if v.Kind() == reflect.Ptr {
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return ""
+	}
+
+	field := v.FieldByName("Bucket")
+	if !field.IsValid() {
+		return ""
+	}
+	if bucket, ok := field.Interface().(*string); ok && bucket != nil {
+		return *bucket
+	}
+	return ""
+}
@@ -0,0 +1,201 @@
+// Package s3 提供S3服务相关功能
+// 作者: KO
+// 创建时间: 2026-10-15
+// 修改时间: 2026-10-15
+package s3
+
+import (
+	"context"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/base64"
+	"hash"
+	"hash/crc32"
+	"io"
+	"log"
+	"math/rand"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/example/s3service/metrics"
+)
+
+// 完整性校验的结果
+const (
+	integrityOK       = "ok"       // 校验和一致
+	integrityMismatch = "mismatch" // 校验和不一致
+	integritySkipped  = "skipped"  // 对象没有可校验的完整对象校验和
+	integrityError    = "error"    // 获取属性或下载失败
+)
+
+// IntegrityChecker 后台对象完整性校验任务
+// 按间隔对前缀下的对象抽样，下载后重新计算校验和并与S3存储的校验和
+// （GetObjectAttributes返回的CRC32/CRC32C/SHA1/SHA256）比较，不一致时记录日志和指标。
+// 只有上传时指定了校验和算法的对象才有存储的校验和；分段上传对象的组合校验和无法据此重新计算，会被跳过
+type IntegrityChecker struct {
+	service    *Service      // S3服务实例
+	bucket     string        // 被校验的存储桶
+	prefix     string        // 被校验的键前缀
+	sampleRate float64       // 抽样比例（0~1）
+	interval   time.Duration // 校验间隔
+}
+
+// newIntegrityChecker 创建完整性校验任务
+// 参数:
+//
+//	service: S3服务实例
+//	bucket: 被校验的存储桶
+//	prefix: 被校验的键前缀
+//	sampleRate: 抽样比例（0~1）
+//	interval: 校验间隔
+//
+// 返回值:
+//
+//	*IntegrityChecker: 完整性校验任务
+func newIntegrityChecker(service *Service, bucket, prefix string, sampleRate float64, interval time.Duration) *IntegrityChecker {
+	return &IntegrityChecker{
+		service:    service,
+		bucket:     bucket,
+		prefix:     prefix,
+		sampleRate: sampleRate,
+		interval:   interval,
+	}
+}
+
+// run 按间隔执行抽样校验，直到上下文取消
+// 参数:
+//
+//	ctx: 上下文
+func (c *IntegrityChecker) run(ctx context.Context) {
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if err := c.checkOnce(ctx); err != nil && ctx.Err() == nil {
+			log.Printf("Integrity check of bucket %s failed: %v", c.bucket, err)
+		}
+	}
+}
+
+// checkOnce 执行一轮抽样校验
+// 参数:
+//
+//	ctx: 上下文
+//
+// 返回值:
+//
+//	error: 列举对象失败时的错误信息
+func (c *IntegrityChecker) checkOnce(ctx context.Context) error {
+	var sampled []string
+	err := c.service.listAllObjects(ctx, c.bucket, c.prefix, func(obj types.Object) error {
+		if rand.Float64() < c.sampleRate {
+			sampled = append(sampled, aws.ToString(obj.Key))
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	forEachConcurrent(ctx, c.service.concurrency, len(sampled), func(ctx context.Context, i int) {
+		result := c.verify(ctx, sampled[i])
+		metrics.IntegrityChecksTotal.WithLabelValues(result).Inc()
+	})
+
+	return nil
+}
+
+// verify 校验单个对象
+// 参数:
+//
+//	ctx: 上下文
+//	key: 文件键
+//
+// 返回值:
+//
+//	string: 校验结果（ok、mismatch、skipped、error）
+func (c *IntegrityChecker) verify(ctx context.Context, key string) string {
+	attrs, err := c.service.client.GetObjectAttributes(ctx, &s3.GetObjectAttributesInput{
+		Bucket:           aws.String(c.bucket),
+		Key:              aws.String(key),
+		ObjectAttributes: []types.ObjectAttributes{types.ObjectAttributesChecksum},
+	})
+	if err != nil {
+		log.Printf("Integrity check: failed to get attributes of %s/%s: %v", c.bucket, key, err)
+		return integrityError
+	}
+
+	algorithm, expected, h := storedChecksum(attrs.Checksum)
+	if h == nil {
+		return integritySkipped
+	}
+
+	output, err := c.service.client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(c.bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		log.Printf("Integrity check: failed to download %s/%s: %v", c.bucket, key, err)
+		return integrityError
+	}
+	defer output.Body.Close()
+
+	if _, err := io.Copy(h, output.Body); err != nil {
+		log.Printf("Integrity check: failed to read %s/%s: %v", c.bucket, key, err)
+		return integrityError
+	}
+
+	actual := base64.StdEncoding.EncodeToString(h.Sum(nil))
+	if actual != expected {
+		log.Printf("Integrity check: %s mismatch for %s/%s: stored %s, computed %s", algorithm, c.bucket, key, expected, actual)
+		return integrityMismatch
+	}
+
+	return integrityOK
+}
+
+// storedChecksum 从对象属性中选出可重新计算的完整对象校验和
+// 参数:
+//
+//	checksum: GetObjectAttributes返回的校验和
+//
+// 返回值:
+//
+//	string: 算法名称
+//	string: 存储的校验和（base64）
+//	hash.Hash: 对应算法的哈希实例，没有可用校验和时为nil
+func storedChecksum(checksum *types.Checksum) (string, string, hash.Hash) {
+	if checksum == nil {
+		return "", "", nil
+	}
+
+	candidates := []struct {
+		algorithm string
+		value     *string
+		newHash   func() hash.Hash
+	}{
+		{"SHA256", checksum.ChecksumSHA256, sha256.New},
+		{"SHA1", checksum.ChecksumSHA1, sha1.New},
+		{"CRC32C", checksum.ChecksumCRC32C, func() hash.Hash { return crc32.New(crc32.MakeTable(crc32.Castagnoli)) }},
+		{"CRC32", checksum.ChecksumCRC32, func() hash.Hash { return crc32.NewIEEE() }},
+	}
+	for _, candidate := range candidates {
+		value := aws.ToString(candidate.value)
+		// 分段上传对象的组合校验和形如xxx-N，无法通过整体内容重新计算
+		if value == "" || strings.Contains(value, "-") {
+			continue
+		}
+		return candidate.algorithm, value, candidate.newHash()
+	}
+
+	return "", "", nil
+}
@@ -0,0 +1,146 @@
+// Package s3 提供S3服务相关功能
+// 作者: KO
+// 创建时间: 2026-10-15
+// 修改时间: 2026-10-15
+package s3
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"unicode"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// errKeyFound 在列举中找到匹配的键后用于提前结束列举
+var errKeyFound = errors.New("key found")
+
+// NormalizeKey 按key_case配置规范化上传对象的键的大小写
+// 参数:
+//
+//	key: 文件键
+//
+// 返回值:
+//
+//	string: 规范化后的文件键（未配置key_case时原样返回）
+func (s *Service) NormalizeKey(key string) string {
+	switch s.cfg.KeyCase {
+	case "lower":
+		return strings.ToLower(key)
+	case "upper":
+		return strings.ToUpper(key)
+	}
+	return key
+}
+
+// resolveKey 将请求的键解析为实际存储的键
+// 未启用case_insensitive_keys时原样返回；启用时先精确查找，找不到再回退到大小写不敏感查找
+// 参数:
+//
+//	ctx: 上下文
+//	bucket: 存储桶名称
+//	key: 请求的文件键
+//
+// 返回值:
+//
+//	string: 实际存储的键（找不到时返回原键，由后续操作报告不存在）
+//	error: 错误信息
+func (s *Service) resolveKey(ctx context.Context, bucket, key string) (string, error) {
+	if !s.cfg.CaseInsensitiveKeys {
+		return key, nil
+	}
+
+	_, err := s.client.HeadObject(ctx, &s3.HeadObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	})
+	if err == nil {
+		return key, nil
+	}
+	if !IsNotFound(err) {
+		return "", err
+	}
+
+	canonical, found, err := s.findKeyCaseInsensitive(ctx, bucket, key)
+	if err != nil {
+		return "", err
+	}
+	if !found {
+		return key, nil
+	}
+	return canonical, nil
+}
+
+// findKeyCaseInsensitive 以大小写不敏感的方式查找键
+// S3的前缀匹配区分大小写，因此只能以键开头不含字母的部分作为前缀列举，再逐个比较。
+// 注意：键以字母开头时需要列举整个存储桶，对象较多时开销很大，只应作为精确查找失败后的回退
+// 参数:
+//
+//	ctx: 上下文
+//	bucket: 存储桶名称
+//	key: 请求的文件键
+//
+// 返回值:
+//
+//	string: 匹配到的实际键
+//	bool: 是否找到
+//	error: 错误信息
+func (s *Service) findKeyCaseInsensitive(ctx context.Context, bucket, key string) (string, bool, error) {
+	var canonical string
+	err := s.listAllObjects(ctx, bucket, caseInvariantPrefix(key), func(obj types.Object) error {
+		if strings.EqualFold(aws.ToString(obj.Key), key) {
+			canonical = aws.ToString(obj.Key)
+			return errKeyFound
+		}
+		return nil
+	})
+	if errors.Is(err, errKeyFound) {
+		return canonical, true, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+	return "", false, nil
+}
+
+// caseInvariantPrefix 返回键开头不受大小写影响的部分（第一个有大小写之分的字符之前）
+// 参数:
+//
+//	key: 文件键
+//
+// 返回值:
+//
+//	string: 前缀
+func caseInvariantPrefix(key string) string {
+	for i, r := range key {
+		if unicode.ToUpper(r) != unicode.ToLower(r) {
+			return key[:i]
+		}
+	}
+	return key
+}
+
+// IsNotFound 判断错误是否表示对象不存在
+// 参数:
+//
+//	err: 错误
+//
+// 返回值:
+//
+//	bool: 是否为不存在错误
+func IsNotFound(err error) bool {
+	var respErr *awshttp.ResponseError
+	if errors.As(err, &respErr) && respErr.HTTPStatusCode() == http.StatusNotFound {
+		return true
+	}
+	switch errorCode(err) {
+	case "NotFound", "NoSuchKey":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,94 @@
+// Package s3 提供S3服务相关功能
+// 作者: KO
+// 创建时间: 2026-10-15
+// 修改时间: 2026-10-15
+package s3
+
+import (
+	"context"
+	"io"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/smithy-go/middleware"
+	"github.com/example/s3service/metrics"
+	"golang.org/x/sync/semaphore"
+)
+
+// upstreamLimiter 返回限制全部S3调用并发数的SDK中间件
+// 每次操作（包括其所有重试）在Initialize阶段占用一个名额，使用调用方的上下文等待，请求取消时立即返回；
+// GetObject的响应体在操作返回后才被读取，名额保留到响应体读完或关闭为止，
+// 因此流式下载在传输期间持续占用名额。
+// 预签名请求不访问S3，其中间件栈中没有Retry中间件，此时不挂载
+// 参数:
+//
+//	limit: 最大并发数
+//
+// 返回值:
+//
+//	func(*middleware.Stack) error: 用于s3.Options.APIOptions的中间件注册函数
+func upstreamLimiter(limit int) func(*middleware.Stack) error {
+	sem := semaphore.NewWeighted(int64(limit))
+	return func(stack *middleware.Stack) error {
+		if _, ok := stack.Finalize.Get("Retry"); !ok {
+			return nil
+		}
+
+		return stack.Initialize.Add(middleware.InitializeMiddlewareFunc("UpstreamLimiter",
+			func(ctx context.Context, in middleware.InitializeInput, next middleware.InitializeHandler) (middleware.InitializeOutput, middleware.Metadata, error) {
+				if err := sem.Acquire(ctx, 1); err != nil {
+					return middleware.InitializeOutput{}, middleware.Metadata{}, err
+				}
+				metrics.UpstreamInFlight.Inc()
+				var once sync.Once
+				release := func() {
+					once.Do(func() {
+						metrics.UpstreamInFlight.Dec()
+						sem.Release(1)
+					})
+				}
+
+				out, metadata, err := next.HandleInitialize(ctx, in)
+				if output, ok := out.Result.(*s3.GetObjectOutput); ok && err == nil && output.Body != nil {
+					output.Body = &releasingBody{ReadCloser: output.Body, release: release}
+					return out, metadata, err
+				}
+				release()
+				return out, metadata, err
+			}), middleware.Before)
+	}
+}
+
+// releasingBody 读取到末尾或关闭时释放并发名额的响应体
+// 读完后立即释放，避免调用方在关闭前继续发起S3调用时与自己占用的名额互相等待
+type releasingBody struct {
+	io.ReadCloser
+	release func() // 释放名额（可重复调用）
+}
+
+// Read 读取响应体，读取到末尾时释放并发名额
+// 参数:
+//
+//	p: 读取缓冲区
+//
+// 返回值:
+//
+//	int: 读取的字节数
+//	error: 错误信息
+func (b *releasingBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if err == io.EOF {
+		b.release()
+	}
+	return n, err
+}
+
+// Close 关闭响应体并释放并发名额
+// 返回值:
+//
+//	error: 错误信息
+func (b *releasingBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.release()
+	return err
+}
@@ -0,0 +1,143 @@
+// Package s3 提供S3服务相关功能
+// 作者: KO
+// 创建时间: 2026-10-15
+// 修改时间: 2026-10-15
+package s3
+
+import (
+	"context"
+	"log/slog"
+	"reflect"
+	"sync/atomic"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/smithy-go/middleware"
+)
+
+// operationLogger 以结构化日志记录每次S3操作
+// 日志记录器可以在服务创建后替换（SetLogger），未设置时使用slog.Default()
+type operationLogger struct {
+	logger atomic.Pointer[slog.Logger] // 日志记录器
+}
+
+// get 返回当前的日志记录器
+// 返回值:
+//
+//	*slog.Logger: 日志记录器
+func (l *operationLogger) get() *slog.Logger {
+	if logger := l.logger.Load(); logger != nil {
+		return logger
+	}
+	return slog.Default()
+}
+
+// SetLogger 设置记录S3操作日志的日志记录器，替换默认的slog.Default()
+// 参数:
+//
+//	logger: 日志记录器
+func (s *Service) SetLogger(logger *slog.Logger) {
+	s.logger.logger.Store(logger)
+}
+
+// middleware 返回记录S3操作日志的SDK中间件
+// 每次操作（包括重试在内）记录一条日志：操作名、存储桶、键、传输的字节数、耗时、结果和请求ID。
+// 结果为ok、not_found或error，失败的操作以Error级别记录并附带错误信息；404是常见的正常结果（如检查存在性），
+// 以Info级别记录。预签名请求的中间件栈中没有Retry中间件，此时不挂载
+// 返回值:
+//
+//	func(*middleware.Stack) error: 用于s3.Options.APIOptions的中间件注册函数
+func (l *operationLogger) middleware() func(*middleware.Stack) error {
+	return func(stack *middleware.Stack) error {
+		if _, ok := stack.Finalize.Get("Retry"); !ok {
+			return nil
+		}
+
+		// 位于外层时SDK尚未在上下文中记录操作名，取中间件栈的ID（即操作名）
+		operation := stack.ID()
+		return stack.Initialize.Add(middleware.InitializeMiddlewareFunc("OperationLogger",
+			func(ctx context.Context, in middleware.InitializeInput, next middleware.InitializeHandler) (middleware.InitializeOutput, middleware.Metadata, error) {
+				started := time.Now()
+				out, metadata, err := next.HandleInitialize(ctx, in)
+
+				bytes, _ := transferredBytes(in.Parameters, out.Result)
+				attrs := []slog.Attr{
+					slog.String("op", operation),
+					slog.String("bucket", inputBucket(in.Parameters)),
+					slog.String("key", inputKey(in.Parameters)),
+					slog.Int64("bytes", bytes),
+					slog.Duration("duration", time.Since(started)),
+				}
+				if id, _ := ctx.Value(requestIDKey{}).(string); id != "" {
+					attrs = append(attrs, slog.String("request_id", id))
+				}
+
+				level := slog.LevelInfo
+				switch {
+				case err == nil:
+					attrs = append(attrs, slog.String("outcome", "ok"))
+				case IsNotFound(err):
+					attrs = append(attrs, slog.String("outcome", "not_found"))
+				default:
+					level = slog.LevelError
+					attrs = append(attrs, slog.String("outcome", "error"), slog.String("error", err.Error()))
+				}
+				l.get().LogAttrs(ctx, level, "s3 operation", attrs...)
+
+				return out, metadata, err
+			}), middleware.Before)
+	}
+}
+
+// inputKey 从S3操作的输入参数中取出对象键
+// 与inputBucket相同，通过反射读取Key字段
+// 参数:
+//
+//	params: 操作输入参数（如*s3.GetObjectInput）
+//
+// 返回值:
+//
+//	string: 对象键，输入中没有键时为空
+func inputKey(params interface{}) string {
+	v := reflect.ValueOf(params)
+	if v.Kind() == reflect.Ptr {
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return ""
+	}
+
+	field := v.FieldByName("Key")
+	if !field.IsValid() {
+		return ""
+	}
+	if key, ok := field.Interface().(*string); ok && key != nil {
+		return *key
+	}
+	return ""
+}
+
+// transferredBytes 返回操作传输的对象内容字节数及方向
+// 上传（PutObject、UploadPart）取请求的长度，下载（GetObject）取响应的长度，其他操作为0
+// 参数:
+//
+//	params: 操作输入参数
+//	result: 操作输出（失败时为nil）
+//
+// 返回值:
+//
+//	int64: 字节数
+//	string: 方向（upload、download），不传输对象内容时为空
+func transferredBytes(params, result interface{}) (int64, string) {
+	switch input := params.(type) {
+	case *s3.PutObjectInput:
+		return aws.ToInt64(input.ContentLength), "upload"
+	case *s3.UploadPartInput:
+		return aws.ToInt64(input.ContentLength), "upload"
+	}
+	if output, ok := result.(*s3.GetObjectOutput); ok {
+		return aws.ToInt64(output.ContentLength), "download"
+	}
+	return 0, ""
+}
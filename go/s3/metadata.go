@@ -0,0 +1,168 @@
+// Package s3 提供S3服务相关功能
+// 作者: KO
+// 创建时间: 2026-10-15
+// 修改时间: 2026-10-15
+package s3
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/smithy-go/middleware"
+)
+
+// ErrInvalidMetadata 用户自定义元数据的名称或值无法作为x-amz-meta-*请求头发送，或总大小超过S3的限制
+var ErrInvalidMetadata = errors.New("invalid metadata")
+
+// maxUserMetadataSize S3对用户自定义元数据的大小限制（全部名称与值的字节数之和）
+const maxUserMetadataSize = 2048
+
+// validateMetadata 检查用户自定义元数据能否作为x-amz-meta-*请求头发送
+// 名称只允许HTTP头名称中的字符（S3会将其转为小写），值只允许可打印的ASCII字符
+// 参数:
+//
+//	metadata: 用户自定义元数据
+//
+// 返回值:
+//
+//	error: 不合法时返回包装了ErrInvalidMetadata的错误
+func validateMetadata(metadata map[string]string) error {
+	size := 0
+	for name, value := range metadata {
+		if name == "" {
+			return fmt.Errorf("%w: empty name", ErrInvalidMetadata)
+		}
+		for i := 0; i < len(name); i++ {
+			if !isTokenChar(name[i]) {
+				return fmt.Errorf("%w: name %q contains an invalid character", ErrInvalidMetadata, name)
+			}
+		}
+		for i := 0; i < len(value); i++ {
+			if value[i] < 0x20 || value[i] > 0x7e {
+				return fmt.Errorf("%w: value of %q must be printable ASCII", ErrInvalidMetadata, name)
+			}
+		}
+		size += len(name) + len(value)
+	}
+	if size > maxUserMetadataSize {
+		return fmt.Errorf("%w: %d bytes exceeds the %d byte limit", ErrInvalidMetadata, size, maxUserMetadataSize)
+	}
+	return nil
+}
+
+// isTokenChar 判断字节是否可用于HTTP头名称（RFC 7230 token）
+// 参数:
+//
+//	c: 字节
+//
+// 返回值:
+//
+//	bool: 是否可用
+func isTokenChar(c byte) bool {
+	switch {
+	case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		return true
+	}
+	switch c {
+	case '!', '#', '$', '%', '&', '\'', '*', '+', '-', '.', '^', '_', '`', '|', '~':
+		return true
+	}
+	return false
+}
+
+// GetMetadata 通过HeadObject获取对象的用户自定义元数据（x-amz-meta-*，名称为小写且不含前缀）
+// 参数:
+//
+//	ctx: 上下文
+//	bucket: 存储桶名称（为空时使用默认存储桶）
+//	key: 文件键
+//
+// 返回值:
+//
+//	map[string]string: 用户自定义元数据（没有时为空map）
+//	error: 错误信息，对象不存在时可用IsNotFound判断
+func (s *Service) GetMetadata(ctx context.Context, bucket, key string) (map[string]string, error) {
+	info, err := s.StatObject(ctx, bucket, key)
+	if err != nil {
+		return nil, err
+	}
+	if info.Metadata == nil {
+		return map[string]string{}, nil
+	}
+	return info.Metadata, nil
+}
+
+// defaultMetadata 返回在写入对象时补充默认用户元数据的SDK中间件
+// 作用于PutObject和CreateMultipartUpload：请求中已有的同名元数据（名称不区分大小写）优先，
+// 其余默认项合并到元数据中。合并后超过S3的元数据大小限制时返回包装了ErrInvalidMetadata的错误，
+// 不发送请求。预签名请求的中间件栈中没有Retry中间件，此时不挂载
+// 参数:
+//
+//	defaults: 默认元数据（default_metadata）
+//
+// 返回值:
+//
+//	func(*middleware.Stack) error: 用于s3.Options.APIOptions的中间件注册函数
+func defaultMetadata(defaults map[string]string) func(*middleware.Stack) error {
+	return func(stack *middleware.Stack) error {
+		if _, ok := stack.Finalize.Get("Retry"); !ok {
+			return nil
+		}
+
+		return stack.Initialize.Add(middleware.InitializeMiddlewareFunc("DefaultMetadata",
+			func(ctx context.Context, in middleware.InitializeInput, next middleware.InitializeHandler) (middleware.InitializeOutput, middleware.Metadata, error) {
+				// 调用方的输入和元数据可能被复用，复制后再修改
+				switch input := in.Parameters.(type) {
+				case *s3.PutObjectInput:
+					merged, err := mergeDefaultMetadata(input.Metadata, defaults)
+					if err != nil {
+						return middleware.InitializeOutput{}, middleware.Metadata{}, err
+					}
+					cp := *input
+					cp.Metadata = merged
+					in.Parameters = &cp
+				case *s3.CreateMultipartUploadInput:
+					merged, err := mergeDefaultMetadata(input.Metadata, defaults)
+					if err != nil {
+						return middleware.InitializeOutput{}, middleware.Metadata{}, err
+					}
+					cp := *input
+					cp.Metadata = merged
+					in.Parameters = &cp
+				}
+				return next.HandleInitialize(ctx, in)
+			}), middleware.Before)
+	}
+}
+
+// mergeDefaultMetadata 将默认元数据合并到请求的元数据之下
+// 参数:
+//
+//	metadata: 请求的元数据（可为nil）
+//	defaults: 默认元数据
+//
+// 返回值:
+//
+//	map[string]string: 合并后的元数据副本
+//	error: 合并后超过大小限制时返回包装了ErrInvalidMetadata的错误
+func mergeDefaultMetadata(metadata, defaults map[string]string) (map[string]string, error) {
+	merged := make(map[string]string, len(metadata)+len(defaults))
+	present := make(map[string]bool, len(metadata))
+	for name, value := range metadata {
+		merged[name] = value
+		present[strings.ToLower(name)] = true
+	}
+	for name, value := range defaults {
+		if !present[strings.ToLower(name)] {
+			merged[name] = value
+		}
+	}
+
+	if err := validateMetadata(merged); err != nil {
+		return nil, fmt.Errorf("%w (including default_metadata)", err)
+	}
+	return merged, nil
+}
@@ -0,0 +1,158 @@
+// Package s3 提供S3服务相关功能
+// 作者: KO
+// 创建时间: 2026-10-15
+// 修改时间: 2026-10-15
+package s3
+
+import (
+	"context"
+	"errors"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// ErrMetadataTemplateEmpty 没有配置元数据模板
+var ErrMetadataTemplateEmpty = errors.New("no metadata template configured")
+
+// templateNowPlaceholder 元数据模板中替换为本次执行时间（RFC3339，UTC）的占位符
+const templateNowPlaceholder = "{{now}}"
+
+// TemplateResult 对单个对象应用元数据模板的结果
+type TemplateResult struct {
+	Key          string `json:"key"`                    // 文件键
+	CacheControl string `json:"cacheControl,omitempty"` // 应用后的Cache-Control
+	Error        string `json:"error,omitempty"`        // 错误信息
+}
+
+// ApplyMetadataTemplate 对前缀下的全部对象应用配置的元数据模板
+// 通过MetadataDirective为REPLACE的自我复制原地改写元数据，不重新上传内容：
+// Cache-Control按扩展名取自metadata_template_cache_control（未匹配时使用default项），
+// metadata_template_metadata中的项覆盖到已有的用户元数据上，值中的{{now}}替换为本次执行时间。
+// 内容类型、内容编码等其他系统元数据保持不变。CopyObject不支持超过5GB的对象，这类对象会报告错误
+// 参数:
+//
+//	ctx: 上下文
+//	bucket: 存储桶名称（为空时使用默认存储桶）
+//	prefix: 键前缀（为空时处理整个存储桶）
+//
+// 返回值:
+//
+//	[]TemplateResult: 每个对象的处理结果
+//	error: 未配置模板或列举失败时的错误信息
+func (s *Service) ApplyMetadataTemplate(ctx context.Context, bucket, prefix string) ([]TemplateResult, error) {
+	if bucket == "" {
+		bucket = s.defaultBucket
+	}
+	if len(s.cfg.MetadataTemplateCacheControl) == 0 && len(s.cfg.MetadataTemplateMetadata) == 0 {
+		return nil, ErrMetadataTemplateEmpty
+	}
+
+	var keys []string
+	err := s.listAllObjects(ctx, bucket, prefix, func(obj types.Object) error {
+		keys = append(keys, aws.ToString(obj.Key))
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC().Format(time.RFC3339)
+	results := make([]TemplateResult, len(keys))
+	forEachConcurrent(ctx, s.concurrency, len(keys), func(ctx context.Context, i int) {
+		results[i] = s.applyTemplate(ctx, bucket, keys[i], now)
+	})
+
+	return results, nil
+}
+
+// applyTemplate 对单个对象应用元数据模板
+// 参数:
+//
+//	ctx: 上下文
+//	bucket: 存储桶名称
+//	key: 文件键
+//	now: 替换{{now}}的时间
+//
+// 返回值:
+//
+//	TemplateResult: 处理结果
+func (s *Service) applyTemplate(ctx context.Context, bucket, key, now string) TemplateResult {
+	result := TemplateResult{Key: key}
+
+	head, err := s.client.HeadObject(ctx, &s3.HeadObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+
+	// REPLACE会清除未显式提供的元数据，因此先复制已有的值
+	metadata := make(map[string]string, len(head.Metadata)+len(s.cfg.MetadataTemplateMetadata))
+	for name, value := range head.Metadata {
+		metadata[name] = value
+	}
+	for name, value := range s.cfg.MetadataTemplateMetadata {
+		metadata[name] = strings.ReplaceAll(value, templateNowPlaceholder, now)
+	}
+
+	cacheControl := aws.ToString(head.CacheControl)
+	ext := strings.ToLower(strings.TrimPrefix(path.Ext(key), "."))
+	if value, ok := s.cfg.MetadataTemplateCacheControl[ext]; ok && ext != "" {
+		cacheControl = value
+	} else if value, ok := s.cfg.MetadataTemplateCacheControl["default"]; ok {
+		cacheControl = value
+	}
+
+	var cacheControlValue *string
+	if cacheControl != "" {
+		cacheControlValue = aws.String(cacheControl)
+	}
+	if _, err := s.replaceMetadata(ctx, bucket, key, head, metadata, cacheControlValue); err != nil {
+		result.Error = err.Error()
+		return result
+	}
+
+	if s.index != nil {
+		s.index.put(bucket, key, metadata)
+	}
+
+	result.CacheControl = cacheControl
+	return result
+}
+
+// replaceMetadata 通过MetadataDirective为REPLACE的自我复制替换对象的元数据
+// REPLACE会清除未显式提供的系统元数据，因此内容类型、内容编码等沿用HEAD读到的值
+// 参数:
+//
+//	ctx: 上下文
+//	bucket: 存储桶名称
+//	key: 文件键
+//	head: 对象当前的HEAD结果
+//	metadata: 新的用户元数据
+//	cacheControl: 新的Cache-Control（为nil时不设置）
+//
+// 返回值:
+//
+//	*s3.CopyObjectOutput: 复制结果
+//	error: 错误信息
+func (s *Service) replaceMetadata(ctx context.Context, bucket, key string, head *s3.HeadObjectOutput, metadata map[string]string, cacheControl *string) (*s3.CopyObjectOutput, error) {
+	return s.client.CopyObject(ctx, &s3.CopyObjectInput{
+		Bucket:             aws.String(bucket),
+		Key:                aws.String(key),
+		CopySource:         aws.String(copySource(bucket, key)),
+		MetadataDirective:  types.MetadataDirectiveReplace,
+		Metadata:           metadata,
+		ContentType:        head.ContentType,
+		ContentEncoding:    head.ContentEncoding,
+		ContentDisposition: head.ContentDisposition,
+		ContentLanguage:    head.ContentLanguage,
+		CacheControl:       cacheControl,
+	})
+}
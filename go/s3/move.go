@@ -0,0 +1,86 @@
+// Package s3 提供S3服务相关功能
+// 作者: KO
+// 创建时间: 2026-10-15
+// 修改时间: 2026-10-15
+package s3
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrInvalidMove 移动的源与目标相同或文件键为空
+	ErrInvalidMove = errors.New("invalid move")
+	// ErrMoveVerification 复制后的目标对象与源对象不一致，源对象未被删除
+	ErrMoveVerification = errors.New("move verification failed")
+)
+
+// MoveObject 移动（重命名）对象，可跨存储桶
+// S3没有原生的重命名操作，移动由三步完成且整体不是原子的：
+// 服务端复制到目标键，通过HeadObject确认目标存在且大小与源一致，然后删除源对象。
+// 校验失败时不删除源对象；删除源对象失败时删除目标副本回滚，
+// 因此任何一步失败都至少保留一份完整数据。移动期间其他客户端可能同时看到源和目标两个对象。
+// 目标键上已有的对象会被覆盖；CopyObject不支持超过5GB的对象
+// 参数:
+//
+//	ctx: 上下文
+//	srcBucket: 源存储桶名称（为空时使用默认存储桶）
+//	srcKey: 源文件键
+//	dstBucket: 目标存储桶名称（为空时使用默认存储桶）
+//	dstKey: 目标文件键
+//
+// 返回值:
+//
+//	error: 源与目标相同时返回包装了ErrInvalidMove的错误，
+//	       校验失败时返回包装了ErrMoveVerification的错误
+func (s *Service) MoveObject(ctx context.Context, srcBucket, srcKey, dstBucket, dstKey string) error {
+	if srcBucket == "" {
+		srcBucket = s.defaultBucket
+	}
+	if dstBucket == "" {
+		dstBucket = s.defaultBucket
+	}
+	if srcKey == "" || dstKey == "" {
+		return fmt.Errorf("%w: source and destination keys are required", ErrInvalidMove)
+	}
+
+	src, err := s.StatObject(ctx, srcBucket, srcKey)
+	if err != nil {
+		return err
+	}
+	// 源与目标相同时，回滚或删除源对象都会删除唯一的一份数据
+	if srcBucket == dstBucket && src.Key == dstKey {
+		return fmt.Errorf("%w: source and destination must differ", ErrInvalidMove)
+	}
+
+	// 源对象不可删除时提前失败，而不是复制后再回滚
+	if s.immutability != nil {
+		if err := s.immutability.check(ctx, srcBucket, src.Key, ""); err != nil {
+			return err
+		}
+	}
+
+	if err := s.CopyObject(ctx, srcBucket, src.Key, dstBucket, dstKey); err != nil {
+		return err
+	}
+
+	dst, err := s.StatObject(ctx, dstBucket, dstKey)
+	if err != nil {
+		return fmt.Errorf("%w: destination is not readable after copy: %v", ErrMoveVerification, err)
+	}
+	if dst.Size != src.Size {
+		return fmt.Errorf("%w: destination has %d bytes, source has %d", ErrMoveVerification, dst.Size, src.Size)
+	}
+
+	if err := s.DeleteFile(ctx, srcBucket, src.Key); err != nil {
+		// 目标副本刚刚写入，必然处于不可变窗口内，回滚时跳过检查
+		if rollbackErr := s.DeleteFile(withImmutabilityBypass(ctx), dstBucket, dstKey); rollbackErr != nil {
+			return errors.Join(fmt.Errorf("failed to delete source: %w", err), fmt.Errorf("failed to roll back copy: %w", rollbackErr))
+		}
+		return fmt.Errorf("failed to delete source, copy rolled back: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,223 @@
+// Package s3 提供S3服务相关功能
+// 作者: KO
+// 创建时间: 2026-10-15
+// 修改时间: 2026-10-15
+package s3
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// MinPartSize S3分段上传中除最后一段外每段的最小大小
+const MinPartSize = 5 * 1024 * 1024
+
+// abortTimeout 中止分段上传的超时时间
+// 中止时原请求的上下文可能已被取消，因此使用独立的上下文
+const abortTimeout = 30 * time.Second
+
+// MultipartProgress 服务端分段上传的进度
+type MultipartProgress struct {
+	PartNumber     int32 `json:"partNumber"`           // 正在上传或刚完成的分段号
+	PartsCompleted int32 `json:"partsCompleted"`       // 已完成的分段数
+	TotalParts     int32 `json:"totalParts,omitempty"` // 分段总数（对象大小未知时为0）
+	BytesCompleted int64 `json:"bytesCompleted"`       // 已上传的字节数
+	TotalBytes     int64 `json:"totalBytes,omitempty"` // 对象总大小（未知时为0）
+}
+
+// multipartUpload 按partSize读取body并以分段上传方式写入对象
+// 任一分段上传或完成请求失败时都会调用AbortMultipartUpload，避免残留未完成的分段
+// 参数:
+//
+//	ctx: 上下文
+//	bucket: 存储桶名称（为空时使用默认存储桶）
+//	key: 文件键
+//	body: 文件内容
+//	size: 对象总大小（未知时为0，用于报告进度和检查配额）
+//	partSize: 分段大小（小于MinPartSize时使用MinPartSize）
+//	onProgress: 每个分段开始上传和上传完成时的回调（可为nil）
+//	opts: 上传选项（只使用ContentType和Metadata）
+//
+// 返回值:
+//
+//	string: 完成后对象的ETag
+//	error: 错误信息
+func (s *Service) multipartUpload(ctx context.Context, bucket, key string, body io.Reader, size, partSize int64, onProgress func(MultipartProgress), opts UploadOptions) (string, error) {
+	if bucket == "" {
+		bucket = s.defaultBucket
+	}
+	if err := validateMetadata(opts.Metadata); err != nil {
+		return "", err
+	}
+	if partSize < MinPartSize {
+		partSize = MinPartSize
+	}
+	progress := MultipartProgress{TotalBytes: size}
+	if size > 0 {
+		progress.TotalParts = int32((size + partSize - 1) / partSize)
+	}
+	// 已知大小时按上传后的用量检查配额；流式上传的大小未知（0），只能检查当前用量是否已达到配额
+	if err := s.checkQuota(ctx, bucket, size); err != nil {
+		return "", err
+	}
+
+	input := &s3.CreateMultipartUploadInput{
+		Bucket:   aws.String(bucket),
+		Key:      aws.String(key),
+		Metadata: opts.Metadata,
+	}
+	// 分段上传开始前无法检测内容，只按规则纠正声明的类型或按扩展名推断
+	if contentType := s.CorrectContentType(opts.ContentType, key); contentType != "" {
+		input.ContentType = aws.String(contentType)
+	}
+
+	created, err := s.client.CreateMultipartUpload(ctx, input)
+	if err != nil {
+		return "", err
+	}
+	uploadID := created.UploadId
+
+	abort := func(cause error) (string, error) {
+		abortCtx, cancel := context.WithTimeout(context.Background(), abortTimeout)
+		defer cancel()
+		if _, err := s.client.AbortMultipartUpload(abortCtx, &s3.AbortMultipartUploadInput{
+			Bucket:   aws.String(bucket),
+			Key:      aws.String(key),
+			UploadId: uploadID,
+		}); err != nil {
+			return "", errors.Join(cause, err)
+		}
+		return "", cause
+	}
+
+	var parts []types.CompletedPart
+	var uploaded int64
+	buf := make([]byte, partSize)
+	for partNumber := int32(1); ; partNumber++ {
+		n, readErr := io.ReadFull(body, buf)
+		if readErr != nil && readErr != io.EOF && readErr != io.ErrUnexpectedEOF {
+			return abort(readErr)
+		}
+		// 流结束且已有分段时无需再上传空分段
+		if n == 0 && len(parts) > 0 {
+			break
+		}
+
+		progress.PartNumber = partNumber
+		if onProgress != nil {
+			onProgress(progress)
+		}
+
+		output, err := s.client.UploadPart(ctx, &s3.UploadPartInput{
+			Bucket:        aws.String(bucket),
+			Key:           aws.String(key),
+			UploadId:      uploadID,
+			PartNumber:    aws.Int32(partNumber),
+			Body:          bytes.NewReader(buf[:n]),
+			ContentLength: aws.Int64(int64(n)),
+		})
+		if err != nil {
+			return abort(err)
+		}
+		parts = append(parts, types.CompletedPart{
+			ETag:       output.ETag,
+			PartNumber: aws.Int32(partNumber),
+		})
+
+		uploaded += int64(n)
+		progress.PartsCompleted++
+		progress.BytesCompleted = uploaded
+		if onProgress != nil {
+			onProgress(progress)
+		}
+
+		if readErr != nil {
+			break
+		}
+	}
+
+	completed, err := s.client.CompleteMultipartUpload(ctx, &s3.CompleteMultipartUploadInput{
+		Bucket:          aws.String(bucket),
+		Key:             aws.String(key),
+		UploadId:        uploadID,
+		MultipartUpload: &types.CompletedMultipartUpload{Parts: parts},
+	})
+	if err != nil {
+		return abort(err)
+	}
+
+	s.addUsage(bucket, uploaded)
+	if s.index != nil {
+		s.index.put(bucket, key, opts.Metadata)
+	}
+
+	return aws.ToString(completed.ETag), nil
+}
+
+// UploadMultipart 以分段上传方式流式写入大对象
+// 按partSize读取body（小于MinPartSize时使用MinPartSize），任一分段失败时中止分段上传
+// 参数:
+//
+//	ctx: 上下文
+//	bucket: 存储桶名称（为空时使用默认存储桶）
+//	key: 文件键
+//	body: 文件内容
+//	partSize: 分段大小
+//	opts: 上传选项（只使用ContentType和Metadata）
+//
+// 返回值:
+//
+//	error: 错误信息
+func (s *Service) UploadMultipart(ctx context.Context, bucket, key string, body io.Reader, partSize int64, opts UploadOptions) error {
+	_, err := s.multipartUpload(ctx, bucket, key, body, 0, partSize, nil, opts)
+	return err
+}
+
+// UploadMultipartWithProgress 以分段上传方式写入大对象，并在每个分段开始和完成时回调详细进度
+// 参数:
+//
+//	ctx: 上下文
+//	bucket: 存储桶名称（为空时使用默认存储桶）
+//	key: 文件键
+//	body: 文件内容
+//	size: 对象总大小（未知时为0）
+//	partSize: 分段大小
+//	onProgress: 进度回调（在上传所在的协程中同步调用，可为nil）
+//	opts: 上传选项（只使用ContentType和Metadata）
+//
+// 返回值:
+//
+//	string: 完成后对象的ETag
+//	error: 错误信息
+func (s *Service) UploadMultipartWithProgress(ctx context.Context, bucket, key string, body io.Reader, size, partSize int64, onProgress func(MultipartProgress), opts UploadOptions) (string, error) {
+	return s.multipartUpload(ctx, bucket, key, body, size, partSize, onProgress, opts)
+}
+
+// UploadWithProgress 以分段上传方式流式写入对象，并在每个分段完成后回调进度
+// 参数:
+//
+//	ctx: 上下文
+//	bucket: 存储桶名称（为空时使用默认存储桶）
+//	key: 文件键
+//	body: 文件内容（读取出错时上传会被中止）
+//	partSize: 分段大小
+//	onProgress: 进度回调，参数为已上传的累计字节数
+//
+// 返回值:
+//
+//	string: 完成后对象的ETag
+//	error: 错误信息
+func (s *Service) UploadWithProgress(ctx context.Context, bucket, key string, body io.Reader, partSize int64, onProgress func(uploaded int64)) (string, error) {
+	return s.multipartUpload(ctx, bucket, key, body, 0, partSize, func(p MultipartProgress) {
+		if onProgress != nil && p.PartsCompleted == p.PartNumber {
+			onProgress(p.BytesCompleted)
+		}
+	}, UploadOptions{})
+}
@@ -0,0 +1,132 @@
+// Package s3 提供S3服务相关功能
+// 作者: KO
+// 创建时间: 2026-10-15
+// 修改时间: 2026-10-15
+package s3
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+)
+
+// maxNDJSONLineSize 单行NDJSON记录的最大长度
+const maxNDJSONLineSize = 1024 * 1024
+
+// errInvalidJSON 行内容不是合法的JSON
+var errInvalidJSON = errors.New("line is not valid JSON")
+
+// NDJSONLineError NDJSON数据中存在无法接受的行
+type NDJSONLineError struct {
+	Line int   // 行号（从1开始）
+	Err  error // 具体错误
+}
+
+// Error 实现error接口
+func (e *NDJSONLineError) Error() string {
+	return fmt.Sprintf("invalid NDJSON at line %d: %v", e.Line, e.Err)
+}
+
+// Unwrap 返回具体错误
+func (e *NDJSONLineError) Unwrap() error {
+	return e.Err
+}
+
+// AppendNDJSON 将流式NDJSON数据按批写入S3对象
+// S3不支持追加写入，因此记录先写入本地临时文件，达到大小或时间阈值（或数据结束）时
+// 再以单次PutObject写入一个按日期分区的对象：<prefix>YYYY/MM/DD/<时间戳>-<随机串>.ndjson
+// 参数:
+//
+//	ctx: 上下文
+//	bucket: 存储桶名称（为空时使用默认存储桶）
+//	prefix: 键前缀
+//	body: NDJSON数据流
+//	maxBytes: 单个对象的最大字节数（小于等于0时不按大小切分）
+//	maxAge: 单个对象覆盖的最长时间（小于等于0时不按时间切分）
+//
+// 返回值:
+//
+//	[]string: 已写入的对象键（出错时包含出错前已写入的对象）
+//	error: 错误信息，存在无效行时为*NDJSONLineError
+func (s *Service) AppendNDJSON(ctx context.Context, bucket, prefix string, body io.Reader, maxBytes int64, maxAge time.Duration) ([]string, error) {
+	spill, err := os.CreateTemp("", "ndjson-*")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(spill.Name())
+	defer spill.Close()
+
+	var keys []string
+	var size int64
+	started := time.Now()
+
+	flush := func() error {
+		if size == 0 {
+			return nil
+		}
+
+		// SectionReader按偏移读取，可Seek，SDK签名时能先计算内容的哈希
+		key := fmt.Sprintf("%s%s/%s-%s.ndjson", prefix, started.UTC().Format("2006/01/02"),
+			started.UTC().Format("20060102T150405Z"), randomHex(4))
+		if err := s.UploadStream(ctx, bucket, key, io.NewSectionReader(spill, 0, size), size, UploadOptions{ContentType: "application/x-ndjson"}); err != nil {
+			return err
+		}
+		keys = append(keys, key)
+
+		if err := spill.Truncate(0); err != nil {
+			return err
+		}
+		if _, err := spill.Seek(0, io.SeekStart); err != nil {
+			return err
+		}
+		size = 0
+		return nil
+	}
+
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 64*1024), maxNDJSONLineSize)
+	line := 0
+	for scanner.Scan() {
+		line++
+		record := bytes.TrimSpace(scanner.Bytes())
+		if len(record) == 0 {
+			continue
+		}
+		if !json.Valid(record) {
+			return keys, &NDJSONLineError{Line: line, Err: errInvalidJSON}
+		}
+
+		// 追加本行会超出大小阈值或已超过时间阈值时，先切分出一个对象
+		if size > 0 && ((maxBytes > 0 && size+int64(len(record))+1 > maxBytes) ||
+			(maxAge > 0 && time.Since(started) >= maxAge)) {
+			if err := flush(); err != nil {
+				return keys, err
+			}
+			started = time.Now()
+		}
+
+		n, err := spill.Write(append(record, '\n'))
+		if err != nil {
+			return keys, err
+		}
+		size += int64(n)
+	}
+	if err := scanner.Err(); err != nil {
+		if errors.Is(err, bufio.ErrTooLong) {
+			return keys, &NDJSONLineError{Line: line + 1, Err: err}
+		}
+		return keys, err
+	}
+
+	if err := flush(); err != nil {
+		return keys, err
+	}
+
+	return keys, nil
+}
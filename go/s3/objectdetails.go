@@ -0,0 +1,209 @@
+// Package s3 提供S3服务相关功能
+// 作者: KO
+// 创建时间: 2026-10-15
+// 修改时间: 2026-10-15
+package s3
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// ObjectDetails 对象的全部信息，供详情页一次获取
+// HEAD之外的各部分单独获取，某部分失败时该字段为空，原因记录在Errors中
+type ObjectDetails struct {
+	Key                  string            `json:"key"`                            // 文件键
+	Size                 int64             `json:"size"`                           // 文件大小（字节）
+	ContentType          string            `json:"contentType"`                    // 内容类型
+	ETag                 string            `json:"etag"`                           // 实体标签
+	LastModified         *time.Time        `json:"lastModified"`                   // 最后修改时间
+	StorageClass         string            `json:"storageClass"`                   // 存储类别（HEAD未返回时为STANDARD）
+	ServerSideEncryption string            `json:"serverSideEncryption,omitempty"` // 服务端加密方式
+	KMSKeyID             string            `json:"kmsKeyId,omitempty"`             // SSE-KMS使用的密钥ID
+	VersionID            string            `json:"versionId,omitempty"`            // 当前版本ID
+	Metadata             map[string]string `json:"metadata"`                       // 用户自定义元数据
+	Tags                 map[string]string `json:"tags,omitempty"`                 // 对象标签
+	ACL                  []ACLGrant        `json:"acl,omitempty"`                  // 访问控制列表
+	Retention            *ObjectRetention  `json:"retention,omitempty"`            // 对象锁定保留设置（未配置时为空）
+	LegalHold            string            `json:"legalHold,omitempty"`            // 合法保留状态（ON、OFF，未配置时为空）
+	Versions             []ObjectVersion   `json:"versions,omitempty"`             // 全部版本（从新到旧，存储桶未启用版本控制时只有一个null版本）
+	Errors               map[string]string `json:"errors,omitempty"`               // 获取失败的部分及原因
+}
+
+// ACLGrant 访问控制列表中的一项授权
+type ACLGrant struct {
+	Grantee    string `json:"grantee"`    // 被授权者（ID、URI或邮箱）
+	Permission string `json:"permission"` // 权限
+}
+
+// ObjectRetention 对象锁定的保留设置
+type ObjectRetention struct {
+	Mode            string     `json:"mode"`            // GOVERNANCE或COMPLIANCE
+	RetainUntilDate *time.Time `json:"retainUntilDate"` // 保留截止时间
+}
+
+// isNotApplicable 判断错误是否表示该部分对此对象不适用（未配置对象锁定或后端不支持），此时不作为错误报告
+// 参数:
+//
+//	err: 错误
+//
+// 返回值:
+//
+//	bool: 是否不适用
+func isNotApplicable(err error) bool {
+	switch errorCode(err) {
+	case "NoSuchObjectLockConfiguration", "ObjectLockConfigurationNotFoundError", "InvalidRequest", "NotImplemented":
+		return true
+	}
+	return false
+}
+
+// GetObjectDetails 汇总对象的HEAD信息、用户元数据、标签、ACL、对象锁定设置和版本
+// 先HEAD确认对象存在，其余部分（标签、ACL、保留设置、合法保留、版本）并发获取；
+// 某部分失败（如没有读取ACL的权限）不影响其他部分，失败原因以部分名称为键记录在Errors中
+// 参数:
+//
+//	ctx: 上下文
+//	bucket: 存储桶名称（为空时使用默认存储桶）
+//	key: 文件键
+//
+// 返回值:
+//
+//	*ObjectDetails: 对象信息
+//	error: HEAD失败时的错误信息，对象不存在时可用IsNotFound判断
+func (s *Service) GetObjectDetails(ctx context.Context, bucket, key string) (*ObjectDetails, error) {
+	if bucket == "" {
+		bucket = s.defaultBucket
+	}
+
+	head, err := s.client.HeadObject(ctx, &s3.HeadObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	details := &ObjectDetails{
+		Key:                  key,
+		Size:                 aws.ToInt64(head.ContentLength),
+		ContentType:          aws.ToString(head.ContentType),
+		ETag:                 aws.ToString(head.ETag),
+		LastModified:         head.LastModified,
+		StorageClass:         string(head.StorageClass),
+		ServerSideEncryption: string(head.ServerSideEncryption),
+		KMSKeyID:             aws.ToString(head.SSEKMSKeyId),
+		VersionID:            aws.ToString(head.VersionId),
+		Metadata:             head.Metadata,
+		Errors:               map[string]string{},
+	}
+	// HEAD只在非标准存储类别时返回x-amz-storage-class
+	if details.StorageClass == "" {
+		details.StorageClass = string(types.StorageClassStandard)
+	}
+	if details.Metadata == nil {
+		details.Metadata = map[string]string{}
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	section := func(name string, fetch func() error) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := fetch(); err != nil && !isNotApplicable(err) {
+				mu.Lock()
+				details.Errors[name] = err.Error()
+				mu.Unlock()
+			}
+		}()
+	}
+
+	// 各部分只写入自己的字段，互不冲突
+	section("tags", func() error {
+		tags, err := s.GetObjectTags(ctx, bucket, key)
+		details.Tags = tags
+		return err
+	})
+	section("acl", func() error {
+		output, err := s.client.GetObjectAcl(ctx, &s3.GetObjectAclInput{Bucket: aws.String(bucket), Key: aws.String(key)})
+		if err != nil {
+			return err
+		}
+		details.ACL = make([]ACLGrant, 0, len(output.Grants))
+		for _, grant := range output.Grants {
+			details.ACL = append(details.ACL, ACLGrant{
+				Grantee:    granteeName(grant.Grantee),
+				Permission: string(grant.Permission),
+			})
+		}
+		return nil
+	})
+	section("retention", func() error {
+		output, err := s.client.GetObjectRetention(ctx, &s3.GetObjectRetentionInput{Bucket: aws.String(bucket), Key: aws.String(key)})
+		if err != nil {
+			return err
+		}
+		if output.Retention != nil {
+			details.Retention = &ObjectRetention{
+				Mode:            string(output.Retention.Mode),
+				RetainUntilDate: output.Retention.RetainUntilDate,
+			}
+		}
+		return nil
+	})
+	section("legalHold", func() error {
+		output, err := s.client.GetObjectLegalHold(ctx, &s3.GetObjectLegalHoldInput{Bucket: aws.String(bucket), Key: aws.String(key)})
+		if err != nil {
+			return err
+		}
+		if output.LegalHold != nil {
+			details.LegalHold = string(output.LegalHold.Status)
+		}
+		return nil
+	})
+	section("versions", func() error {
+		// 以键为前缀列举会包含以该键开头的其他对象，只取键完全相同的一组
+		files, err := s.ListFileVersions(ctx, bucket, key)
+		if err != nil {
+			return err
+		}
+		for _, file := range files {
+			if file.Key == key {
+				details.Versions = file.Versions
+			}
+		}
+		return nil
+	})
+	wg.Wait()
+
+	if len(details.Errors) == 0 {
+		details.Errors = nil
+	}
+	return details, nil
+}
+
+// granteeName 返回被授权者的可读标识
+// 参数:
+//
+//	grantee: 被授权者
+//
+// 返回值:
+//
+//	string: 显示名称、ID、URI或邮箱中第一个非空的值
+func granteeName(grantee *types.Grantee) string {
+	if grantee == nil {
+		return ""
+	}
+	for _, value := range []*string{grantee.DisplayName, grantee.ID, grantee.URI, grantee.EmailAddress} {
+		if v := aws.ToString(value); v != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,414 @@
+// Package s3 提供S3服务相关功能
+// 作者: KO
+// 创建时间: 2026-10-15
+// 修改时间: 2026-10-15
+package s3
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// maxPartCount S3分段上传允许的最大分段数
+const maxPartCount = 10000
+
+// maxPartSize S3分段上传中单个分段的最大大小
+const maxPartSize = 5 * 1024 * 1024 * 1024
+
+var (
+	// ErrSessionNotFound 分段写入会话不存在（可能已完成、已中止或由其他实例创建）
+	ErrSessionNotFound = errors.New("upload session not found")
+	// ErrInvalidSession 创建分段写入会话的参数无效
+	ErrInvalidSession = errors.New("invalid upload session")
+	// ErrInvalidPart 分段号、偏移或分段大小与会话的布局不符
+	ErrInvalidPart = errors.New("invalid part")
+	// ErrSessionIncomplete 仍有分段未上传，无法完成会话
+	ErrSessionIncomplete = errors.New("upload session is incomplete")
+)
+
+// PartSession 按偏移写入的分段上传会话
+// 对象按固定分段大小切分：第N段覆盖[(N-1)*PartSize, N*PartSize)，最后一段为剩余字节；
+// 客户端可以按任意顺序上传分段，重复上传同一分段会覆盖之前的内容
+type PartSession struct {
+	ID        string `json:"sessionId"` // 会话ID
+	Bucket    string `json:"bucket"`    // 存储桶名称
+	Key       string `json:"key"`       // 文件键
+	TotalSize int64  `json:"totalSize"` // 对象总大小（字节）
+	PartSize  int64  `json:"partSize"`  // 分段大小（字节）
+	PartCount int32  `json:"partCount"` // 分段总数
+
+	uploadID string           // S3分段上传ID
+	mu       sync.Mutex       // 保护parts和lastUsed
+	parts    map[int32]string // 已上传分段的ETag
+	lastUsed time.Time        // 创建或最后一次上传分段的时间
+}
+
+// PartNumberForOffset 计算偏移所在的分段号，偏移必须与分段边界对齐
+// 参数:
+//
+//	offset: 字节偏移
+//
+// 返回值:
+//
+//	int32: 分段号（从1开始）
+//	error: 偏移越界或未对齐时返回包装了ErrInvalidPart的错误
+func (p *PartSession) PartNumberForOffset(offset int64) (int32, error) {
+	if offset < 0 || offset >= p.TotalSize {
+		return 0, fmt.Errorf("%w: offset %d is outside the object (size %d)", ErrInvalidPart, offset, p.TotalSize)
+	}
+	if offset%p.PartSize != 0 {
+		return 0, fmt.Errorf("%w: offset %d is not aligned to the part size %d", ErrInvalidPart, offset, p.PartSize)
+	}
+	return int32(offset/p.PartSize) + 1, nil
+}
+
+// partLength 返回指定分段应有的字节数
+// 参数:
+//
+//	number: 分段号
+//
+// 返回值:
+//
+//	int64: 分段大小
+func (p *PartSession) partLength(number int32) int64 {
+	if number == p.PartCount {
+		return p.TotalSize - int64(p.PartCount-1)*p.PartSize
+	}
+	return p.PartSize
+}
+
+// missingParts 返回尚未上传的分段号
+// 返回值:
+//
+//	[]int32: 缺失的分段号（升序）
+func (p *PartSession) missingParts() []int32 {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var missing []int32
+	for number := int32(1); number <= p.PartCount; number++ {
+		if _, ok := p.parts[number]; !ok {
+			missing = append(missing, number)
+		}
+	}
+	return missing
+}
+
+// idleSince 返回会话最后一次活动至今的时间
+// 参数:
+//
+//	now: 当前时间
+//
+// 返回值:
+//
+//	time.Duration: 空闲时间
+func (p *PartSession) idleSince(now time.Time) time.Duration {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return now.Sub(p.lastUsed)
+}
+
+// partSessions 当前实例上的分段写入会话
+// 会话只保存在内存中，服务重启后丢失；对应的未完成分段上传需要通过中止或生命周期规则清理。
+// 超过part_session_ttl未活动的会话由后台任务中止
+type partSessions struct {
+	mu       sync.Mutex
+	sessions map[string]*PartSession
+}
+
+// get 查找会话
+// 参数:
+//
+//	id: 会话ID
+//
+// 返回值:
+//
+//	*PartSession: 会话
+//	error: 不存在时返回ErrSessionNotFound
+func (ps *partSessions) get(id string) (*PartSession, error) {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+
+	session, ok := ps.sessions[id]
+	if !ok {
+		return nil, ErrSessionNotFound
+	}
+	return session, nil
+}
+
+// CreatePartSession 创建按偏移写入的分段上传会话
+// 参数:
+//
+//	ctx: 上下文
+//	bucket: 存储桶名称（为空时使用默认存储桶）
+//	key: 文件键
+//	totalSize: 对象总大小（字节）
+//	partSize: 分段大小（字节，多于一个分段时不得小于MinPartSize，不得大于5GiB）
+//
+// 返回值:
+//
+//	*PartSession: 会话
+//	error: 错误信息
+func (s *Service) CreatePartSession(ctx context.Context, bucket, key string, totalSize, partSize int64) (*PartSession, error) {
+	if bucket == "" {
+		bucket = s.defaultBucket
+	}
+	if key == "" || totalSize <= 0 || partSize <= 0 {
+		return nil, fmt.Errorf("%w: key, totalSize and partSize are required", ErrInvalidSession)
+	}
+
+	partCount := (totalSize + partSize - 1) / partSize
+	if partCount > 1 && partSize < MinPartSize {
+		return nil, fmt.Errorf("%w: partSize must be at least %d bytes", ErrInvalidSession, MinPartSize)
+	}
+	if min(partSize, totalSize) > maxPartSize {
+		return nil, fmt.Errorf("%w: partSize must be at most %d bytes", ErrInvalidSession, int64(maxPartSize))
+	}
+	if partCount > maxPartCount {
+		return nil, fmt.Errorf("%w: %d parts exceed the maximum of %d", ErrInvalidSession, partCount, maxPartCount)
+	}
+	if err := s.checkQuota(ctx, bucket, totalSize); err != nil {
+		return nil, err
+	}
+
+	input := &s3.CreateMultipartUploadInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	}
+	if contentType := s.CorrectContentType("", key); contentType != "" {
+		input.ContentType = aws.String(contentType)
+	}
+
+	created, err := s.client.CreateMultipartUpload(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+
+	session := &PartSession{
+		ID:        randomHex(16),
+		Bucket:    bucket,
+		Key:       key,
+		TotalSize: totalSize,
+		PartSize:  partSize,
+		PartCount: int32(partCount),
+		uploadID:  aws.ToString(created.UploadId),
+		parts:     make(map[int32]string),
+		lastUsed:  time.Now(),
+	}
+
+	s.sessions.mu.Lock()
+	s.sessions.sessions[session.ID] = session
+	s.sessions.mu.Unlock()
+
+	return session, nil
+}
+
+// GetPartSession 查找分段写入会话
+// 参数:
+//
+//	id: 会话ID
+//
+// 返回值:
+//
+//	*PartSession: 会话
+//	error: 不存在时返回ErrSessionNotFound
+func (s *Service) GetPartSession(id string) (*PartSession, error) {
+	return s.sessions.get(id)
+}
+
+// UploadSessionPart 上传会话中的一个分段
+// 参数:
+//
+//	ctx: 上下文
+//	id: 会话ID
+//	number: 分段号（从1开始）
+//	body: 分段内容
+//	size: 分段内容的字节数，必须等于该分段应有的大小；未知（chunked）时为-1，先读取内容确定长度（不可Seek的内容总是先读取一遍）
+//
+// 返回值:
+//
+//	error: 错误信息
+func (s *Service) UploadSessionPart(ctx context.Context, id string, number int32, body io.Reader, size int64) error {
+	session, err := s.sessions.get(id)
+	if err != nil {
+		return err
+	}
+	if number < 1 || number > session.PartCount {
+		return fmt.Errorf("%w: part number %d is outside 1-%d", ErrInvalidPart, number, session.PartCount)
+	}
+	expected := session.partLength(number)
+	if size >= 0 && size != expected {
+		return fmt.Errorf("%w: part %d must be %d bytes, got %d", ErrInvalidPart, number, expected, size)
+	}
+	// SDK对HTTP端点签名时需要可Seek的内容，分段不超过partSize，先读取一遍的代价有限；
+	// 最多多读一个字节，长度未知时在上传之前就能拒绝过长或过短的分段
+	if _, seekable := body.(io.ReadSeeker); !seekable || size < 0 {
+		spilled, n, cleanup, err := spillBody(io.LimitReader(body, expected+1), s.cfg.ChunkedMemoryLimit)
+		defer cleanup()
+		if err != nil {
+			return fmt.Errorf("failed to read part body: %w", err)
+		}
+		if n > expected {
+			return fmt.Errorf("%w: part %d must be %d bytes, body is longer", ErrInvalidPart, number, expected)
+		}
+		body, size = spilled, n
+	}
+	if size != expected {
+		return fmt.Errorf("%w: part %d must be %d bytes, got %d", ErrInvalidPart, number, expected, size)
+	}
+
+	output, err := s.client.UploadPart(ctx, &s3.UploadPartInput{
+		Bucket:        aws.String(session.Bucket),
+		Key:           aws.String(session.Key),
+		UploadId:      aws.String(session.uploadID),
+		PartNumber:    aws.Int32(number),
+		Body:          body,
+		ContentLength: aws.Int64(size),
+	})
+	if err != nil {
+		return err
+	}
+
+	session.mu.Lock()
+	session.parts[number] = aws.ToString(output.ETag)
+	session.lastUsed = time.Now()
+	session.mu.Unlock()
+
+	return nil
+}
+
+// CompletePartSession 校验全部分段均已上传后完成会话，合并为最终对象
+// 参数:
+//
+//	ctx: 上下文
+//	id: 会话ID
+//
+// 返回值:
+//
+//	string: 最终对象的ETag
+//	error: 有分段缺失时返回包装了ErrSessionIncomplete的错误
+func (s *Service) CompletePartSession(ctx context.Context, id string) (string, error) {
+	session, err := s.sessions.get(id)
+	if err != nil {
+		return "", err
+	}
+	if missing := session.missingParts(); len(missing) > 0 {
+		return "", fmt.Errorf("%w: missing parts %v", ErrSessionIncomplete, missing)
+	}
+
+	session.mu.Lock()
+	parts := make([]types.CompletedPart, 0, len(session.parts))
+	for number, etag := range session.parts {
+		parts = append(parts, types.CompletedPart{
+			ETag:       aws.String(etag),
+			PartNumber: aws.Int32(number),
+		})
+	}
+	session.mu.Unlock()
+	sort.Slice(parts, func(i, j int) bool {
+		return aws.ToInt32(parts[i].PartNumber) < aws.ToInt32(parts[j].PartNumber)
+	})
+
+	completed, err := s.client.CompleteMultipartUpload(ctx, &s3.CompleteMultipartUploadInput{
+		Bucket:          aws.String(session.Bucket),
+		Key:             aws.String(session.Key),
+		UploadId:        aws.String(session.uploadID),
+		MultipartUpload: &types.CompletedMultipartUpload{Parts: parts},
+	})
+	if err != nil {
+		return "", err
+	}
+
+	s.sessions.mu.Lock()
+	delete(s.sessions.sessions, id)
+	s.sessions.mu.Unlock()
+
+	s.addUsage(session.Bucket, session.TotalSize)
+	if s.index != nil {
+		s.index.put(session.Bucket, session.Key, nil)
+	}
+
+	return aws.ToString(completed.ETag), nil
+}
+
+// AbortPartSession 中止会话并丢弃已上传的分段
+// 参数:
+//
+//	ctx: 上下文
+//	id: 会话ID
+//
+// 返回值:
+//
+//	error: 错误信息
+func (s *Service) AbortPartSession(ctx context.Context, id string) error {
+	session, err := s.sessions.get(id)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.client.AbortMultipartUpload(ctx, &s3.AbortMultipartUploadInput{
+		Bucket:   aws.String(session.Bucket),
+		Key:      aws.String(session.Key),
+		UploadId: aws.String(session.uploadID),
+	})
+	if err != nil {
+		return err
+	}
+
+	s.sessions.mu.Lock()
+	delete(s.sessions.sessions, id)
+	s.sessions.mu.Unlock()
+
+	return nil
+}
+
+// expirePartSessions 定期中止超过part_session_ttl未活动的会话，上下文取消时停止
+// 中止失败的会话保留在内存中，下次检查时重试
+// 参数:
+//
+//	ctx: 上下文
+func (s *Service) expirePartSessions(ctx context.Context) {
+	ttl := s.cfg.PartSessionTTL
+	interval := time.Minute
+	if ttl < interval {
+		interval = ttl
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		now := time.Now()
+		var expired []string
+		s.sessions.mu.Lock()
+		for id, session := range s.sessions.sessions {
+			if session.idleSince(now) > ttl {
+				expired = append(expired, id)
+			}
+		}
+		s.sessions.mu.Unlock()
+
+		for _, id := range expired {
+			abortCtx, cancel := context.WithTimeout(context.Background(), abortTimeout)
+			if err := s.AbortPartSession(abortCtx, id); err != nil && !errors.Is(err, ErrSessionNotFound) {
+				log.Printf("Failed to abort expired upload session %s: %v", id, err)
+			}
+			cancel()
+		}
+	}
+}
@@ -0,0 +1,82 @@
+// Package s3 提供S3服务相关功能
+// 作者: KO
+// 创建时间: 2026-10-15
+// 修改时间: 2026-10-15
+package s3
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// precompressedSuffixes 内容编码到预压缩变体键后缀的映射
+var precompressedSuffixes = map[string]string{
+	"br":   ".br",
+	"gzip": ".gz",
+}
+
+// PrecompressedVariant 对象的预压缩变体
+type PrecompressedVariant struct {
+	Key         string // 变体的文件键（原键加.br或.gz后缀）
+	Encoding    string // 内容编码（br或gzip）
+	ContentType string // 原对象的内容类型
+}
+
+// FindPrecompressed 按客户端可接受的编码顺序查找对象的预压缩变体
+// 依次对key.br、key.gz等变体执行HEAD，找到第一个存在的变体后再HEAD原对象获取其内容类型；
+// 没有变体或原对象不存在时返回nil，调用方应回退到原对象
+// 参数:
+//
+//	ctx: 上下文
+//	bucket: 存储桶名称（为空时使用默认存储桶）
+//	key: 原对象的文件键
+//	encodings: 客户端可接受的内容编码（按优先级排序，不支持的编码会被忽略）
+//
+// 返回值:
+//
+//	*PrecompressedVariant: 找到的变体（未找到时为nil）
+//	error: 错误信息
+func (s *Service) FindPrecompressed(ctx context.Context, bucket, key string, encodings []string) (*PrecompressedVariant, error) {
+	if bucket == "" {
+		bucket = s.defaultBucket
+	}
+
+	for _, encoding := range encodings {
+		suffix, ok := precompressedSuffixes[encoding]
+		if !ok {
+			continue
+		}
+
+		_, err := s.client.HeadObject(ctx, &s3.HeadObjectInput{
+			Bucket: aws.String(bucket),
+			Key:    aws.String(key + suffix),
+		})
+		if IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		base, err := s.client.HeadObject(ctx, &s3.HeadObjectInput{
+			Bucket: aws.String(bucket),
+			Key:    aws.String(key),
+		})
+		if IsNotFound(err) {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		return &PrecompressedVariant{
+			Key:         key + suffix,
+			Encoding:    encoding,
+			ContentType: aws.ToString(base.ContentType),
+		}, nil
+	}
+
+	return nil, nil
+}
@@ -0,0 +1,134 @@
+// Package s3 提供S3服务相关功能
+// 作者: KO
+// 创建时间: 2026-10-15
+// 修改时间: 2026-10-15
+package s3
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+var (
+	// ErrInvalidPresignConstraint 预签名URL的访问限制参数格式无效
+	ErrInvalidPresignConstraint = errors.New("invalid presign constraint")
+	// ErrPresignConstraintUnsupported 签名机制无法在预签名URL中嵌入该访问限制
+	ErrPresignConstraintUnsupported = errors.New("presign constraint is not supported")
+)
+
+// PresignConstraints 预签名URL的附加访问限制
+type PresignConstraints struct {
+	SourceIP string // 允许访问的来源IP网段（CIDR格式，如203.0.113.0/24）
+	Referer  string // 允许访问的Referer
+}
+
+// CheckPresignConstraints 校验预签名URL的附加访问限制能否被满足
+// SigV4查询字符串签名只覆盖请求方法、路径、查询参数和签名的请求头，
+// 无法像POST Policy或存储桶策略那样附加条件，因此来源IP和Referer限制
+// 在AWS和MinIO的预签名URL中都无法嵌入；此时返回ErrPresignConstraintUnsupported，
+// 而不是签发一个看似受限、实则不受限的URL。需要此类限制时应在存储桶策略中使用
+// aws:SourceIp / aws:Referer条件。
+// 参数:
+//
+//	c: 访问限制
+//
+// 返回值:
+//
+//	error: 格式无效时返回包装了ErrInvalidPresignConstraint的错误，
+//	       无法嵌入时返回包装了ErrPresignConstraintUnsupported的错误
+func (s *Service) CheckPresignConstraints(c PresignConstraints) error {
+	if c.SourceIP != "" {
+		if _, _, err := net.ParseCIDR(c.SourceIP); err != nil {
+			return fmt.Errorf("%w: sourceIp must be a CIDR block: %s", ErrInvalidPresignConstraint, c.SourceIP)
+		}
+	}
+
+	if c.SourceIP != "" || c.Referer != "" {
+		return fmt.Errorf("%w: SigV4 presigned URLs cannot embed source IP or referer conditions, use a bucket policy with aws:SourceIp/aws:Referer instead", ErrPresignConstraintUnsupported)
+	}
+
+	return nil
+}
+
+// PresignedUpload 预签名上传请求
+type PresignedUpload struct {
+	URL     string            `json:"url"`     // 预签名URL
+	Method  string            `json:"method"`  // 请求方法（PUT）
+	Headers map[string]string `json:"headers"` // 客户端上传时必须携带的请求头（已参与签名）
+}
+
+// PresignUpload 生成文件上传的预签名请求
+// contentType不为空时参与签名，客户端上传时必须携带相同的Content-Type
+// 参数:
+//
+//	ctx: 上下文
+//	bucket: 存储桶名称（为空时使用默认存储桶）
+//	key: 文件键
+//	contentType: 内容类型（可为空）
+//	expiry: URL有效期
+//
+// 返回值:
+//
+//	*PresignedUpload: 预签名上传请求
+//	error: 错误信息
+func (s *Service) PresignUpload(ctx context.Context, bucket, key, contentType string, expiry time.Duration) (*PresignedUpload, error) {
+	if bucket == "" {
+		bucket = s.defaultBucket
+	}
+
+	input := &s3.PutObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	}
+	if contentType != "" {
+		input.ContentType = aws.String(contentType)
+	}
+
+	request, err := s.presignClient.PresignPutObject(ctx, input, s3.WithPresignExpires(expiry))
+	if err != nil {
+		return nil, err
+	}
+
+	// Host由客户端根据URL自动设置，不需要显式携带
+	headers := make(map[string]string, len(request.SignedHeader))
+	for name, values := range request.SignedHeader {
+		name = http.CanonicalHeaderKey(name)
+		if name == "Host" || len(values) == 0 {
+			continue
+		}
+		headers[name] = values[0]
+	}
+
+	return &PresignedUpload{
+		URL:     request.URL,
+		Method:  request.Method,
+		Headers: headers,
+	}, nil
+}
+
+// PresignUploadURL 生成文件上传的预签名URL
+// 参数:
+//
+//	ctx: 上下文
+//	bucket: 存储桶名称（为空时使用默认存储桶）
+//	key: 文件键
+//	expiry: URL有效期
+//
+// 返回值:
+//
+//	string: 预签名URL
+//	error: 错误信息
+func (s *Service) PresignUploadURL(ctx context.Context, bucket, key string, expiry time.Duration) (string, error) {
+	upload, err := s.PresignUpload(ctx, bucket, key, "", expiry)
+	if err != nil {
+		return "", err
+	}
+	return upload.URL, nil
+}
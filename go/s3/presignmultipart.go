@@ -0,0 +1,247 @@
+// Package s3 提供S3服务相关功能
+// 作者: KO
+// 创建时间: 2026-10-15
+// 修改时间: 2026-10-15
+package s3
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// DirectUpload 由客户端通过预签名URL直接上传分段的分段上传
+// 分段内容不经过本服务，服务只负责创建、签名各分段URL以及完成或中止上传
+type DirectUpload struct {
+	ID        string `json:"uploadId"`            // 上传ID
+	Bucket    string `json:"bucket"`              // 存储桶名称
+	Key       string `json:"key"`                 // 文件键
+	TotalSize int64  `json:"totalSize,omitempty"` // 客户端声明的对象总大小（字节，用于配额检查）
+
+	uploadID string // S3分段上传ID
+}
+
+// UploadedPart 客户端上传完成的分段
+type UploadedPart struct {
+	PartNumber int32  `json:"partNumber"` // 分段号（从1开始）
+	ETag       string `json:"etag"`       // 上传分段时S3返回的ETag响应头
+}
+
+// directUploads 当前实例上的直传分段上传
+// 与partSessions一样只保存在内存中，服务重启后需要通过生命周期规则清理未完成的分段上传
+type directUploads struct {
+	mu      sync.Mutex
+	uploads map[string]*DirectUpload
+}
+
+// get 查找直传分段上传
+// 参数:
+//
+//	id: 上传ID
+//
+// 返回值:
+//
+//	*DirectUpload: 直传分段上传
+//	error: 不存在时返回ErrSessionNotFound
+func (du *directUploads) get(id string) (*DirectUpload, error) {
+	du.mu.Lock()
+	defer du.mu.Unlock()
+
+	upload, ok := du.uploads[id]
+	if !ok {
+		return nil, ErrSessionNotFound
+	}
+	return upload, nil
+}
+
+// remove 删除直传分段上传
+// 参数:
+//
+//	id: 上传ID
+func (du *directUploads) remove(id string) {
+	du.mu.Lock()
+	delete(du.uploads, id)
+	du.mu.Unlock()
+}
+
+// CreateDirectUpload 创建由客户端直接上传分段的分段上传
+// 参数:
+//
+//	ctx: 上下文
+//	bucket: 存储桶名称（为空时使用默认存储桶）
+//	key: 文件键
+//	contentType: 内容类型（为空时按扩展名推断）
+//	totalSize: 对象总大小（字节，未知时为0，此时只检查当前用量是否已达到配额）
+//
+// 返回值:
+//
+//	*DirectUpload: 直传分段上传
+//	error: 错误信息
+func (s *Service) CreateDirectUpload(ctx context.Context, bucket, key, contentType string, totalSize int64) (*DirectUpload, error) {
+	if bucket == "" {
+		bucket = s.defaultBucket
+	}
+	if key == "" || totalSize < 0 {
+		return nil, fmt.Errorf("%w: key is required and totalSize must not be negative", ErrInvalidSession)
+	}
+	if err := s.checkQuota(ctx, bucket, totalSize); err != nil {
+		return nil, err
+	}
+
+	input := &s3.CreateMultipartUploadInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	}
+	if contentType = s.CorrectContentType(contentType, key); contentType != "" {
+		input.ContentType = aws.String(contentType)
+	}
+
+	created, err := s.client.CreateMultipartUpload(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+
+	upload := &DirectUpload{
+		ID:        randomHex(16),
+		Bucket:    bucket,
+		Key:       key,
+		TotalSize: totalSize,
+		uploadID:  aws.ToString(created.UploadId),
+	}
+
+	s.directUploads.mu.Lock()
+	s.directUploads.uploads[upload.ID] = upload
+	s.directUploads.mu.Unlock()
+
+	return upload, nil
+}
+
+// PresignDirectUploadPart 生成直传分段上传中一个分段的预签名PUT URL
+// 参数:
+//
+//	ctx: 上下文
+//	id: 上传ID
+//	number: 分段号（1-10000）
+//	expiry: URL有效期
+//
+// 返回值:
+//
+//	string: 预签名URL
+//	error: 上传不存在时返回ErrSessionNotFound，分段号越界时返回包装了ErrInvalidPart的错误
+func (s *Service) PresignDirectUploadPart(ctx context.Context, id string, number int32, expiry time.Duration) (string, error) {
+	upload, err := s.directUploads.get(id)
+	if err != nil {
+		return "", err
+	}
+	if number < 1 || number > maxPartCount {
+		return "", fmt.Errorf("%w: part number %d is outside 1-%d", ErrInvalidPart, number, maxPartCount)
+	}
+
+	request, err := s.presignClient.PresignUploadPart(ctx, &s3.UploadPartInput{
+		Bucket:     aws.String(upload.Bucket),
+		Key:        aws.String(upload.Key),
+		UploadId:   aws.String(upload.uploadID),
+		PartNumber: aws.Int32(number),
+	}, s3.WithPresignExpires(expiry))
+	if err != nil {
+		return "", err
+	}
+
+	return request.URL, nil
+}
+
+// CompleteDirectUpload 按客户端提交的分段ETag完成直传分段上传
+// 参数:
+//
+//	ctx: 上下文
+//	id: 上传ID
+//	parts: 已上传的分段（顺序不限）
+//
+// 返回值:
+//
+//	*DirectUpload: 已完成的直传分段上传
+//	string: 最终对象的ETag
+//	error: 分段列表为空、分段号重复或越界、ETag为空时返回包装了ErrInvalidPart的错误
+func (s *Service) CompleteDirectUpload(ctx context.Context, id string, parts []UploadedPart) (*DirectUpload, string, error) {
+	upload, err := s.directUploads.get(id)
+	if err != nil {
+		return nil, "", err
+	}
+	if len(parts) == 0 {
+		return nil, "", fmt.Errorf("%w: at least one part is required", ErrInvalidPart)
+	}
+
+	completedParts := make([]types.CompletedPart, 0, len(parts))
+	seen := make(map[int32]bool, len(parts))
+	for _, part := range parts {
+		if part.PartNumber < 1 || part.PartNumber > maxPartCount {
+			return nil, "", fmt.Errorf("%w: part number %d is outside 1-%d", ErrInvalidPart, part.PartNumber, maxPartCount)
+		}
+		if seen[part.PartNumber] {
+			return nil, "", fmt.Errorf("%w: part number %d is listed more than once", ErrInvalidPart, part.PartNumber)
+		}
+		if part.ETag == "" {
+			return nil, "", fmt.Errorf("%w: part %d has no etag", ErrInvalidPart, part.PartNumber)
+		}
+		seen[part.PartNumber] = true
+		completedParts = append(completedParts, types.CompletedPart{
+			ETag:       aws.String(part.ETag),
+			PartNumber: aws.Int32(part.PartNumber),
+		})
+	}
+	sort.Slice(completedParts, func(i, j int) bool {
+		return aws.ToInt32(completedParts[i].PartNumber) < aws.ToInt32(completedParts[j].PartNumber)
+	})
+
+	completed, err := s.client.CompleteMultipartUpload(ctx, &s3.CompleteMultipartUploadInput{
+		Bucket:          aws.String(upload.Bucket),
+		Key:             aws.String(upload.Key),
+		UploadId:        aws.String(upload.uploadID),
+		MultipartUpload: &types.CompletedMultipartUpload{Parts: completedParts},
+	})
+	if err != nil {
+		return nil, "", err
+	}
+
+	s.directUploads.remove(id)
+	s.addUsage(upload.Bucket, upload.TotalSize)
+	if s.index != nil {
+		s.index.put(upload.Bucket, upload.Key, nil)
+	}
+
+	return upload, aws.ToString(completed.ETag), nil
+}
+
+// AbortDirectUpload 中止直传分段上传并丢弃已上传的分段
+// 参数:
+//
+//	ctx: 上下文
+//	id: 上传ID
+//
+// 返回值:
+//
+//	error: 错误信息
+func (s *Service) AbortDirectUpload(ctx context.Context, id string) error {
+	upload, err := s.directUploads.get(id)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.client.AbortMultipartUpload(ctx, &s3.AbortMultipartUploadInput{
+		Bucket:   aws.String(upload.Bucket),
+		Key:      aws.String(upload.Key),
+		UploadId: aws.String(upload.uploadID),
+	})
+	if err != nil {
+		return err
+	}
+
+	s.directUploads.remove(id)
+	return nil
+}
@@ -0,0 +1,60 @@
+// Package s3 提供S3服务相关功能
+// 作者: KO
+// 创建时间: 2026-10-15
+// 修改时间: 2026-10-15
+package s3
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// parsePublicURLBase 解析并校验public_url_base
+// 参数:
+//
+//	base: 配置的公开URL前缀
+//
+// 返回值:
+//
+//	*url.URL: 解析后的URL（未配置时为nil）
+//	error: 错误信息
+func parsePublicURLBase(base string) (*url.URL, error) {
+	if base == "" {
+		return nil, nil
+	}
+	u, err := url.Parse(base)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return nil, fmt.Errorf("invalid public_url_base %q: must be an absolute URL such as https://s3.example.com", base)
+	}
+	u.Path = strings.TrimSuffix(u.Path, "/")
+	u.RawPath = ""
+	return u, nil
+}
+
+// PublicURL 返回对象的规范公开URL
+// 按use_path_style决定URL形式：路径风格为<base>/<bucket>/<key>，
+// 虚拟主机风格为<scheme>://<bucket>.<host>/<key>；键按路径段编码
+// 参数:
+//
+//	bucket: 存储桶名称（为空时使用默认存储桶）
+//	key: 文件键
+//
+// 返回值:
+//
+//	string: 公开URL（未配置public_url_base时为空）
+func (s *Service) PublicURL(bucket, key string) string {
+	if s.publicURLBase == nil {
+		return ""
+	}
+	if bucket == "" {
+		bucket = s.defaultBucket
+	}
+
+	u := s.publicURLBase
+	key = s.keys.stored(key)
+	if s.cfg.UsePathStyle {
+		return fmt.Sprintf("%s://%s%s/%s/%s", u.Scheme, u.Host, u.EscapedPath(), bucket, escapeKey(key))
+	}
+	return fmt.Sprintf("%s://%s.%s%s/%s", u.Scheme, bucket, u.Host, u.EscapedPath(), escapeKey(key))
+}
@@ -0,0 +1,131 @@
+// Package s3 提供S3服务相关功能
+// 作者: KO
+// 创建时间: 2026-10-15
+// 修改时间: 2026-10-15
+package s3
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// ErrQuotaExceeded 写入后存储桶的用量将超过配额
+var ErrQuotaExceeded = errors.New("bucket quota exceeded")
+
+// BucketStats 存储桶用量统计
+type BucketStats struct {
+	Objects int64 `json:"objects"` // 对象数
+	Bytes   int64 `json:"bytes"`   // 对象总大小（字节）
+}
+
+// BucketStats 分页列举存储桶中的全部对象，统计对象数和总大小
+// 参数:
+//
+//	ctx: 上下文
+//	bucket: 存储桶名称（为空时使用默认存储桶）
+//
+// 返回值:
+//
+//	*BucketStats: 用量统计
+//	error: 错误信息
+func (s *Service) BucketStats(ctx context.Context, bucket string) (*BucketStats, error) {
+	stats := &BucketStats{}
+	err := s.listAllObjects(ctx, bucket, "", func(obj types.Object) error {
+		stats.Objects++
+		if obj.Size != nil {
+			stats.Bytes += *obj.Size
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
+
+// usageEntry 缓存的存储桶用量
+type usageEntry struct {
+	bytes   int64     // 已用字节数
+	fetched time.Time // 统计时间
+}
+
+// usageCache 存储桶用量的短期缓存，避免每次上传都列举整个存储桶
+type usageCache struct {
+	mu      sync.Mutex
+	entries map[string]usageEntry
+}
+
+// quotaFor 返回存储桶的配额，按存储桶配置的配额优先于全局配额
+// 参数:
+//
+//	bucket: 存储桶名称
+//
+// 返回值:
+//
+//	int64: 配额（字节，0表示不限制）
+func (s *Service) quotaFor(bucket string) int64 {
+	if quota, ok := s.cfg.BucketQuotas[bucket]; ok {
+		return quota
+	}
+	return s.cfg.BucketQuotaBytes
+}
+
+// checkQuota 检查写入size字节后存储桶的用量是否会超过配额
+// 用量来自BucketStats并缓存bucket_usage_ttl，成功写入后累加到缓存中；
+// 由于缓存和并发上传，该检查只是近似的软上限，并发写入可能让用量略超配额
+// 参数:
+//
+//	ctx: 上下文
+//	bucket: 存储桶名称
+//	size: 即将写入的字节数（未知时为0）
+//
+// 返回值:
+//
+//	error: 会超过配额时返回包装了ErrQuotaExceeded的错误
+func (s *Service) checkQuota(ctx context.Context, bucket string, size int64) error {
+	quota := s.quotaFor(bucket)
+	if quota <= 0 {
+		return nil
+	}
+
+	s.usage.mu.Lock()
+	entry, ok := s.usage.entries[bucket]
+	s.usage.mu.Unlock()
+
+	if !ok || time.Since(entry.fetched) > s.cfg.BucketUsageTTL {
+		stats, err := s.BucketStats(ctx, bucket)
+		if err != nil {
+			return err
+		}
+		entry = usageEntry{bytes: stats.Bytes, fetched: time.Now()}
+
+		s.usage.mu.Lock()
+		s.usage.entries[bucket] = entry
+		s.usage.mu.Unlock()
+	}
+
+	if entry.bytes+size > quota {
+		return fmt.Errorf("%w: bucket %s uses %d of %d bytes, cannot add %d", ErrQuotaExceeded, bucket, entry.bytes, quota, size)
+	}
+	return nil
+}
+
+// addUsage 在成功写入后累加缓存中的用量，缓存过期前的检查即可反映本次写入
+// 参数:
+//
+//	bucket: 存储桶名称
+//	size: 写入的字节数
+func (s *Service) addUsage(bucket string, size int64) {
+	s.usage.mu.Lock()
+	defer s.usage.mu.Unlock()
+
+	if entry, ok := s.usage.entries[bucket]; ok {
+		entry.bytes += size
+		s.usage.entries[bucket] = entry
+	}
+}
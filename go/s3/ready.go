@@ -0,0 +1,27 @@
+// Package s3 提供S3服务相关功能
+// 作者: KO
+// 创建时间: 2026-10-15
+// 修改时间: 2026-10-15
+package s3
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// CheckReady 确认S3可用：能够访问默认存储桶
+// 参数:
+//
+//	ctx: 上下文
+//
+// 返回值:
+//
+//	error: 不可用时的错误信息
+func (s *Service) CheckReady(ctx context.Context) error {
+	_, err := s.client.HeadBucket(ctx, &s3.HeadBucketInput{
+		Bucket: aws.String(s.defaultBucket),
+	})
+	return err
+}
@@ -0,0 +1,157 @@
+// Package s3 提供S3服务相关功能
+// 作者: KO
+// 创建时间: 2026-10-15
+// 修改时间: 2026-10-15
+package s3
+
+import (
+	"context"
+	"io"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/aws/smithy-go/middleware"
+)
+
+// maxRecordedCalls 调用记录器保留的最大调用数，超出后丢弃最早的记录
+const maxRecordedCalls = 1000
+
+// RecordedCall 记录的一次S3调用
+type RecordedCall struct {
+	Operation string                 `json:"operation"`       // 操作名称（如PutObject）
+	Input     map[string]interface{} `json:"input"`           // 输入参数（不含请求体等流式字段）
+	Error     string                 `json:"error,omitempty"` // 调用失败时的错误信息
+	Time      time.Time              `json:"time"`            // 调用开始时间
+}
+
+// callRecorder S3调用记录器，仅用于集成测试（debug_record_calls），不应在生产环境启用：
+// 记录中包含键、元数据等请求参数，且每次调用都会反射复制输入
+type callRecorder struct {
+	mu    sync.Mutex
+	calls []RecordedCall
+}
+
+// middleware 返回记录每次S3调用的SDK中间件
+// 记录的是SDK收到的输入，位于其他中间件改写参数（如哈希前缀）之前；预签名请求不会实际发送，不做记录
+// 返回值:
+//
+//	func(*middleware.Stack) error: 用于s3.Options.APIOptions的中间件注册函数
+func (r *callRecorder) middleware() func(*middleware.Stack) error {
+	return func(stack *middleware.Stack) error {
+		if _, ok := stack.Finalize.Get("Retry"); !ok {
+			return nil
+		}
+
+		// 位于外层时SDK尚未在上下文中记录操作名，取中间件栈的ID（即操作名）
+		operation := stack.ID()
+		return stack.Initialize.Add(middleware.InitializeMiddlewareFunc("CallRecorder",
+			func(ctx context.Context, in middleware.InitializeInput, next middleware.InitializeHandler) (middleware.InitializeOutput, middleware.Metadata, error) {
+				call := RecordedCall{
+					Operation: operation,
+					Input:     recordInput(in.Parameters),
+					Time:      time.Now(),
+				}
+				out, metadata, err := next.HandleInitialize(ctx, in)
+				if err != nil {
+					call.Error = err.Error()
+				}
+				r.add(call)
+				return out, metadata, err
+			}), middleware.Before)
+	}
+}
+
+// add 追加一条记录，超出上限时丢弃最早的记录
+// 参数:
+//
+//	call: 调用记录
+func (r *callRecorder) add(call RecordedCall) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.calls = append(r.calls, call)
+	if len(r.calls) > maxRecordedCalls {
+		r.calls = append([]RecordedCall(nil), r.calls[len(r.calls)-maxRecordedCalls:]...)
+	}
+}
+
+// recordInput 将SDK输入结构体转换为可序列化的字段映射
+// 只保留已设置的导出字段；指针解引用，请求体等接口类型（io.Reader）和函数字段不记录
+// 参数:
+//
+//	params: SDK输入（如*s3.PutObjectInput）
+//
+// 返回值:
+//
+//	map[string]interface{}: 字段名到值的映射
+func recordInput(params interface{}) map[string]interface{} {
+	fields := map[string]interface{}{}
+	v := reflect.ValueOf(params)
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return fields
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return fields
+	}
+
+	t := v.Type()
+	readerType := reflect.TypeOf((*io.Reader)(nil)).Elem()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		value := v.Field(i)
+		if !field.IsExported() || value.IsZero() {
+			continue
+		}
+		switch value.Kind() {
+		case reflect.Interface, reflect.Func, reflect.Chan:
+			continue
+		}
+		if field.Type.Implements(readerType) {
+			continue
+		}
+		if value.Kind() == reflect.Pointer {
+			value = value.Elem()
+		}
+		fields[field.Name] = value.Interface()
+	}
+	return fields
+}
+
+// RecordedCalls 返回调用记录器中的S3调用（从早到晚）
+// 参数:
+//
+//	operation: 只返回该操作的调用（为空时返回全部）
+//
+// 返回值:
+//
+//	[]RecordedCall: 调用记录
+//	bool: 是否启用了调用记录（debug_record_calls）
+func (s *Service) RecordedCalls(operation string) ([]RecordedCall, bool) {
+	if s.recorder == nil {
+		return nil, false
+	}
+
+	s.recorder.mu.Lock()
+	defer s.recorder.mu.Unlock()
+	calls := make([]RecordedCall, 0, len(s.recorder.calls))
+	for _, call := range s.recorder.calls {
+		if operation == "" || call.Operation == operation {
+			calls = append(calls, call)
+		}
+	}
+	return calls, true
+}
+
+// ResetRecordedCalls 清空调用记录器，测试用例之间调用
+func (s *Service) ResetRecordedCalls() {
+	if s.recorder == nil {
+		return
+	}
+
+	s.recorder.mu.Lock()
+	defer s.recorder.mu.Unlock()
+	s.recorder.calls = nil
+}
@@ -0,0 +1,189 @@
+// Package s3 提供S3服务相关功能
+// 作者: KO
+// 创建时间: 2026-10-15
+// 修改时间: 2026-10-15
+package s3
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// ErrRenameMapEmpty 重命名映射CSV中没有任何行
+var ErrRenameMapEmpty = errors.New("rename map is empty")
+
+// 重命名映射中单行的处理结果
+const (
+	renameRenamed   = "renamed"   // 已复制到新键并删除旧键
+	renameSkipped   = "skipped"   // 新键已存在（视为之前已完成），跳过
+	renameMalformed = "malformed" // 行格式无效，未处理
+	renameFailed    = "failed"    // 复制或删除失败
+)
+
+// RenameResult 重命名映射中单行的处理结果
+type RenameResult struct {
+	Row    int    `json:"row"`             // CSV中的行号（从1开始）
+	OldKey string `json:"oldKey"`          // 旧文件键
+	NewKey string `json:"newKey"`          // 新文件键
+	Status string `json:"status"`          // 处理结果（renamed、skipped、malformed、failed）
+	Error  string `json:"error,omitempty"` // 错误信息
+}
+
+// parseRenameMap 解析old-key,new-key格式的CSV
+// 第一行若为old-key,new-key（或old_key,new_key、oldKey,newKey）形式的表头则忽略；
+// 列数不是2、键为空、新旧键相同以及重复的旧键或新键都作为格式无效的行报告，不中断解析
+// 参数:
+//
+//	r: CSV内容
+//
+// 返回值:
+//
+//	[]RenameResult: 每一行的结果，有效的行Status为空，待执行
+//	error: 读取失败时的错误信息
+func parseRenameMap(r io.Reader) ([]RenameResult, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	var rows []RenameResult
+	seenOld := make(map[string]int)
+	seenNew := make(map[string]int)
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			rows = append(rows, RenameResult{Row: parseErr.StartLine, Status: renameMalformed, Error: parseErr.Err.Error()})
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		line, _ := reader.FieldPos(0)
+		row := RenameResult{Row: line}
+
+		if len(rows) == 0 && len(record) == 2 && isRenameMapHeader(record[0], record[1]) {
+			continue
+		}
+
+		switch {
+		case len(record) != 2:
+			row.Status = renameMalformed
+			row.Error = fmt.Sprintf("expected 2 fields (old-key,new-key), got %d", len(record))
+		case record[0] == "" || record[1] == "":
+			row.OldKey, row.NewKey = record[0], record[1]
+			row.Status = renameMalformed
+			row.Error = "old and new keys must not be empty"
+		case record[0] == record[1]:
+			row.OldKey, row.NewKey = record[0], record[1]
+			row.Status = renameMalformed
+			row.Error = "old and new keys are the same"
+		case seenOld[record[0]] != 0:
+			row.OldKey, row.NewKey = record[0], record[1]
+			row.Status = renameMalformed
+			row.Error = fmt.Sprintf("old key already mapped on row %d", seenOld[record[0]])
+		case seenNew[record[1]] != 0:
+			row.OldKey, row.NewKey = record[0], record[1]
+			row.Status = renameMalformed
+			row.Error = fmt.Sprintf("new key already targeted on row %d", seenNew[record[1]])
+		default:
+			row.OldKey, row.NewKey = record[0], record[1]
+			seenOld[row.OldKey] = row.Row
+			seenNew[row.NewKey] = row.Row
+		}
+		rows = append(rows, row)
+	}
+
+	return rows, nil
+}
+
+// isRenameMapHeader 判断CSV的一行是否为表头
+// 参数:
+//
+//	first: 第一列
+//	second: 第二列
+//
+// 返回值:
+//
+//	bool: 是否为表头
+func isRenameMapHeader(first, second string) bool {
+	normalize := func(s string) string {
+		return strings.ToLower(strings.NewReplacer("-", "", "_", "", " ", "").Replace(s))
+	}
+	return normalize(first) == "oldkey" && normalize(second) == "newkey"
+}
+
+// RenameFromCSV 按CSV中的old-key,new-key映射批量重命名对象
+// 每一行以服务端复制到新键再删除旧键的方式完成，按worker_concurrency并发执行。
+// 新键已存在的行直接跳过，因此中断后用同一个CSV重新执行即可从断点继续；
+// 注意这也意味着新键上原本就有的对象不会被覆盖，旧键也会保留。
+// CopyObject不支持超过5GB的对象，这类对象会报告失败
+// 参数:
+//
+//	ctx: 上下文
+//	bucket: 存储桶名称（为空时使用默认存储桶）
+//	csvData: CSV内容
+//
+// 返回值:
+//
+//	[]RenameResult: 每一行的处理结果（按行号排序）
+//	error: CSV无法读取或为空时的错误信息
+func (s *Service) RenameFromCSV(ctx context.Context, bucket string, csvData io.Reader) ([]RenameResult, error) {
+	if bucket == "" {
+		bucket = s.defaultBucket
+	}
+
+	results, err := parseRenameMap(csvData)
+	if err != nil {
+		return nil, err
+	}
+	if len(results) == 0 {
+		return nil, ErrRenameMapEmpty
+	}
+
+	forEachConcurrent(ctx, s.concurrency, len(results), func(ctx context.Context, i int) {
+		result := &results[i]
+		if result.Status != "" {
+			return
+		}
+
+		exists, err := s.FileExists(ctx, bucket, result.NewKey)
+		if err != nil {
+			result.Status = renameFailed
+			result.Error = "Failed to check new key: " + err.Error()
+			return
+		}
+		if exists {
+			result.Status = renameSkipped
+			return
+		}
+		if err := s.CopyObject(ctx, bucket, result.OldKey, bucket, result.NewKey); err != nil {
+			result.Status = renameFailed
+			result.Error = "Failed to copy object: " + err.Error()
+			return
+		}
+		if err := s.DeleteFile(ctx, bucket, result.OldKey); err != nil {
+			result.Status = renameFailed
+			result.Error = "Copied, but failed to delete old key: " + err.Error()
+			return
+		}
+		result.Status = renameRenamed
+	})
+
+	// 上下文取消后未启动的行
+	for i := range results {
+		if results[i].Status == "" {
+			results[i].Status = renameFailed
+			results[i].Error = "Not attempted: " + context.Cause(ctx).Error()
+		}
+	}
+
+	return results, nil
+}
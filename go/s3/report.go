@@ -0,0 +1,197 @@
+// Package s3 提供S3服务相关功能
+// 作者: KO
+// 创建时间: 2026-10-15
+// 修改时间: 2026-10-15
+package s3
+
+import (
+	"compress/gzip"
+	"context"
+	"errors"
+	"io"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// ErrTooManyReports 同时生成的报告数已达到report_max_concurrent
+var ErrTooManyReports = errors.New("too many reports in progress")
+
+// reportJobs 后台生成中的报告
+// 报告的上下文派生自StartBackgroundJobs传入的上下文，服务关闭时取消，未完成的分段上传随之中止
+type reportJobs struct {
+	mu    sync.Mutex
+	ctx   context.Context // 报告的父上下文（StartBackgroundJobs之前为context.Background()）
+	slots chan struct{}   // 并发限制（不限制时为nil）
+	wg    sync.WaitGroup  // 生成中的报告
+}
+
+// newReportJobs 创建报告任务管理器
+// 参数:
+//
+//	maxConcurrent: 同时生成的最大报告数（小于等于0时不限制）
+//
+// 返回值:
+//
+//	*reportJobs: 报告任务管理器
+func newReportJobs(maxConcurrent int) *reportJobs {
+	jobs := &reportJobs{ctx: context.Background()}
+	if maxConcurrent > 0 {
+		jobs.slots = make(chan struct{}, maxConcurrent)
+	}
+	return jobs
+}
+
+// setContext 设置报告的父上下文
+// 参数:
+//
+//	ctx: 上下文
+func (j *reportJobs) setContext(ctx context.Context) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.ctx = ctx
+}
+
+// acquire 占用一个并发名额
+// 返回值:
+//
+//	context.Context: 报告的父上下文
+//	error: 名额已满时返回ErrTooManyReports
+func (j *reportJobs) acquire() (context.Context, error) {
+	if j.slots != nil {
+		select {
+		case j.slots <- struct{}{}:
+		default:
+			return nil, ErrTooManyReports
+		}
+	}
+	j.wg.Add(1)
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.ctx, nil
+}
+
+// release 释放acquire占用的名额
+func (j *reportJobs) release() {
+	if j.slots != nil {
+		<-j.slots
+	}
+	j.wg.Done()
+}
+
+// WaitReports 等待生成中的报告结束（服务关闭时报告已被取消，只需等待分段上传中止完成）
+// 参数:
+//
+//	ctx: 上下文，取消时不再等待
+func (s *Service) WaitReports(ctx context.Context) {
+	done := make(chan struct{})
+	go func() {
+		s.reports.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+	}
+}
+
+// ReportInfo 存储为对象的报告
+type ReportInfo struct {
+	Bucket    string    `json:"bucket"`    // 报告所在的存储桶
+	Key       string    `json:"key"`       // 报告的文件键
+	URL       string    `json:"url"`       // 报告的下载预签名URL（报告写完之前访问会返回404）
+	ExpiresAt time.Time `json:"expiresAt"` // 预签名URL的过期时间
+}
+
+// StartReport 在后台生成报告并存储为对象，立即返回报告的位置和下载URL
+// 报告写入report_bucket中report_prefix下的<name>-<时间戳>-<随机串>.json，启用report_gzip时为.json.gz；
+// 内容通过分段上传边生成边写入，不在内存或磁盘中缓存。生成在请求结束后继续进行，
+// 客户端可轮询返回的URL直到报告可用；生成失败时分段上传会被中止，报告对象不会出现，失败原因记录在日志中。
+// 同时生成的报告数受report_max_concurrent限制，每个报告最长运行report_timeout，服务关闭时取消
+// 参数:
+//
+//	name: 报告名称
+//	expiry: 下载URL有效期
+//	write: 报告内容的生成函数，向w写入未压缩的内容
+//
+// 返回值:
+//
+//	*ReportInfo: 报告信息
+//	error: 错误信息，并发名额已满时返回ErrTooManyReports
+func (s *Service) StartReport(name string, expiry time.Duration, write func(ctx context.Context, w io.Writer) error) (*ReportInfo, error) {
+	bucket := s.cfg.ReportBucket
+	if bucket == "" {
+		bucket = s.defaultBucket
+	}
+	now := time.Now().UTC()
+	key := s.cfg.ReportPrefix + name + "-" + now.Format("20060102T150405Z") + "-" + randomHex(4) + ".json"
+	contentType := "application/json"
+	if s.cfg.ReportGzip {
+		key += ".gz"
+		contentType = "application/gzip"
+	}
+
+	parent, err := s.reports.acquire()
+	if err != nil {
+		return nil, err
+	}
+
+	// 报告对象尚不存在，直接签名而不经过PresignDownloadURL的HeadObject
+	request, err := s.presignClient.PresignGetObject(context.Background(), &s3.GetObjectInput{
+		Bucket:                     aws.String(bucket),
+		Key:                        aws.String(key),
+		ResponseContentDisposition: aws.String("attachment; filename=" + key[len(s.cfg.ReportPrefix):]),
+		ResponseContentType:        aws.String(contentType),
+	}, s3.WithPresignExpires(expiry))
+	if err != nil {
+		s.reports.release()
+		return nil, err
+	}
+
+	go func() {
+		defer s.reports.release()
+
+		var ctx context.Context
+		var cancel context.CancelFunc
+		if s.cfg.ReportTimeout > 0 {
+			ctx, cancel = context.WithTimeout(parent, s.cfg.ReportTimeout)
+		} else {
+			ctx, cancel = context.WithCancel(parent)
+		}
+		defer cancel()
+
+		reader, writer := io.Pipe()
+		go func() {
+			var out io.Writer = writer
+			var gz *gzip.Writer
+			if s.cfg.ReportGzip {
+				gz = gzip.NewWriter(writer)
+				out = gz
+			}
+			err := write(ctx, out)
+			if gz != nil {
+				if closeErr := gz.Close(); err == nil {
+					err = closeErr
+				}
+			}
+			writer.CloseWithError(err)
+		}()
+
+		if _, err := s.multipartUpload(ctx, bucket, key, reader, 0, s.cfg.MultipartPartSize, nil, UploadOptions{ContentType: contentType}); err != nil {
+			reader.CloseWithError(err)
+			log.Printf("Failed to store report %s/%s: %v", bucket, key, err)
+		}
+	}()
+
+	return &ReportInfo{
+		Bucket:    bucket,
+		Key:       key,
+		URL:       request.URL,
+		ExpiresAt: now.Add(expiry),
+	}, nil
+}
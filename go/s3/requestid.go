@@ -0,0 +1,89 @@
+// Package s3 提供S3服务相关功能
+// 作者: KO
+// 创建时间: 2026-10-15
+// 修改时间: 2026-10-15
+package s3
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/smithy-go/middleware"
+)
+
+// MetaRequestID 记录创建对象的请求ID的用户元数据名称（x-amz-meta-request-id）
+const MetaRequestID = "request-id"
+
+// requestIDKey 上下文中请求ID的键
+type requestIDKey struct{}
+
+// WithRequestID 返回带有请求ID的上下文，启用request_id_metadata时，以该上下文写入的对象会记录此ID
+// 参数:
+//
+//	ctx: 上下文
+//	id: 请求ID
+//
+// 返回值:
+//
+//	context.Context: 带有请求ID的上下文
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// requestIDMetadata 返回在写入对象时记录请求ID的SDK中间件
+// 作用于PutObject和CreateMultipartUpload；元数据中已有request-id（由客户端提供）时不覆盖。
+// 上下文中没有请求ID，或ID无法作为请求头发送时不记录。预签名请求的中间件栈中没有Retry中间件，此时不挂载
+// 返回值:
+//
+//	func(*middleware.Stack) error: 用于s3.Options.APIOptions的中间件注册函数
+func requestIDMetadata() func(*middleware.Stack) error {
+	return func(stack *middleware.Stack) error {
+		if _, ok := stack.Finalize.Get("Retry"); !ok {
+			return nil
+		}
+
+		return stack.Initialize.Add(middleware.InitializeMiddlewareFunc("RequestIDMetadata",
+			func(ctx context.Context, in middleware.InitializeInput, next middleware.InitializeHandler) (middleware.InitializeOutput, middleware.Metadata, error) {
+				id, _ := ctx.Value(requestIDKey{}).(string)
+				if id == "" || validateMetadata(map[string]string{MetaRequestID: id}) != nil {
+					return next.HandleInitialize(ctx, in)
+				}
+
+				// 调用方的输入和元数据可能被复用，复制后再修改
+				switch input := in.Parameters.(type) {
+				case *s3.PutObjectInput:
+					if _, ok := input.Metadata[MetaRequestID]; !ok {
+						cp := *input
+						cp.Metadata = withMetadata(input.Metadata, MetaRequestID, id)
+						in.Parameters = &cp
+					}
+				case *s3.CreateMultipartUploadInput:
+					if _, ok := input.Metadata[MetaRequestID]; !ok {
+						cp := *input
+						cp.Metadata = withMetadata(input.Metadata, MetaRequestID, id)
+						in.Parameters = &cp
+					}
+				}
+				return next.HandleInitialize(ctx, in)
+			}), middleware.Before)
+	}
+}
+
+// withMetadata 返回添加了一项的元数据副本
+// 参数:
+//
+//	metadata: 原元数据（可为nil）
+//	name: 名称
+//	value: 值
+//
+// 返回值:
+//
+//	map[string]string: 新的元数据
+func withMetadata(metadata map[string]string, name, value string) map[string]string {
+	cp := make(map[string]string, len(metadata)+1)
+	for k, v := range metadata {
+		cp[k] = v
+	}
+	cp[name] = value
+	return cp
+}
@@ -0,0 +1,41 @@
+// Package s3 提供S3服务相关功能
+// 作者: KO
+// 创建时间: 2026-10-15
+// 修改时间: 2026-10-15
+package s3
+
+import (
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/retry"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/example/s3service/config"
+)
+
+// retryOptions 返回按配置设置SDK重试的加载选项，主客户端、备用客户端和STS客户端共用
+// 使用SDK的标准重试器：连接错误、5xx和限流错误（如SlowDown、503）按带抖动的指数退避重试，
+// 单次退避不超过max_backoff；max_retries为负数时保持SDK默认的重试次数
+// 参数:
+//
+//	cfg: S3配置信息
+//
+// 返回值:
+//
+//	[]func(*awsconfig.LoadOptions) error: AWS配置加载选项
+func retryOptions(cfg *config.S3Config) []func(*awsconfig.LoadOptions) error {
+	var opts []func(*awsconfig.LoadOptions) error
+	if cfg.MaxRetries >= 0 {
+		opts = append(opts, awsconfig.WithRetryMaxAttempts(cfg.MaxRetries+1))
+	}
+
+	opts = append(opts, awsconfig.WithRetryer(func() aws.Retryer {
+		return retry.NewStandard(func(o *retry.StandardOptions) {
+			if cfg.MaxRetries >= 0 {
+				o.MaxAttempts = cfg.MaxRetries + 1
+			}
+			if cfg.MaxBackoff > 0 {
+				o.MaxBackoff = cfg.MaxBackoff
+			}
+		})
+	}))
+	return opts
+}
@@ -1,28 +1,83 @@
 // Package s3 提供S3服务相关功能
 // 作者: KO
 // 创建时间: 2026-01-27
-// 修改时间: 2026-01-27
+// 修改时间: 2026-10-15
 package s3
 
 import (
 	"bytes"
 	"context"
+	"crypto/md5"
+	"crypto/rand"
+	"encoding/hex"
 	"errors"
+	"fmt"
 	"io"
+	"log"
+	"net/url"
+	"os"
+	"path"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
-	awsconfig "github.com/aws/aws-sdk-go-v2/config"
-	"github.com/aws/aws-sdk-go-v2/credentials"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
 	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/aws/smithy-go"
+	"github.com/aws/smithy-go/middleware"
 	"github.com/example/s3service/config"
+	"github.com/example/s3service/metrics"
+)
+
+var (
+	// ErrInvalidBucketOptions 创建存储桶的参数无效
+	ErrInvalidBucketOptions = errors.New("invalid bucket options")
+	// ErrOwnershipUnsupported 后端不支持对象所有权控制
+	ErrOwnershipUnsupported = errors.New("object ownership controls are not supported by the backend")
+	// ErrUnchanged 已有对象的内容与待上传内容相同，上传被跳过
+	ErrUnchanged = errors.New("object content unchanged")
+	// ErrUnchangedCheckUnsupported 客户端加密的内容每次都不同，无法判断内容是否变化
+	ErrUnchangedCheckUnsupported = errors.New("skipIfUnchanged cannot be combined with client-side encryption")
+	// ErrInvalidCopy 复制的源与目标是同一个对象
+	ErrInvalidCopy = errors.New("invalid copy")
+	// ErrRegionMismatch 请求创建存储桶的区域与客户端配置的区域不一致
+	ErrRegionMismatch = errors.New("bucket region does not match the client region")
 )
 
 // Service S3服务实现
 type Service struct {
-	client        *s3.Client // S3客户端
-	defaultBucket string     // 默认存储桶
+	client        *s3.Client        // S3客户端
+	presignClient *s3.PresignClient // 预签名客户端
+	cfg           *config.S3Config  // 服务配置
+	defaultBucket string            // 默认存储桶
+	knownBuckets  []string          // 配置中显式列出的存储桶
+	concurrency   int               // 并发访问S3的最大并发数
+	stagingPrefix string            // 暂存对象的键前缀
+	index         *MetadataIndex    // 元数据索引（未启用时为nil）
+	sessions      *partSessions     // 按偏移写入的分段上传会话
+	directUploads *directUploads    // 客户端通过预签名URL直传分段的分段上传
+	usage         *usageCache       // 存储桶用量缓存（用于配额检查）
+	reports       *reportJobs       // 后台生成中的报告
+	health        *healthCache      // 深度健康检查结果缓存
+	quirks        backendQuirks     // 所配置后端类型的行为差异
+	publicURLBase *url.URL          // 公开URL前缀（未配置时为nil）
+	keys          keyHasher         // 存储键的哈希前缀处理器
+
+	immutability *immutabilityGuard // 上传后不可变窗口检查器（未启用时为nil）
+	recorder     *callRecorder      // S3调用记录器，仅用于测试（未启用时为nil）
+	logger       *operationLogger   // S3操作日志
+}
+
+// ObjectInfo 对象元数据信息
+type ObjectInfo struct {
+	Key          string            `json:"key"`                 // 文件键
+	Size         int64             `json:"size"`                // 文件大小（字节）
+	ContentType  string            `json:"contentType"`         // 内容类型
+	ETag         string            `json:"etag"`                // 实体标签
+	LastModified *time.Time        `json:"lastModified"`        // 最后修改时间
+	Metadata     map[string]string `json:"metadata"`            // 用户自定义元数据
+	Checksums    map[string]string `json:"checksums,omitempty"` // S3保存的完整对象校验和（算法 -> Base64编码的值）
 }
 
 // NewService 创建新的S3服务实例
@@ -36,28 +91,137 @@ type Service struct {
 //	error: 错误信息
 func NewService(cfg *config.S3Config) (*Service, error) {
 	// 创建自定义AWS配置
-	awsCfg, err := awsconfig.LoadDefaultConfig(context.Background(),
-		awsconfig.WithRegion(cfg.Region),
-		awsconfig.WithCredentialsProvider(credentials.NewStaticCredentialsProvider(
-			cfg.AccessKeyID,
-			cfg.SecretAccessKey,
-			"",
-		)),
-	)
+	awsCfg, err := loadAWSConfig(context.Background(), cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := validateTransforms(cfg.UploadTransforms); err != nil {
+		return nil, err
+	}
+	quirks, err := quirksFor(cfg.BackendFlavor)
+	if err != nil {
+		return nil, err
+	}
+	publicURLBase, err := parsePublicURLBase(cfg.PublicURLBase)
+	if err != nil {
+		return nil, err
+	}
+	keys, err := newKeyHasher(cfg.HashPrefixBytes)
 	if err != nil {
 		return nil, err
 	}
 
+	if err := validateMetadata(cfg.DefaultMetadata); err != nil {
+		return nil, fmt.Errorf("invalid default_metadata: %w", err)
+	}
+
+	logger := &operationLogger{}
+
+	var recorder *callRecorder
+	if cfg.DebugRecordCalls {
+		log.Printf("Warning: debug_record_calls is enabled; every S3 call is recorded and exposed at /api/s3/debug/calls. Do not use in production")
+		recorder = &callRecorder{}
+	}
+
+	var immutability *immutabilityGuard
+	if cfg.ImmutabilityWindow > 0 {
+		immutability = &immutabilityGuard{window: cfg.ImmutabilityWindow, exemptPrefix: cfg.StagingPrefix}
+	}
+
 	// 创建S3客户端
+	labeler := metrics.NewBucketLabeler(cfg.Bucket, cfg.MetricsBucketAllowlist)
+	// 备用客户端只用于读取，只挂载指标和与主客户端一致的哈希前缀
+	failoverOptions := []func(*middleware.Stack) error{upstreamMetrics(labeler), retryMetrics()}
+	if keys.enabled() {
+		failoverOptions = append(failoverOptions, hashKeyPrefix(keys))
+	}
 	client := s3.NewFromConfig(awsCfg, func(o *s3.Options) {
 		o.BaseEndpoint = aws.String(cfg.Endpoint)
 		o.UsePathStyle = cfg.UsePathStyle
+		o.APIOptions = append(o.APIOptions, upstreamMetrics(labeler), retryMetrics())
+		if cfg.MaxUpstreamConcurrency > 0 {
+			o.APIOptions = append(o.APIOptions, upstreamLimiter(cfg.MaxUpstreamConcurrency))
+		}
+		if keys.enabled() {
+			o.APIOptions = append(o.APIOptions, hashKeyPrefix(keys))
+		}
+		// 在并发限制之前检查，检查发起的HEAD请求不会与被检查的操作争用同一个名额
+		if immutability != nil {
+			o.APIOptions = append(o.APIOptions, immutability.middleware())
+		}
+		if cfg.RequestIDMetadata {
+			o.APIOptions = append(o.APIOptions, requestIDMetadata())
+		}
+		if len(cfg.DefaultMetadata) > 0 {
+			o.APIOptions = append(o.APIOptions, defaultMetadata(cfg.DefaultMetadata))
+		}
+		if cfg.FailoverEndpoint != "" {
+			o.APIOptions = append(o.APIOptions, readFailover(newFailoverClient(awsCfg, cfg, failoverOptions...)))
+		}
+		// 在并发限制和不可变检查之外计时，排队等待也计入超时
+		if cfg.RequestTimeout > 0 {
+			o.APIOptions = append(o.APIOptions, operationTimeout(cfg.RequestTimeout))
+		}
+		// 在超时之外计时，记录包括排队和重试在内的整体耗时
+		o.APIOptions = append(o.APIOptions, logger.middleware())
+		// 最后注册，位于最外层，记录调用方传入的原始参数
+		if recorder != nil {
+			o.APIOptions = append(o.APIOptions, recorder.middleware())
+		}
 	})
+	if immutability != nil {
+		immutability.client = client
+	}
 
-	return &Service{
+	service := &Service{
 		client:        client,
+		presignClient: s3.NewPresignClient(client),
+		cfg:           cfg,
 		defaultBucket: cfg.Bucket,
-	}, nil
+		knownBuckets:  cfg.Buckets,
+		concurrency:   cfg.WorkerConcurrency,
+		stagingPrefix: cfg.StagingPrefix,
+		sessions:      &partSessions{sessions: make(map[string]*PartSession)},
+		directUploads: &directUploads{uploads: make(map[string]*DirectUpload)},
+		usage:         &usageCache{entries: make(map[string]usageEntry)},
+		reports:       newReportJobs(cfg.ReportMaxConcurrent),
+		health:        &healthCache{},
+		quirks:        quirks,
+		publicURLBase: publicURLBase,
+		keys:          keys,
+
+		immutability: immutability,
+		recorder:     recorder,
+		logger:       logger,
+	}
+	if cfg.MetadataIndexEnabled {
+		service.index = newMetadataIndex(service, cfg.Bucket, cfg.MetadataIndexInterval)
+	}
+
+	return service, nil
+}
+
+// StartBackgroundJobs 启动已启用的后台任务（元数据索引重建、完整性校验、过期分段写入会话的清理），上下文取消时停止；
+// 之后开始生成的报告也派生自该上下文，随之取消
+// 参数:
+//
+//	ctx: 上下文
+func (s *Service) StartBackgroundJobs(ctx context.Context) {
+	s.reports.setContext(ctx)
+	if s.cfg.PartSessionTTL > 0 {
+		go s.expirePartSessions(ctx)
+	}
+	if s.index != nil {
+		go s.index.run(ctx)
+	}
+	if s.cfg.IntegrityCheckEnabled && !s.quirks.objectAttributes {
+		log.Printf("Integrity check disabled: backend_flavor %s does not support GetObjectAttributes", s.cfg.BackendFlavor)
+	} else if s.cfg.IntegrityCheckEnabled {
+		checker := newIntegrityChecker(s, s.defaultBucket, s.cfg.IntegrityCheckPrefix,
+			s.cfg.IntegrityCheckSampleRate, s.cfg.IntegrityCheckInterval)
+		go checker.run(ctx)
+	}
 }
 
 // UploadFile 上传文件到S3存储桶
@@ -67,23 +231,201 @@ func NewService(cfg *config.S3Config) (*Service, error) {
 //	bucket: 存储桶名称（为空时使用默认存储桶）
 //	key: 文件键
 //	content: 文件内容
+//	opts: 上传选项
+//
+// 返回值:
+//
+//	error: 错误信息
+func (s *Service) UploadFile(ctx context.Context, bucket, key string, content []byte, opts UploadOptions) error {
+	return s.UploadStream(ctx, bucket, key, bytes.NewReader(content), int64(len(content)), opts)
+}
+
+// contentUnchanged 判断已有对象的内容是否与body相同
+// 只有单次上传的对象ETag才是内容的MD5；分段上传对象的ETag形如xxx-N，
+// 使用SSE-KMS或SSE-C加密的对象ETag也不是MD5，这些情况都视为已变化而照常上传。
+// 计算MD5需要完整读取body，读取后会回到起始位置
+// 参数:
+//
+//	ctx: 上下文
+//	bucket: 存储桶名称（为空时使用默认存储桶）
+//	key: 文件键
+//	body: 待上传的内容
+//
+// 返回值:
+//
+//	bool: 内容是否未变化
+//	error: 错误信息
+func (s *Service) contentUnchanged(ctx context.Context, bucket, key string, body io.ReadSeeker) (bool, error) {
+	info, err := s.StatObject(ctx, bucket, key)
+	if err != nil {
+		if IsNotFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	etag := strings.Trim(info.ETag, `"`)
+	if strings.Contains(etag, "-") {
+		return false, nil
+	}
+
+	h := md5.New()
+	if _, err := io.Copy(h, body); err != nil {
+		return false, err
+	}
+	if _, err := body.Seek(0, io.SeekStart); err != nil {
+		return false, err
+	}
+	return strings.EqualFold(etag, hex.EncodeToString(h.Sum(nil))), nil
+}
+
+// UploadStream 以流的方式上传文件到S3存储桶，不会在内存中缓冲整个文件
+// 实现了io.ReadSeeker的body（如multipart表单中的文件）直接作为PutObject的Body，ContentLength取自size。
+// SDK对HTTP端点签名时需要先计算内容的哈希再回到开头发送，因此不可Seek的body（如原始请求体）
+// 和长度未知（size为负数，如chunked请求体）的body先读取一遍：不超过chunked_memory_limit的内容保留在内存中，
+// 更大的内容写入本地临时文件
+// 参数:
+//
+//	ctx: 上下文
+//	bucket: 存储桶名称（为空时使用默认存储桶）
+//	key: 文件键
+//	body: 文件内容
+//	size: 文件大小（字节，未知时为-1）
+//	opts: 上传选项
 //
 // 返回值:
 //
 //	error: 错误信息
-func (s *Service) UploadFile(ctx context.Context, bucket, key string, content []byte) error {
+func (s *Service) UploadStream(ctx context.Context, bucket, key string, body io.Reader, size int64, opts UploadOptions) error {
 	if bucket == "" {
 		bucket = s.defaultBucket
 	}
+	if err := validateMetadata(opts.Metadata); err != nil {
+		return err
+	}
+	if opts.SkipIfUnchanged && len(opts.EncryptionKey) > 0 {
+		return ErrUnchangedCheckUnsupported
+	}
+	// PutObject需要确定的长度和可Seek的内容，否则先缓冲（大内容写入临时文件）
+	if _, seekable := body.(io.ReadSeeker); !seekable || size < 0 {
+		spilled, spilledSize, cleanup, err := spillBody(body, s.cfg.ChunkedMemoryLimit)
+		defer cleanup()
+		if err != nil {
+			return fmt.Errorf("failed to read upload body: %w", err)
+		}
+		if size >= 0 && spilledSize != size {
+			return fmt.Errorf("upload body is %d bytes, expected %d", spilledSize, size)
+		}
+		body, size = spilled, spilledSize
+	}
+	if err := s.checkQuota(ctx, bucket, size); err != nil {
+		return err
+	}
 
-	_, err := s.client.PutObject(ctx, &s3.PutObjectInput{
+	contentType := s.CorrectContentType(opts.ContentType, key)
+	// 既没有可信的声明类型也无法按扩展名推断时，按内容检测
+	if contentType == "" || s.isUntrustedContentType(contentType) {
+		sniffed, sniffedBody, err := sniffContentType(body)
+		if err != nil {
+			return err
+		}
+		body = sniffedBody
+		if !s.isUntrustedContentType(sniffed) {
+			contentType = sniffed
+		}
+	}
+	// 按内容类型执行配置的上传转换，转换后的大小可能变化
+	if chain := s.transformsFor(contentType); len(chain) > 0 {
+		if opts.Checksum != "" {
+			return fmt.Errorf("%w: content of type %s is transformed before storage, so a precomputed checksum cannot match", ErrInvalidChecksum, contentType)
+		}
+		transformed, transformedSize, err := applyTransforms(chain, body)
+		if err != nil {
+			return fmt.Errorf("upload transform failed: %w", err)
+		}
+		defer os.Remove(transformed.Name())
+		defer transformed.Close()
+		// 转换后的内容可能更大，按实际存储的大小重新检查配额
+		if transformedSize > size {
+			if err := s.checkQuota(ctx, bucket, transformedSize); err != nil {
+				return err
+			}
+		}
+		body, size = transformed, transformedSize
+	}
+
+	// 与已有对象比较的是最终存储的内容（转换之后），转换规则变化后旧对象不会被误判为未变化
+	if opts.SkipIfUnchanged {
+		seeker, ok := body.(io.ReadSeeker)
+		if !ok {
+			return errors.New("skipIfUnchanged requires a seekable body")
+		}
+		unchanged, err := s.contentUnchanged(ctx, bucket, key, seeker)
+		if err != nil {
+			return err
+		}
+		if unchanged {
+			return ErrUnchanged
+		}
+	}
+
+	// 客户端加密：S3只保存密文，加密后内容类型已无意义
+	metadata := opts.Metadata
+	if len(opts.EncryptionKey) > 0 {
+		if opts.Checksum != "" {
+			return fmt.Errorf("%w: a precomputed checksum cannot be combined with client-side encryption", ErrInvalidChecksum)
+		}
+		encrypted, err := newEncryptReader(body, opts.EncryptionKey)
+		if err != nil {
+			return err
+		}
+		// 加密流不可Seek，SDK签名时需要先计算密文的哈希，因此先将密文读取一遍（大内容写入临时文件）
+		spilled, spilledSize, cleanup, err := spillBody(encrypted, s.cfg.ChunkedMemoryLimit)
+		defer cleanup()
+		if err != nil {
+			return fmt.Errorf("failed to encrypt upload body: %w", err)
+		}
+		if spilledSize != encryptedSize(size) {
+			return fmt.Errorf("encrypted body is %d bytes, expected %d", spilledSize, encryptedSize(size))
+		}
+		body, size = spilled, spilledSize
+		contentType = "application/octet-stream"
+
+		metadata = make(map[string]string, len(opts.Metadata)+1)
+		for name, value := range opts.Metadata {
+			metadata[name] = value
+		}
+		metadata[MetaClientEncryption] = clientEncryptionScheme
+	}
+
+	input := &s3.PutObjectInput{
 		Bucket:        aws.String(bucket),
 		Key:           aws.String(key),
-		Body:          bytes.NewReader(content),
-		ContentLength: aws.Int64(int64(len(content))),
-	})
+		Body:          body,
+		ContentLength: aws.Int64(size),
+		Metadata:      metadata,
+	}
+	if contentType != "" {
+		input.ContentType = aws.String(contentType)
+	}
+	if err := applyChecksum(input, opts.ChecksumAlgorithm, opts.Checksum); err != nil {
+		return err
+	}
 
-	return err
+	_, err := s.client.PutObject(ctx, input)
+	if err != nil {
+		if isChecksumMismatch(err) {
+			return fmt.Errorf("%w: %v", ErrChecksumMismatch, err)
+		}
+		return err
+	}
+	s.addUsage(bucket, size)
+
+	if s.index != nil {
+		s.index.put(bucket, key, metadata)
+	}
+
+	return nil
 }
 
 // DownloadFile 从S3存储桶下载文件
@@ -96,22 +438,137 @@ func (s *Service) UploadFile(ctx context.Context, bucket, key string, content []
 // 返回值:
 //
 //	[]byte: 文件内容
-//	error: 错误信息
-func (s *Service) DownloadFile(ctx context.Context, bucket, key string) ([]byte, error) {
+//	string: 对象的内容类型（S3未返回时为空）
+//	error: 错误信息，对象是客户端加密的时返回ErrEncryptionKeyRequired
+func (s *Service) DownloadFile(ctx context.Context, bucket, key string) ([]byte, string, error) {
 	if bucket == "" {
 		bucket = s.defaultBucket
 	}
 
+	key, err := s.resolveKey(ctx, bucket, key)
+	if err != nil {
+		return nil, "", err
+	}
+
 	output, err := s.client.GetObject(ctx, &s3.GetObjectInput{
 		Bucket: aws.String(bucket),
 		Key:    aws.String(key),
 	})
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
 	defer output.Body.Close()
 
-	return io.ReadAll(output.Body)
+	if output.Metadata[MetaClientEncryption] != "" {
+		return nil, "", ErrEncryptionKeyRequired
+	}
+
+	content, err := io.ReadAll(output.Body)
+	if err != nil {
+		return nil, "", err
+	}
+	return content, aws.ToString(output.ContentType), nil
+}
+
+// DownloadStream 以流的方式下载文件，不在内存中缓冲整个对象
+// 调用方必须关闭返回的io.ReadCloser（包括中途出错或客户端断开时）
+// 参数:
+//
+//	ctx: 上下文
+//	bucket: 存储桶名称（为空时使用默认存储桶）
+//	key: 文件键
+//
+// 返回值:
+//
+//	io.ReadCloser: 文件内容
+//	int64: 内容长度（字节）
+//	string: 对象的内容类型（S3未返回时为空）
+//	error: 错误信息，对象是客户端加密的时返回ErrEncryptionKeyRequired
+func (s *Service) DownloadStream(ctx context.Context, bucket, key string) (io.ReadCloser, int64, string, error) {
+	if bucket == "" {
+		bucket = s.defaultBucket
+	}
+
+	key, err := s.resolveKey(ctx, bucket, key)
+	if err != nil {
+		return nil, 0, "", err
+	}
+
+	output, err := s.client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return nil, 0, "", err
+	}
+
+	if output.Metadata[MetaClientEncryption] != "" {
+		output.Body.Close()
+		return nil, 0, "", ErrEncryptionKeyRequired
+	}
+
+	return output.Body, aws.ToInt64(output.ContentLength), aws.ToString(output.ContentType), nil
+}
+
+// RangeInfo 范围下载的响应信息
+type RangeInfo struct {
+	ContentRange  string // Content-Range响应头（如bytes 0-1023/4096），未返回时为空
+	ContentLength int64  // 本次返回的字节数
+	ContentType   string // 对象的内容类型
+	TotalSize     int64  // 对象总大小（无法确定时为-1）
+}
+
+// DownloadRange 按HTTP Range请求头下载文件的一部分
+// 参数:
+//
+//	ctx: 上下文
+//	bucket: 存储桶名称（为空时使用默认存储桶）
+//	key: 文件键
+//	rangeHeader: Range请求头（如bytes=0-1023，为空时下载整个文件）
+//
+// 返回值:
+//
+//	io.ReadCloser: 文件内容，调用方负责关闭
+//	*RangeInfo: 范围信息
+//	error: 错误信息
+func (s *Service) DownloadRange(ctx context.Context, bucket, key, rangeHeader string) (io.ReadCloser, *RangeInfo, error) {
+	if bucket == "" {
+		bucket = s.defaultBucket
+	}
+
+	key, err := s.resolveKey(ctx, bucket, key)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	input := &s3.GetObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	}
+	if rangeHeader != "" {
+		input.Range = aws.String(rangeHeader)
+	}
+
+	output, err := s.client.GetObject(ctx, input)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	info := &RangeInfo{
+		ContentRange:  aws.ToString(output.ContentRange),
+		ContentLength: aws.ToInt64(output.ContentLength),
+		ContentType:   aws.ToString(output.ContentType),
+		TotalSize:     -1,
+	}
+	if info.ContentRange == "" {
+		info.TotalSize = info.ContentLength
+	} else if i := strings.LastIndex(info.ContentRange, "/"); i >= 0 {
+		if total, err := strconv.ParseInt(info.ContentRange[i+1:], 10, 64); err == nil {
+			info.TotalSize = total
+		}
+	}
+
+	return output.Body, info, nil
 }
 
 // DeleteFile 从S3存储桶删除文件
@@ -129,15 +586,28 @@ func (s *Service) DeleteFile(ctx context.Context, bucket, key string) error {
 		bucket = s.defaultBucket
 	}
 
-	_, err := s.client.DeleteObject(ctx, &s3.DeleteObjectInput{
+	key, err := s.resolveKey(ctx, bucket, key)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.client.DeleteObject(ctx, &s3.DeleteObjectInput{
 		Bucket: aws.String(bucket),
 		Key:    aws.String(key),
 	})
+	if err != nil {
+		return err
+	}
 
-	return err
+	if s.index != nil {
+		s.index.remove(bucket, key)
+	}
+
+	return nil
 }
 
 // FileExists 检查文件是否存在于S3存储桶
+// 只有HeadObject明确返回404（NotFound）时才视为不存在，网络故障、权限不足等其他错误原样返回
 // 参数:
 //
 //	ctx: 上下文
@@ -147,7 +617,8 @@ func (s *Service) DeleteFile(ctx context.Context, bucket, key string) error {
 // 返回值:
 //
 //	bool: 文件是否存在
-func (s *Service) FileExists(ctx context.Context, bucket, key string) bool {
+//	error: 无法确定是否存在时的错误信息
+func (s *Service) FileExists(ctx context.Context, bucket, key string) (bool, error) {
 	if bucket == "" {
 		bucket = s.defaultBucket
 	}
@@ -156,11 +627,134 @@ func (s *Service) FileExists(ctx context.Context, bucket, key string) bool {
 		Bucket: aws.String(bucket),
 		Key:    aws.String(key),
 	})
+	if err != nil && s.cfg.CaseInsensitiveKeys && IsNotFound(err) {
+		_, found, err := s.findKeyCaseInsensitive(ctx, bucket, key)
+		return found, err
+	}
+	if IsNotFound(err) {
+		return false, nil
+	}
+
+	return err == nil, err
+}
+
+// StatObject 获取S3存储桶中文件的元数据
+// 参数:
+//
+//	ctx: 上下文
+//	bucket: 存储桶名称（为空时使用默认存储桶）
+//	key: 文件键
+//
+// 返回值:
+//
+//	*ObjectInfo: 文件元数据
+//	error: 错误信息
+func (s *Service) StatObject(ctx context.Context, bucket, key string) (*ObjectInfo, error) {
+	if bucket == "" {
+		bucket = s.defaultBucket
+	}
+
+	output, err := s.client.HeadObject(ctx, &s3.HeadObjectInput{
+		Bucket:       aws.String(bucket),
+		Key:          aws.String(key),
+		ChecksumMode: types.ChecksumModeEnabled,
+	})
+	if err != nil && s.cfg.CaseInsensitiveKeys && IsNotFound(err) {
+		canonical, found, findErr := s.findKeyCaseInsensitive(ctx, bucket, key)
+		if findErr != nil {
+			return nil, findErr
+		}
+		if found {
+			key = canonical
+			output, err = s.client.HeadObject(ctx, &s3.HeadObjectInput{
+				Bucket:       aws.String(bucket),
+				Key:          aws.String(key),
+				ChecksumMode: types.ChecksumModeEnabled,
+			})
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
 
-	return err == nil
+	return &ObjectInfo{
+		Key:          key,
+		Size:         aws.ToInt64(output.ContentLength),
+		ContentType:  aws.ToString(output.ContentType),
+		ETag:         aws.ToString(output.ETag),
+		LastModified: output.LastModified,
+		Metadata:     output.Metadata,
+		Checksums:    headChecksums(output),
+	}, nil
+}
+
+// PresignDownloadURL 生成文件下载的预签名URL
+// 参数:
+//
+//	ctx: 上下文
+//	bucket: 存储桶名称（为空时使用默认存储桶）
+//	key: 文件键
+//	expiry: URL有效期
+//
+// 返回值:
+//
+//	string: 预签名URL
+//	error: 错误信息
+func (s *Service) PresignDownloadURL(ctx context.Context, bucket, key string, expiry time.Duration) (string, error) {
+	if bucket == "" {
+		bucket = s.defaultBucket
+	}
+
+	input := &s3.GetObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	}
+	// 启用force_download时由S3在响应中返回附件形式的Content-Disposition
+	if s.cfg.ForceDownload {
+		input.ResponseContentDisposition = aws.String("attachment; filename=" + path.Base(key))
+		if s.cfg.ForceDownloadOverride {
+			info, err := s.StatObject(ctx, bucket, key)
+			if err != nil {
+				return "", err
+			}
+			if contentType := s.DownloadContentType(info.ContentType); contentType != info.ContentType {
+				input.ResponseContentType = aws.String(contentType)
+			}
+		}
+	}
+
+	request, err := s.presignClient.PresignGetObject(ctx, input, s3.WithPresignExpires(expiry))
+	if err != nil {
+		return "", err
+	}
+
+	return request.URL, nil
+}
+
+// PresignDownloadURLs 并发生成多个文件的下载预签名URL
+// 参数:
+//
+//	ctx: 上下文
+//	bucket: 存储桶名称（为空时使用默认存储桶）
+//	keys: 文件键列表
+//	expiry: URL有效期
+//
+// 返回值:
+//
+//	[]string: 与keys一一对应的预签名URL（失败时为空）
+//	[]error: 与keys一一对应的错误（成功时为nil）
+func (s *Service) PresignDownloadURLs(ctx context.Context, bucket string, keys []string, expiry time.Duration) ([]string, []error) {
+	urls := make([]string, len(keys))
+	errs := make([]error, len(keys))
+	forEachConcurrent(ctx, s.concurrency, len(keys), func(ctx context.Context, i int) {
+		urls[i], errs[i] = s.PresignDownloadURL(ctx, bucket, keys[i], expiry)
+	})
+	return urls, errs
 }
 
 // ListFiles 列出S3存储桶中的所有文件
+// 使用分页器逐页列举，不受单次ListObjectsV2最多返回1000个对象的限制；
+// 对象很多的存储桶应使用ListFilesPage分页返回
 // 参数:
 //
 //	ctx: 上下文
@@ -175,26 +769,260 @@ func (s *Service) ListFiles(ctx context.Context, bucket string) ([]map[string]in
 		bucket = s.defaultBucket
 	}
 
-	output, err := s.client.ListObjectsV2(ctx, &s3.ListObjectsV2Input{
-		Bucket: aws.String(bucket),
+	files := make([]map[string]interface{}, 0)
+	err := s.listAllObjects(ctx, bucket, "", func(obj types.Object) error {
+		if !isFolderMarker(obj) {
+			files = append(files, fileEntry(obj))
+		}
+		return nil
 	})
 	if err != nil {
 		return nil, err
 	}
 
-	files := make([]map[string]interface{}, 0, len(output.Contents))
+	return files, nil
+}
+
+// ErrDelimiterUnsupported 启用了哈希前缀时无法按分隔符归并
+var ErrDelimiterUnsupported = errors.New("delimiter listing is not supported when hash_prefix_bytes is set")
+
+// FileListing 按前缀和分隔符列举的结果
+type FileListing struct {
+	Files          []map[string]interface{} `json:"files"`          // 文件列表
+	CommonPrefixes []string                 `json:"commonPrefixes"` // 公共前缀（类似子目录）
+}
+
+// ListFilesWithPrefix 列举前缀下的全部文件，设置了分隔符时将更深层的键归并为公共前缀
+// 如prefix为2026/、delimiter为/时，返回2026/下的文件以及2026/01/、2026/02/等公共前缀，
+// 可用于按目录树的方式浏览存储桶
+// 参数:
+//
+//	ctx: 上下文
+//	bucket: 存储桶名称（为空时使用默认存储桶）
+//	prefix: 键前缀（为空时列举全部对象）
+//	delimiter: 分隔符（为空时不归并）
+//
+// 返回值:
+//
+//	*FileListing: 文件和公共前缀
+//	error: 错误信息
+func (s *Service) ListFilesWithPrefix(ctx context.Context, bucket, prefix, delimiter string) (*FileListing, error) {
+	listing := &FileListing{
+		Files:          make([]map[string]interface{}, 0),
+		CommonPrefixes: make([]string, 0),
+	}
+
+	opts := ListPageOptions{Prefix: prefix, Delimiter: delimiter}
+	for {
+		page, err := s.ListFilesPage(ctx, bucket, opts)
+		if err != nil {
+			return nil, err
+		}
+		listing.Files = append(listing.Files, page.Files...)
+		listing.CommonPrefixes = append(listing.CommonPrefixes, page.CommonPrefixes...)
+		if page.NextToken == "" {
+			return listing, nil
+		}
+		opts.ContinuationToken = page.NextToken
+	}
+}
+
+// ListPageOptions 分页列举文件的参数
+type ListPageOptions struct {
+	Prefix            string // 键前缀（为空时列举全部对象）
+	MaxKeys           int32  // 每页最多返回的对象数
+	ContinuationToken string // 上一页返回的续传令牌（为空时从头开始）
+	StartAfter        string // 从该键之后开始列举（游标模式，设置了ContinuationToken时被S3忽略）
+	Delimiter         string // 分隔符（如/），设置后前缀之后含分隔符的键归并到CommonPrefixes中
+}
+
+// FilePage 分页列举文件的一页结果
+type FilePage struct {
+	Files      []map[string]interface{} // 文件列表
+	NextToken  string                   // 下一页的续传令牌（没有更多结果时为空）
+	NextCursor string                   // 下一页的StartAfter游标，即本页最后一个键（没有更多结果时为空）
+
+	CommonPrefixes []string // 设置了Delimiter时归并得到的公共前缀（类似子目录）
+}
+
+// ListFilesPage 分页列举文件，每次只发出一次ListObjectsV2请求
+// 参数:
+//
+//	ctx: 上下文
+//	bucket: 存储桶名称（为空时使用默认存储桶）
+//	opts: 分页参数
+//
+// 返回值:
+//
+//	*FilePage: 一页文件列表
+//	error: 错误信息
+func (s *Service) ListFilesPage(ctx context.Context, bucket string, opts ListPageOptions) (*FilePage, error) {
+	if bucket == "" {
+		bucket = s.defaultBucket
+	}
+
+	input := &s3.ListObjectsV2Input{
+		Bucket: aws.String(bucket),
+	}
+	if opts.Prefix != "" {
+		input.Prefix = aws.String(opts.Prefix)
+	}
+	if opts.MaxKeys > 0 {
+		input.MaxKeys = aws.Int32(opts.MaxKeys)
+	}
+	if opts.ContinuationToken != "" {
+		input.ContinuationToken = aws.String(opts.ContinuationToken)
+	}
+	if opts.StartAfter != "" {
+		input.StartAfter = aws.String(opts.StartAfter)
+	}
+	if opts.Delimiter != "" {
+		if s.keys.enabled() {
+			return nil, ErrDelimiterUnsupported
+		}
+		input.Delimiter = aws.String(opts.Delimiter)
+	}
+
+	output, err := s.client.ListObjectsV2(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &FilePage{
+		Files: make([]map[string]interface{}, 0, len(output.Contents)),
+	}
 	for _, obj := range output.Contents {
-		files = append(files, map[string]interface{}{
-			"key":          *obj.Key,
-			"size":         obj.Size,
-			"lastModified": obj.LastModified,
-		})
+		// 文件夹标记不作为文件返回，设置了Delimiter时以CommonPrefixes的形式出现
+		if !isFolderMarker(obj) {
+			page.Files = append(page.Files, fileEntry(obj))
+		}
+	}
+	for _, prefix := range output.CommonPrefixes {
+		page.CommonPrefixes = append(page.CommonPrefixes, aws.ToString(prefix.Prefix))
+	}
+	if aws.ToBool(output.IsTruncated) {
+		page.NextToken = aws.ToString(output.NextContinuationToken)
+		// 启用哈希前缀时页面在本地按前缀过滤过，以过滤前的最后一个键作为游标
+		if last, ok := output.ResultMetadata.Get(lastListedKey{}).(string); ok {
+			page.NextCursor = last
+		} else if len(output.Contents) > 0 {
+			page.NextCursor = aws.ToString(output.Contents[len(output.Contents)-1].Key)
+		}
+	}
+
+	return page, nil
+}
+
+// ListFilesFiltered 分页列举前缀下的全部文件，只返回键满足过滤条件的文件
+// 过滤在服务端完成，因此会扫描前缀下的所有对象
+// 参数:
+//
+//	ctx: 上下文
+//	bucket: 存储桶名称（为空时使用默认存储桶）
+//	prefix: 键前缀（为空时列举全部对象）
+//	match: 键过滤函数
+//
+// 返回值:
+//
+//	[]map[string]interface{}: 文件列表
+//	error: 错误信息
+func (s *Service) ListFilesFiltered(ctx context.Context, bucket, prefix string, match func(key string) bool) ([]map[string]interface{}, error) {
+	files := make([]map[string]interface{}, 0)
+	err := s.listAllObjects(ctx, bucket, prefix, func(obj types.Object) error {
+		if !isFolderMarker(obj) && match(aws.ToString(obj.Key)) {
+			files = append(files, fileEntry(obj))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	return files, nil
 }
 
+// fileEntry 将S3对象转换为文件列表中的一项
+// 参数:
+//
+//	obj: S3对象
+//
+// 返回值:
+//
+//	map[string]interface{}: 文件信息
+func fileEntry(obj types.Object) map[string]interface{} {
+	return map[string]interface{}{
+		"key":          aws.ToString(obj.Key),
+		"size":         obj.Size,
+		"lastModified": obj.LastModified,
+	}
+}
+
+// listAllObjects 分页列举存储桶中指定前缀下的全部对象
+// 参数:
+//
+//	ctx: 上下文
+//	bucket: 存储桶名称（为空时使用默认存储桶）
+//	prefix: 键前缀（为空时列举全部对象）
+//	fn: 对每个对象调用的回调，返回错误时停止列举
+//
+// 返回值:
+//
+//	error: 错误信息
+func (s *Service) listAllObjects(ctx context.Context, bucket, prefix string, fn func(obj types.Object) error) error {
+	if bucket == "" {
+		bucket = s.defaultBucket
+	}
+
+	input := &s3.ListObjectsV2Input{
+		Bucket: aws.String(bucket),
+	}
+	if prefix != "" {
+		input.Prefix = aws.String(prefix)
+	}
+
+	paginator := s3.NewListObjectsV2Paginator(s.client, input)
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return err
+		}
+		for _, obj := range page.Contents {
+			if err := fn(obj); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// SearchMetadata 在元数据索引中查询用户元数据匹配的对象
+// 索引是最终一致且仅限当前实例的，详见MetadataIndex
+// 参数:
+//
+//	filters: 元数据过滤条件（元数据键 -> 期望值），元数据键不区分大小写
+//
+// 返回值:
+//
+//	[]IndexEntry: 匹配的记录
+//	error: 索引未启用时返回ErrMetadataIndexDisabled
+func (s *Service) SearchMetadata(filters map[string]string) ([]IndexEntry, error) {
+	if s.index == nil {
+		return nil, ErrMetadataIndexDisabled
+	}
+
+	// S3返回的用户元数据键均为小写
+	normalized := make(map[string]string, len(filters))
+	for name, value := range filters {
+		normalized[strings.ToLower(name)] = value
+	}
+
+	return s.index.search(normalized), nil
+}
+
 // ListBuckets 列出所有S3存储桶
+// 当凭证没有ListBuckets权限（AccessDenied）但可以访问特定存储桶时，
+// 返回默认存储桶和配置中显式列出的存储桶作为回退列表，并标记列表不完整
 // 参数:
 //
 //	ctx: 上下文
@@ -202,11 +1030,15 @@ func (s *Service) ListFiles(ctx context.Context, bucket string) ([]map[string]in
 // 返回值:
 //
 //	[]map[string]interface{}: 存储桶列表
+//	bool: 列表是否不完整（使用了回退列表）
 //	error: 错误信息
-func (s *Service) ListBuckets(ctx context.Context) ([]map[string]interface{}, error) {
+func (s *Service) ListBuckets(ctx context.Context) ([]map[string]interface{}, bool, error) {
 	output, err := s.client.ListBuckets(ctx, &s3.ListBucketsInput{})
 	if err != nil {
-		return nil, err
+		if isAccessDenied(err) {
+			return s.fallbackBuckets(), true, nil
+		}
+		return nil, false, err
 	}
 
 	buckets := make([]map[string]interface{}, 0, len(output.Buckets))
@@ -217,7 +1049,55 @@ func (s *Service) ListBuckets(ctx context.Context) ([]map[string]interface{}, er
 		})
 	}
 
-	return buckets, nil
+	return buckets, false, nil
+}
+
+// fallbackBuckets 返回由配置得出的存储桶列表（默认存储桶在前，去重）
+// 返回值:
+//
+//	[]map[string]interface{}: 存储桶列表（创建时间未知，为nil）
+func (s *Service) fallbackBuckets() []map[string]interface{} {
+	names := append([]string{s.defaultBucket}, s.knownBuckets...)
+
+	seen := make(map[string]struct{}, len(names))
+	buckets := make([]map[string]interface{}, 0, len(names))
+	for _, name := range names {
+		if _, ok := seen[name]; ok || name == "" {
+			continue
+		}
+		seen[name] = struct{}{}
+		buckets = append(buckets, map[string]interface{}{
+			"name":         name,
+			"creationDate": nil,
+		})
+	}
+
+	return buckets
+}
+
+// CreateBucketOptions 创建存储桶的可选参数
+type CreateBucketOptions struct {
+	ObjectOwnership string // 对象所有权（BucketOwnerEnforced、BucketOwnerPreferred、ObjectWriter）
+	ACL             string // 存储桶预设ACL（private、public-read等）
+	Region          string // 存储桶所在区域（为空时不指定LocationConstraint）
+}
+
+// validate 校验创建存储桶参数的取值及组合是否为S3所允许
+// 返回值:
+//
+//	error: 校验失败时返回包装了ErrInvalidBucketOptions的错误
+func (o CreateBucketOptions) validate() error {
+	if o.ObjectOwnership != "" && !containsString(types.ObjectOwnership("").Values(), types.ObjectOwnership(o.ObjectOwnership)) {
+		return fmt.Errorf("%w: unknown objectOwnership %q", ErrInvalidBucketOptions, o.ObjectOwnership)
+	}
+	if o.ACL != "" && !containsString(types.BucketCannedACL("").Values(), types.BucketCannedACL(o.ACL)) {
+		return fmt.Errorf("%w: unknown acl %q", ErrInvalidBucketOptions, o.ACL)
+	}
+	// BucketOwnerEnforced会禁用ACL，此时只允许等价于默认值的private
+	if o.ObjectOwnership == string(types.ObjectOwnershipBucketOwnerEnforced) && o.ACL != "" && o.ACL != string(types.BucketCannedACLPrivate) {
+		return fmt.Errorf("%w: acl %q is not allowed when objectOwnership is BucketOwnerEnforced (ACLs are disabled)", ErrInvalidBucketOptions, o.ACL)
+	}
+	return nil
 }
 
 // CreateBucket 创建新的S3存储桶
@@ -225,11 +1105,25 @@ func (s *Service) ListBuckets(ctx context.Context) ([]map[string]interface{}, er
 //
 //	ctx: 上下文
 //	bucket: 存储桶名称
+//	opts: 对象所有权、ACL等可选参数
 //
 // 返回值:
 //
-//	error: 错误信息
-func (s *Service) CreateBucket(ctx context.Context, bucket string) error {
+//	error: 错误信息，名称不符合S3命名规则时返回ErrInvalidBucketName
+func (s *Service) CreateBucket(ctx context.Context, bucket string, opts CreateBucketOptions) error {
+	if err := ValidateBucketName(bucket); err != nil {
+		return err
+	}
+	if err := opts.validate(); err != nil {
+		return err
+	}
+	if opts.ObjectOwnership != "" && !s.quirks.ownershipControls {
+		return fmt.Errorf("%w: backend_flavor %s does not support object ownership controls", ErrOwnershipUnsupported, s.cfg.BackendFlavor)
+	}
+	if err := s.checkBucketRegion(bucket, opts.Region); err != nil {
+		return err
+	}
+
 	// 检查存储桶是否已存在
 	_, err := s.client.HeadBucket(ctx, &s3.HeadBucketInput{
 		Bucket: aws.String(bucket),
@@ -239,29 +1133,147 @@ func (s *Service) CreateBucket(ctx context.Context, bucket string) error {
 		return errors.New("bucket already exists")
 	}
 
-	// 检查是否是NoSuchBucket错误或404错误
-	var apiErr *types.NoSuchBucket
-	if errors.As(err, &apiErr) {
-		// 确实是NoSuchBucket错误，可以继续创建
-	} else {
-		// 对于MinIO，HeadBucket返回404时，可能不会返回NoSuchBucket类型的错误
-		// 而是返回一个包含"NotFound"或"StatusCode: 404"的错误消息
-		// 所以我们需要检查错误信息是否包含"NotFound"或"StatusCode: 404"
-		errMsg := err.Error()
-		if errMsg == "" {
-			return err
-		}
-		// 检查错误消息中是否包含"NotFound"或"StatusCode: 404"
-		if !strings.Contains(errMsg, "NotFound") && !strings.Contains(errMsg, "StatusCode: 404") {
-			// 其他错误，比如权限问题、网络问题等
-			return err
-		}
+	// 只有存储桶不存在时才继续创建，其他错误（权限问题、网络问题等）直接返回
+	if !s.quirks.isBucketNotFound(err) {
+		return err
 	}
 
 	// 创建存储桶
-	_, err = s.client.CreateBucket(ctx, &s3.CreateBucketInput{
+	input := &s3.CreateBucketInput{
 		Bucket: aws.String(bucket),
-	})
+	}
+	if opts.ObjectOwnership != "" {
+		input.ObjectOwnership = types.ObjectOwnership(opts.ObjectOwnership)
+	}
+	if opts.ACL != "" {
+		input.ACL = types.BucketCannedACL(opts.ACL)
+	}
+	// us-east-1不接受LocationConstraint，省略即表示该区域；忽略该参数的后端不再发送
+	if opts.Region != "" && opts.Region != "us-east-1" && !s.quirks.ignoresLocationConstraint {
+		input.CreateBucketConfiguration = &types.CreateBucketConfiguration{
+			LocationConstraint: types.BucketLocationConstraint(opts.Region),
+		}
+	}
+
+	_, err = s.client.CreateBucket(ctx, input)
+	if err != nil && opts.ObjectOwnership != "" && isNotImplemented(err) {
+		// MinIO等兼容实现可能不支持对象所有权控制
+		return fmt.Errorf("%w: %v", ErrOwnershipUnsupported, err)
+	}
 
 	return err
+}
+
+// checkBucketRegion 校验请求创建存储桶的区域是否与客户端配置的区域一致
+// 在真实的AWS上，其他区域的存储桶可以创建成功，但本服务的客户端随后无法访问它。
+// 未启用按请求覆盖区域时，按bucket_region_policy拒绝（reject）或仅记录警告（warn）；
+// 后端忽略LocationConstraint时存储桶总在服务端的区域，不做校验
+// 参数:
+//
+//	bucket: 存储桶名称
+//	region: 请求的区域（为空时不校验）
+//
+// 返回值:
+//
+//	error: 拒绝时返回包装了ErrRegionMismatch的错误
+func (s *Service) checkBucketRegion(bucket, region string) error {
+	if region == "" || region == s.cfg.Region || s.cfg.AllowRegionOverride || s.quirks.ignoresLocationConstraint {
+		return nil
+	}
+
+	if s.cfg.BucketRegionPolicy == "warn" {
+		log.Printf("Warning: creating bucket %s in region %s, but the client is configured for %s; it may be unreachable through this service",
+			bucket, region, s.cfg.Region)
+		return nil
+	}
+
+	return fmt.Errorf("%w: requested %q but this service is configured for %q, so the bucket would be created but not reachable through it; "+
+		"create it in %q, or enable allow_region_override", ErrRegionMismatch, region, s.cfg.Region, s.cfg.Region)
+}
+
+// isNotImplemented 判断错误是否为后端不支持该功能（NotImplemented）
+// 参数:
+//
+//	err: 错误
+//
+// 返回值:
+//
+//	bool: 是否为NotImplemented错误
+func isNotImplemented(err error) bool {
+	var apiErr smithy.APIError
+	return errors.As(err, &apiErr) && apiErr.ErrorCode() == "NotImplemented"
+}
+
+// errorCode 返回S3 API错误的错误码
+// 参数:
+//
+//	err: 错误
+//
+// 返回值:
+//
+//	string: 错误码，不是API错误时为空
+func errorCode(err error) string {
+	var apiErr smithy.APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.ErrorCode()
+	}
+	return ""
+}
+
+// IsInvalidRange 判断错误是否为请求范围无法满足（InvalidRange）
+// 参数:
+//
+//	err: 错误
+//
+// 返回值:
+//
+//	bool: 是否为InvalidRange错误
+func IsInvalidRange(err error) bool {
+	var apiErr smithy.APIError
+	return errors.As(err, &apiErr) && apiErr.ErrorCode() == "InvalidRange"
+}
+
+// isAccessDenied 判断错误是否为权限不足（AccessDenied）
+// 参数:
+//
+//	err: 错误
+//
+// 返回值:
+//
+//	bool: 是否为AccessDenied错误
+func isAccessDenied(err error) bool {
+	var apiErr smithy.APIError
+	return errors.As(err, &apiErr) && apiErr.ErrorCode() == "AccessDenied"
+}
+
+// containsString 判断字符串类型的切片中是否包含指定值
+// 参数:
+//
+//	values: 切片
+//	value: 待查找的值
+//
+// 返回值:
+//
+//	bool: 是否包含
+func containsString[T ~string](values []T, value T) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// randomHex 生成n字节的随机十六进制字符串
+// 参数:
+//
+//	n: 随机字节数
+//
+// 返回值:
+//
+//	string: 长度为2n的十六进制字符串
+func randomHex(n int) string {
+	buf := make([]byte, n)
+	_, _ = rand.Read(buf)
+	return hex.EncodeToString(buf)
 }
\ No newline at end of file
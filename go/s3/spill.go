@@ -0,0 +1,57 @@
+// Package s3 提供S3服务相关功能
+// 作者: KO
+// 创建时间: 2026-10-15
+// 修改时间: 2026-10-15
+package s3
+
+import (
+	"bytes"
+	"io"
+	"os"
+)
+
+// spillBody 读取长度未知的内容（如Transfer-Encoding: chunked的请求体），得到可Seek的内容及其长度
+// 不超过memoryLimit字节的内容保留在内存中，更大的内容写入本地临时文件，不会在内存中缓冲整个请求体
+// 参数:
+//
+//	body: 长度未知的内容
+//	memoryLimit: 保留在内存中的最大字节数
+//
+// 返回值:
+//
+//	io.ReadSeeker: 从头开始读取的内容
+//	int64: 内容长度
+//	func(): 释放临时文件的清理函数（总是非nil）
+//	error: 错误信息
+func spillBody(body io.Reader, memoryLimit int64) (io.ReadSeeker, int64, func(), error) {
+	noop := func() {}
+
+	var head bytes.Buffer
+	n, err := io.Copy(&head, io.LimitReader(body, memoryLimit+1))
+	if err != nil {
+		return nil, 0, noop, err
+	}
+	if n <= memoryLimit {
+		return bytes.NewReader(head.Bytes()), n, noop, nil
+	}
+
+	spill, err := os.CreateTemp("", "upload-*")
+	if err != nil {
+		return nil, 0, noop, err
+	}
+	cleanup := func() {
+		spill.Close()
+		os.Remove(spill.Name())
+	}
+
+	size, err := io.Copy(spill, io.MultiReader(&head, body))
+	if err != nil {
+		cleanup()
+		return nil, 0, noop, err
+	}
+	if _, err := spill.Seek(0, io.SeekStart); err != nil {
+		cleanup()
+		return nil, 0, noop, err
+	}
+	return spill, size, cleanup, nil
+}
@@ -0,0 +1,197 @@
+// Package s3 提供S3服务相关功能
+// 作者: KO
+// 创建时间: 2026-10-15
+// 修改时间: 2026-10-15
+package s3
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// stagingIDPattern 暂存ID的格式（32位十六进制）
+var stagingIDPattern = regexp.MustCompile(`^[0-9a-f]{32}$`)
+
+var (
+	// ErrInvalidStagingID 暂存ID格式无效
+	ErrInvalidStagingID = errors.New("invalid staging id")
+	// ErrStagingEmpty 暂存区中没有任何对象
+	ErrStagingEmpty = errors.New("no objects staged under staging id")
+)
+
+// PublishResult 发布暂存对象时单个对象的结果
+type PublishResult struct {
+	Key       string `json:"key"`             // 最终文件键
+	Published bool   `json:"published"`       // 是否已复制到最终位置
+	Cleaned   bool   `json:"cleaned"`         // 暂存副本是否已删除
+	Error     string `json:"error,omitempty"` // 错误信息
+}
+
+// NewStagingID 生成新的暂存ID
+// 暂存集合完全由对象键前缀<staging_prefix><暂存ID>/标识，服务端不保存额外状态
+// 返回值:
+//
+//	string: 暂存ID
+func (s *Service) NewStagingID() string {
+	return randomHex(16)
+}
+
+// StagingKey 返回文件在暂存区中的键
+// 参数:
+//
+//	stagingID: 暂存ID
+//	key: 最终文件键
+//
+// 返回值:
+//
+//	string: 暂存键
+//	error: 暂存ID无效时返回ErrInvalidStagingID
+func (s *Service) StagingKey(stagingID, key string) (string, error) {
+	if !stagingIDPattern.MatchString(stagingID) {
+		return "", ErrInvalidStagingID
+	}
+	return s.stagingPrefix + stagingID + "/" + key, nil
+}
+
+// Publish 将暂存区中的全部对象发布到最终位置
+// 先以服务端复制将所有暂存对象复制到最终键，全部成功后再删除暂存副本，
+// 使客户端不会看到只写了一半的对象集合。S3不支持多对象事务，因此这只是尽力而为的原子性：
+// 若部分复制失败，已复制成功的对象仍会可见，且暂存副本全部保留以便重试发布。
+// 参数:
+//
+//	ctx: 上下文
+//	bucket: 存储桶名称（为空时使用默认存储桶）
+//	stagingID: 暂存ID
+//
+// 返回值:
+//
+//	[]PublishResult: 每个对象的发布结果
+//	error: 暂存ID无效、暂存区为空或列举失败时的错误信息
+func (s *Service) Publish(ctx context.Context, bucket, stagingID string) ([]PublishResult, error) {
+	if !stagingIDPattern.MatchString(stagingID) {
+		return nil, ErrInvalidStagingID
+	}
+	if bucket == "" {
+		bucket = s.defaultBucket
+	}
+
+	prefix := s.stagingPrefix + stagingID + "/"
+	var stagedKeys []string
+	err := s.listAllObjects(ctx, bucket, prefix, func(obj types.Object) error {
+		stagedKeys = append(stagedKeys, aws.ToString(obj.Key))
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(stagedKeys) == 0 {
+		return nil, ErrStagingEmpty
+	}
+
+	// 第一阶段：复制全部对象
+	results := make([]PublishResult, len(stagedKeys))
+	forEachConcurrent(ctx, s.concurrency, len(stagedKeys), func(ctx context.Context, i int) {
+		key := strings.TrimPrefix(stagedKeys[i], prefix)
+		results[i].Key = key
+		if err := s.CopyObject(ctx, bucket, stagedKeys[i], bucket, key); err != nil {
+			results[i].Error = "Failed to copy staged object: " + err.Error()
+			return
+		}
+		results[i].Published = true
+	})
+
+	for _, result := range results {
+		if !result.Published {
+			return results, nil
+		}
+	}
+
+	// 第二阶段：全部复制成功后删除暂存副本
+	forEachConcurrent(ctx, s.concurrency, len(stagedKeys), func(ctx context.Context, i int) {
+		if err := s.DeleteFile(ctx, bucket, stagedKeys[i]); err != nil {
+			results[i].Error = "Failed to delete staged copy: " + err.Error()
+			return
+		}
+		results[i].Cleaned = true
+	})
+
+	return results, nil
+}
+
+// CopyObject 在S3内部复制对象（服务端复制，不经过本服务传输数据）
+// 参数:
+//
+//	ctx: 上下文
+//	srcBucket: 源存储桶名称（为空时使用默认存储桶）
+//	srcKey: 源文件键
+//	dstBucket: 目标存储桶名称（为空时使用默认存储桶）
+//	dstKey: 目标文件键
+//
+// 返回值:
+//
+//	error: 错误信息，源与目标相同时返回包装了ErrInvalidCopy的错误
+func (s *Service) CopyObject(ctx context.Context, srcBucket, srcKey, dstBucket, dstKey string) error {
+	if srcBucket == "" {
+		srcBucket = s.defaultBucket
+	}
+	if dstBucket == "" {
+		dstBucket = s.defaultBucket
+	}
+	// 空的存储桶名称解析为默认存储桶之后再比较，S3会拒绝不修改任何属性的自我复制
+	if srcBucket == dstBucket && srcKey == dstKey {
+		return fmt.Errorf("%w: source and destination must differ", ErrInvalidCopy)
+	}
+
+	_, err := s.client.CopyObject(ctx, &s3.CopyObjectInput{
+		Bucket:     aws.String(dstBucket),
+		Key:        aws.String(dstKey),
+		CopySource: aws.String(copySource(srcBucket, srcKey)),
+	})
+	if err != nil {
+		return err
+	}
+
+	if s.index != nil {
+		s.index.copy(srcBucket, srcKey, dstBucket, dstKey)
+	}
+
+	return nil
+}
+
+// copySource 构造URL编码后的CopySource（bucket/key）
+// 逐段编码以保留路径分隔符，空格编码为%20而不是+，避免部分后端将+解码为空格
+// 参数:
+//
+//	bucket: 存储桶名称
+//	key: 文件键
+//
+// 返回值:
+//
+//	string: CopySource值
+func copySource(bucket, key string) string {
+	return fmt.Sprintf("%s/%s", bucket, escapeKey(key))
+}
+
+// escapeKey 逐段URL编码文件键，保留路径分隔符，空格编码为%20
+// 参数:
+//
+//	key: 文件键
+//
+// 返回值:
+//
+//	string: 编码后的文件键
+func escapeKey(key string) string {
+	segments := strings.Split(key, "/")
+	for i, segment := range segments {
+		segments[i] = strings.ReplaceAll(url.QueryEscape(segment), "+", "%20")
+	}
+	return strings.Join(segments, "/")
+}
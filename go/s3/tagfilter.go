@@ -0,0 +1,88 @@
+// Package s3 提供S3服务相关功能
+// 作者: KO
+// 创建时间: 2026-10-15
+// 修改时间: 2026-10-15
+package s3
+
+import (
+	"context"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// ListFilesByTag 列举前缀下带有指定标签的文件
+// S3不能按标签列举，因此先列举前缀下的全部对象，再以worker_concurrency的并发为每个对象调用一次GetObjectTagging：
+// 请求数与前缀下的对象数成正比，大前缀下既慢又会产生相应的请求费用，应尽量用prefix缩小范围。
+// 标签不在元数据索引中，无法由索引加速。列举与获取标签之间被删除的对象直接跳过
+// 参数:
+//
+//	ctx: 上下文
+//	bucket: 存储桶名称（为空时使用默认存储桶）
+//	prefix: 键前缀（为空时列举全部对象）
+//	tagKey: 标签键
+//	tagValue: 标签值
+//	anyValue: 为true时只要求带有tagKey，忽略tagValue
+//
+// 返回值:
+//
+//	[]map[string]interface{}: 文件列表（按键排序）
+//	error: 错误信息
+func (s *Service) ListFilesByTag(ctx context.Context, bucket, prefix, tagKey, tagValue string, anyValue bool) ([]map[string]interface{}, error) {
+	if bucket == "" {
+		bucket = s.defaultBucket
+	}
+
+	var objects []types.Object
+	err := s.listAllObjects(ctx, bucket, prefix, func(obj types.Object) error {
+		if !isFolderMarker(obj) {
+			objects = append(objects, obj)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	matched := make([]bool, len(objects))
+	var mu sync.Mutex
+	var firstErr error
+	forEachConcurrent(ctx, s.concurrency, len(objects), func(ctx context.Context, i int) {
+		output, err := s.client.GetObjectTagging(ctx, &s3.GetObjectTaggingInput{
+			Bucket: aws.String(bucket),
+			Key:    objects[i].Key,
+		})
+		if err != nil {
+			if !IsNotFound(err) {
+				mu.Lock()
+				if firstErr == nil {
+					firstErr = err
+				}
+				mu.Unlock()
+			}
+			return
+		}
+		for _, tag := range output.TagSet {
+			if aws.ToString(tag.Key) == tagKey && (anyValue || aws.ToString(tag.Value) == tagValue) {
+				matched[i] = true
+				return
+			}
+		}
+	})
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if firstErr != nil {
+		return nil, firstErr
+	}
+
+	files := make([]map[string]interface{}, 0)
+	for i, obj := range objects {
+		if matched[i] {
+			files = append(files, fileEntry(obj))
+		}
+	}
+	return files, nil
+}
@@ -0,0 +1,118 @@
+// Package s3 提供S3服务相关功能
+// 作者: KO
+// 创建时间: 2026-10-15
+// 修改时间: 2026-10-15
+package s3
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"unicode/utf8"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// ErrInvalidTags 对象标签超出S3的数量或长度限制
+var ErrInvalidTags = errors.New("invalid tags")
+
+const (
+	maxObjectTags     = 10  // 每个对象最多的标签数
+	maxTagKeyLength   = 128 // 标签键的最大长度（Unicode字符）
+	maxTagValueLength = 256 // 标签值的最大长度（Unicode字符）
+)
+
+// validateTags 检查对象标签是否符合S3的限制：最多10个，键1到128个字符，值最多256个字符
+// 参数:
+//
+//	tags: 对象标签
+//
+// 返回值:
+//
+//	error: 不符合时返回包装了ErrInvalidTags的错误
+func validateTags(tags map[string]string) error {
+	if len(tags) > maxObjectTags {
+		return fmt.Errorf("%w: %d tags exceeds the limit of %d", ErrInvalidTags, len(tags), maxObjectTags)
+	}
+	for key, value := range tags {
+		if n := utf8.RuneCountInString(key); n == 0 || n > maxTagKeyLength {
+			return fmt.Errorf("%w: key %q must be 1 to %d characters", ErrInvalidTags, key, maxTagKeyLength)
+		}
+		if utf8.RuneCountInString(value) > maxTagValueLength {
+			return fmt.Errorf("%w: value of %q exceeds %d characters", ErrInvalidTags, key, maxTagValueLength)
+		}
+	}
+	return nil
+}
+
+// GetObjectTags 获取对象标签
+// 参数:
+//
+//	ctx: 上下文
+//	bucket: 存储桶名称（为空时使用默认存储桶）
+//	key: 文件键
+//
+// 返回值:
+//
+//	map[string]string: 对象标签（没有时为空map）
+//	error: 错误信息，对象不存在时可用IsNotFound判断
+func (s *Service) GetObjectTags(ctx context.Context, bucket, key string) (map[string]string, error) {
+	if bucket == "" {
+		bucket = s.defaultBucket
+	}
+
+	output, err := s.client.GetObjectTagging(ctx, &s3.GetObjectTaggingInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	tags := make(map[string]string, len(output.TagSet))
+	for _, tag := range output.TagSet {
+		tags[aws.ToString(tag.Key)] = aws.ToString(tag.Value)
+	}
+	return tags, nil
+}
+
+// SetObjectTags 替换对象的全部标签，tags为空时清除标签
+// 参数:
+//
+//	ctx: 上下文
+//	bucket: 存储桶名称（为空时使用默认存储桶）
+//	key: 文件键
+//	tags: 对象标签
+//
+// 返回值:
+//
+//	error: 标签不符合限制时返回包装了ErrInvalidTags的错误
+func (s *Service) SetObjectTags(ctx context.Context, bucket, key string, tags map[string]string) error {
+	if bucket == "" {
+		bucket = s.defaultBucket
+	}
+	if err := validateTags(tags); err != nil {
+		return err
+	}
+
+	// 按键排序，使请求内容稳定
+	keys := make([]string, 0, len(tags))
+	for k := range tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	tagSet := make([]types.Tag, 0, len(keys))
+	for _, k := range keys {
+		tagSet = append(tagSet, types.Tag{Key: aws.String(k), Value: aws.String(tags[k])})
+	}
+
+	_, err := s.client.PutObjectTagging(ctx, &s3.PutObjectTaggingInput{
+		Bucket:  aws.String(bucket),
+		Key:     aws.String(key),
+		Tagging: &types.Tagging{TagSet: tagSet},
+	})
+	return err
+}
@@ -0,0 +1,257 @@
+// Package s3 提供S3服务相关功能
+// 作者: KO
+// 创建时间: 2026-10-15
+// 修改时间: 2026-10-15
+package s3
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"image/draw"
+	_ "image/gif" // 注册gif解码器
+	"image/jpeg"
+	"image/png"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+var (
+	// ErrNotImage 对象不是支持的图片格式（jpeg、png、gif）
+	ErrNotImage = errors.New("object is not a supported image")
+	// ErrInvalidThumbnailWidth 缩略图宽度无效或超过上限
+	ErrInvalidThumbnailWidth = errors.New("invalid thumbnail width")
+)
+
+// thumbnailSourceETagKey 缩略图对象中记录源对象ETag的元数据键，用于判断缓存是否过期
+const thumbnailSourceETagKey = "source-etag"
+
+// Thumbnail 缩略图
+type Thumbnail struct {
+	Data        []byte // 缩略图内容
+	ContentType string // 缩略图的内容类型
+}
+
+// Thumbnail 生成图片对象的缩略图，保持宽高比缩放到指定宽度（不放大）
+// 生成的缩略图缓存在<thumbnail_prefix><宽度>/<文件键>下，并记录源对象的ETag；
+// 源对象未变化时直接返回缓存，否则重新生成并覆盖缓存。jpeg输出为jpeg，png和gif输出为png
+// 参数:
+//
+//	ctx: 上下文
+//	bucket: 存储桶名称（为空时使用默认存储桶）
+//	key: 文件键
+//	width: 缩略图宽度（像素）
+//
+// 返回值:
+//
+//	*Thumbnail: 缩略图
+//	error: 错误信息
+func (s *Service) Thumbnail(ctx context.Context, bucket, key string, width int) (*Thumbnail, error) {
+	if bucket == "" {
+		bucket = s.defaultBucket
+	}
+	if width <= 0 || width > s.cfg.ThumbnailMaxWidth {
+		return nil, fmt.Errorf("%w: must be between 1 and %d", ErrInvalidThumbnailWidth, s.cfg.ThumbnailMaxWidth)
+	}
+
+	info, err := s.StatObject(ctx, bucket, key)
+	if err != nil {
+		return nil, err
+	}
+	// 已声明内容类型的非图片对象无需下载即可拒绝
+	if info.ContentType != "" && !strings.HasPrefix(info.ContentType, "image/") {
+		return nil, fmt.Errorf("%w: content type %s", ErrNotImage, info.ContentType)
+	}
+
+	thumbKey := s.cfg.ThumbnailPrefix + strconv.Itoa(width) + "/" + info.Key
+	cached, err := s.cachedThumbnail(ctx, bucket, thumbKey, info.ETag)
+	if err != nil {
+		return nil, err
+	}
+	if cached != nil {
+		return cached, nil
+	}
+
+	// 源图片需要完整读入内存解码，先按大小拒绝，避免任意标记为image/*的大对象耗尽内存
+	maxBytes := s.cfg.ThumbnailMaxSourceBytes
+	if info.Size > maxBytes {
+		return nil, fmt.Errorf("%w: %d bytes exceeds the maximum of %d bytes", ErrNotImage, info.Size, maxBytes)
+	}
+
+	output, err := s.client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(info.Key),
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer output.Body.Close()
+
+	// 对象可能在HEAD之后被替换，读取时同样限制大小
+	source, err := io.ReadAll(io.LimitReader(output.Body, maxBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(source)) > maxBytes {
+		return nil, fmt.Errorf("%w: object exceeds the maximum of %d bytes", ErrNotImage, maxBytes)
+	}
+
+	thumb, err := s.renderThumbnail(source, width)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.UploadFile(ctx, bucket, thumbKey, thumb.Data, UploadOptions{
+		ContentType: thumb.ContentType,
+		Metadata:    map[string]string{thumbnailSourceETagKey: info.ETag},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return thumb, nil
+}
+
+// cachedThumbnail 读取缓存的缩略图，源对象ETag不一致时视为不存在
+// 参数:
+//
+//	ctx: 上下文
+//	bucket: 存储桶名称
+//	thumbKey: 缩略图的键
+//	sourceETag: 源对象当前的ETag
+//
+// 返回值:
+//
+//	*Thumbnail: 缓存的缩略图（缓存不存在或已过期时为nil）
+//	error: 错误信息
+func (s *Service) cachedThumbnail(ctx context.Context, bucket, thumbKey, sourceETag string) (*Thumbnail, error) {
+	output, err := s.client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(thumbKey),
+	})
+	if err != nil {
+		if IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer output.Body.Close()
+
+	if output.Metadata[thumbnailSourceETagKey] != sourceETag {
+		return nil, nil
+	}
+
+	data, err := io.ReadAll(output.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Thumbnail{Data: data, ContentType: aws.ToString(output.ContentType)}, nil
+}
+
+// renderThumbnail 解码图片并缩放编码为缩略图
+// 参数:
+//
+//	source: 源图片内容
+//	width: 缩略图宽度
+//
+// 返回值:
+//
+//	*Thumbnail: 缩略图
+//	error: 不是支持的图片或图片尺寸超过上限时返回错误
+func (s *Service) renderThumbnail(source []byte, width int) (*Thumbnail, error) {
+	// 先只读取尺寸，避免解码超大图片耗尽内存
+	cfg, format, err := image.DecodeConfig(bytes.NewReader(source))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrNotImage, err)
+	}
+	if int64(cfg.Width)*int64(cfg.Height) > s.cfg.ThumbnailMaxSourcePixels {
+		return nil, fmt.Errorf("%w: %dx%d exceeds the maximum of %d pixels", ErrNotImage, cfg.Width, cfg.Height, s.cfg.ThumbnailMaxSourcePixels)
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(source))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrNotImage, err)
+	}
+
+	resized := resizeImage(img, width)
+
+	var buf bytes.Buffer
+	if format == "jpeg" {
+		if err := jpeg.Encode(&buf, resized, &jpeg.Options{Quality: 85}); err != nil {
+			return nil, err
+		}
+		return &Thumbnail{Data: buf.Bytes(), ContentType: "image/jpeg"}, nil
+	}
+
+	if err := png.Encode(&buf, resized); err != nil {
+		return nil, err
+	}
+	return &Thumbnail{Data: buf.Bytes(), ContentType: "image/png"}, nil
+}
+
+// resizeImage 以区域平均的方式将图片缩小到指定宽度，保持宽高比；不放大更窄的图片
+// 参数:
+//
+//	img: 源图片
+//	width: 目标宽度
+//
+// 返回值:
+//
+//	image.Image: 缩放后的图片
+func resizeImage(img image.Image, width int) image.Image {
+	bounds := img.Bounds()
+	srcW, srcH := bounds.Dx(), bounds.Dy()
+
+	src := image.NewRGBA(image.Rect(0, 0, srcW, srcH))
+	draw.Draw(src, src.Bounds(), img, bounds.Min, draw.Src)
+	if width >= srcW || srcW == 0 || srcH == 0 {
+		return src
+	}
+
+	height := srcH * width / srcW
+	if height < 1 {
+		height = 1
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	for dy := 0; dy < height; dy++ {
+		y0, y1 := dy*srcH/height, (dy+1)*srcH/height
+		if y1 == y0 {
+			y1 = y0 + 1
+		}
+		for dx := 0; dx < width; dx++ {
+			x0, x1 := dx*srcW/width, (dx+1)*srcW/width
+			if x1 == x0 {
+				x1 = x0 + 1
+			}
+
+			var r, g, b, a, n int
+			for y := y0; y < y1; y++ {
+				row := src.Pix[y*src.Stride:]
+				for x := x0; x < x1; x++ {
+					p := row[x*4 : x*4+4]
+					r += int(p[0])
+					g += int(p[1])
+					b += int(p[2])
+					a += int(p[3])
+					n++
+				}
+			}
+
+			d := dst.Pix[dy*dst.Stride+dx*4:]
+			d[0] = uint8(r / n)
+			d[1] = uint8(g / n)
+			d[2] = uint8(b / n)
+			d[3] = uint8(a / n)
+		}
+	}
+
+	return dst
+}
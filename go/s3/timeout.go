@@ -0,0 +1,77 @@
+// Package s3 提供S3服务相关功能
+// 作者: KO
+// 创建时间: 2026-10-15
+// 修改时间: 2026-10-15
+package s3
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"time"
+
+	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/smithy-go/middleware"
+	"github.com/example/s3service/config"
+)
+
+// newHTTPClient 创建带超时的HTTP客户端，供S3和STS客户端使用
+// connect_timeout限制建立TCP连接和TLS握手的时间；request_timeout限制请求发出后等待响应头的时间。
+// 没有使用http.Client.Timeout：它包含读取响应体的时间，会中断大文件的流式下载
+// 参数:
+//
+//	cfg: S3配置信息
+//
+// 返回值:
+//
+//	*awshttp.BuildableClient: HTTP客户端
+func newHTTPClient(cfg *config.S3Config) *awshttp.BuildableClient {
+	return awshttp.NewBuildableClient().
+		WithDialerOptions(func(d *net.Dialer) {
+			if cfg.ConnectTimeout > 0 {
+				d.Timeout = cfg.ConnectTimeout
+			}
+		}).
+		WithTransportOptions(func(t *http.Transport) {
+			if cfg.ConnectTimeout > 0 {
+				t.TLSHandshakeTimeout = cfg.ConnectTimeout
+			}
+			if cfg.RequestTimeout > 0 {
+				t.ResponseHeaderTimeout = cfg.RequestTimeout
+			}
+		})
+}
+
+// operationTimeout 返回为每次S3操作设置超时的SDK中间件
+// 超时覆盖整个操作，包括重试、并发限制的排队和不可变窗口检查。传输对象内容的操作
+// （GetObject、PutObject、UploadPart、CopyObject、UploadPartCopy）和合并分段的CompleteMultipartUpload
+// 耗时与对象大小成正比，不设整体超时，
+// 只受HTTP客户端的连接和响应头超时限制。预签名请求的中间件栈中没有Retry中间件，此时不挂载
+// 参数:
+//
+//	timeout: 单次操作的超时时间
+//
+// 返回值:
+//
+//	func(*middleware.Stack) error: 用于s3.Options.APIOptions的中间件注册函数
+func operationTimeout(timeout time.Duration) func(*middleware.Stack) error {
+	return func(stack *middleware.Stack) error {
+		if _, ok := stack.Finalize.Get("Retry"); !ok {
+			return nil
+		}
+
+		return stack.Initialize.Add(middleware.InitializeMiddlewareFunc("OperationTimeout",
+			func(ctx context.Context, in middleware.InitializeInput, next middleware.InitializeHandler) (middleware.InitializeOutput, middleware.Metadata, error) {
+				switch in.Parameters.(type) {
+				case *s3.GetObjectInput, *s3.PutObjectInput, *s3.UploadPartInput, *s3.CopyObjectInput, *s3.UploadPartCopyInput,
+					*s3.CompleteMultipartUploadInput:
+					return next.HandleInitialize(ctx, in)
+				}
+
+				ctx, cancel := context.WithTimeout(ctx, timeout)
+				defer cancel()
+				return next.HandleInitialize(ctx, in)
+			}), middleware.Before)
+	}
+}
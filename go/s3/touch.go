@@ -0,0 +1,74 @@
+// Package s3 提供S3服务相关功能
+// 作者: KO
+// 创建时间: 2026-10-15
+// 修改时间: 2026-10-15
+package s3
+
+import (
+	"context"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// touchedAtKey 记录touch时间的用户元数据键（x-amz-meta-touched-at）
+const touchedAtKey = "touched-at"
+
+// Touch 在不改变内容的情况下更新对象的最后修改时间
+// 通过REPLACE方式的自我复制实现，并沿用HEAD读到的内容类型和元数据；
+// S3要求同键复制必须有所改变，因此总是写入touched-at元数据
+// 参数:
+//
+//	ctx: 上下文
+//	bucket: 存储桶名称（为空时使用默认存储桶）
+//	key: 文件键
+//
+// 返回值:
+//
+//	*time.Time: 新的最后修改时间
+//	error: 错误信息
+func (s *Service) Touch(ctx context.Context, bucket, key string) (*time.Time, error) {
+	if bucket == "" {
+		bucket = s.defaultBucket
+	}
+
+	key, err := s.resolveKey(ctx, bucket, key)
+	if err != nil {
+		return nil, err
+	}
+
+	head, err := s.client.HeadObject(ctx, &s3.HeadObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	metadata := make(map[string]string, len(head.Metadata)+1)
+	for name, value := range head.Metadata {
+		metadata[name] = value
+	}
+	metadata[touchedAtKey] = time.Now().UTC().Format(time.RFC3339Nano)
+
+	output, err := s.replaceMetadata(ctx, bucket, key, head, metadata, head.CacheControl)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.index != nil {
+		s.index.put(bucket, key, metadata)
+	}
+
+	if output.CopyObjectResult != nil && output.CopyObjectResult.LastModified != nil {
+		return output.CopyObjectResult.LastModified, nil
+	}
+
+	// 部分兼容实现不返回复制结果，改为重新读取
+	info, err := s.StatObject(ctx, bucket, key)
+	if err != nil {
+		return nil, err
+	}
+	return info.LastModified, nil
+}
@@ -0,0 +1,361 @@
+// Package s3 提供S3服务相关功能
+// 作者: KO
+// 创建时间: 2026-10-15
+// 修改时间: 2026-10-15
+package s3
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"os"
+	"strings"
+	"sync"
+)
+
+// Transform 上传时对内容进行的转换
+// Apply从r读取原始内容，将转换后的内容写入w；实现应尽量边读边写，不在内存中缓冲整个文件
+type Transform interface {
+	Apply(w io.Writer, r io.Reader) error
+}
+
+// TransformFunc 以函数实现Transform
+type TransformFunc func(w io.Writer, r io.Reader) error
+
+// Apply 执行转换
+// 参数:
+//
+//	w: 转换后内容的写入目标
+//	r: 原始内容
+//
+// 返回值:
+//
+//	error: 错误信息
+func (f TransformFunc) Apply(w io.Writer, r io.Reader) error {
+	return f(w, r)
+}
+
+var (
+	transformsMu sync.RWMutex
+	// transforms 已注册的转换，按名称索引
+	transforms = map[string]Transform{
+		"strip-exif":  TransformFunc(stripEXIF),
+		"minify-json": TransformFunc(minifyJSON),
+	}
+)
+
+// RegisterTransform 注册上传转换，注册后即可在upload_transforms配置中按名称引用
+// 需要在创建Service之前调用
+// 参数:
+//
+//	name: 转换名称
+//	t: 转换实现
+func RegisterTransform(name string, t Transform) {
+	transformsMu.Lock()
+	defer transformsMu.Unlock()
+	transforms[name] = t
+}
+
+// lookupTransform 按名称查找已注册的转换
+// 参数:
+//
+//	name: 转换名称
+//
+// 返回值:
+//
+//	Transform: 转换实现
+//	bool: 是否存在
+func lookupTransform(name string) (Transform, bool) {
+	transformsMu.RLock()
+	defer transformsMu.RUnlock()
+	t, ok := transforms[name]
+	return t, ok
+}
+
+// validateTransforms 检查配置中引用的转换是否都已注册
+// 参数:
+//
+//	config: 内容类型到转换名称列表的映射
+//
+// 返回值:
+//
+//	error: 存在未注册的转换时返回错误
+func validateTransforms(config map[string][]string) error {
+	for contentType, names := range config {
+		for _, name := range names {
+			if _, ok := lookupTransform(name); !ok {
+				return fmt.Errorf("unknown upload transform %q for %s", name, contentType)
+			}
+		}
+	}
+	return nil
+}
+
+// transformsFor 返回内容类型对应的转换列表（未启用转换时为空）
+// 参数:
+//
+//	contentType: 内容类型
+//
+// 返回值:
+//
+//	[]Transform: 依次执行的转换
+func (s *Service) transformsFor(contentType string) []Transform {
+	if !s.cfg.UploadTransformsEnabled || contentType == "" {
+		return nil
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return nil
+	}
+
+	var chain []Transform
+	for _, name := range s.cfg.UploadTransforms[strings.ToLower(mediaType)] {
+		if t, ok := lookupTransform(name); ok {
+			chain = append(chain, t)
+		}
+	}
+	return chain
+}
+
+// applyTransforms 依次执行转换，并将结果写入临时文件以获得转换后的大小
+// 转换之间通过管道串联，整个过程不在内存中缓冲文件
+// 参数:
+//
+//	chain: 转换列表
+//	body: 原始内容
+//
+// 返回值:
+//
+//	*os.File: 转换后内容所在的临时文件（已定位到开头），调用方负责关闭并删除
+//	int64: 转换后的大小
+//	error: 错误信息
+func applyTransforms(chain []Transform, body io.Reader) (*os.File, int64, error) {
+	reader := body
+	pipes := make([]*io.PipeReader, 0, len(chain))
+	for _, t := range chain {
+		pr, pw := io.Pipe()
+		go func(t Transform, r io.Reader) {
+			pw.CloseWithError(t.Apply(pw, r))
+		}(t, reader)
+		reader = pr
+		pipes = append(pipes, pr)
+	}
+	// 出错时关闭所有管道的读端：后面的转换失败后不再读取前一个管道，
+	// 前面的转换会一直阻塞在写入上，关闭后写入立即返回错误，协程得以退出
+	closePipes := func(err error) {
+		for _, pr := range pipes {
+			pr.CloseWithError(err)
+		}
+	}
+	// 成功时也关闭，避免提前结束读取的转换让前面的转换阻塞（已关闭的管道保留第一次的错误）
+	defer closePipes(nil)
+
+	spill, err := os.CreateTemp("", "transform-*")
+	if err != nil {
+		closePipes(err)
+		return nil, 0, err
+	}
+	cleanup := func() {
+		spill.Close()
+		os.Remove(spill.Name())
+	}
+
+	size, err := io.Copy(spill, reader)
+	if err != nil {
+		closePipes(err)
+		cleanup()
+		return nil, 0, err
+	}
+	if _, err := spill.Seek(0, io.SeekStart); err != nil {
+		cleanup()
+		return nil, 0, err
+	}
+
+	return spill, size, nil
+}
+
+// stripEXIF 去除JPEG的Exif（APP1）段或PNG的eXIf块，其余内容原样流式复制
+// 不是JPEG或PNG的内容原样输出
+// 参数:
+//
+//	w: 输出
+//	r: 输入
+//
+// 返回值:
+//
+//	error: 错误信息
+func stripEXIF(w io.Writer, r io.Reader) error {
+	br := bufio.NewReader(r)
+	head, err := br.Peek(8)
+	if err != nil && err != io.EOF {
+		return err
+	}
+
+	switch {
+	case bytes.HasPrefix(head, []byte{0xFF, 0xD8}):
+		return stripJPEGExif(w, br)
+	case bytes.Equal(head, []byte("\x89PNG\r\n\x1a\n")):
+		return stripPNGExif(w, br)
+	default:
+		_, err := io.Copy(w, br)
+		return err
+	}
+}
+
+// stripJPEGExif 去除JPEG中的Exif APP1段
+// 逐段复制到扫描数据（SOS）为止，之后的压缩数据原样复制
+// 参数:
+//
+//	w: 输出
+//	br: 以SOI开头的JPEG输入
+//
+// 返回值:
+//
+//	error: 错误信息
+func stripJPEGExif(w io.Writer, br *bufio.Reader) error {
+	soi := make([]byte, 2)
+	if _, err := io.ReadFull(br, soi); err != nil {
+		return err
+	}
+	if _, err := w.Write(soi); err != nil {
+		return err
+	}
+
+	for {
+		marker := make([]byte, 2)
+		if _, err := io.ReadFull(br, marker); err != nil {
+			return err
+		}
+		if marker[0] != 0xFF {
+			return errors.New("invalid JPEG segment marker")
+		}
+
+		// 独立标记（如EOI、RSTn）没有长度字段
+		if marker[1] == 0xD9 || (marker[1] >= 0xD0 && marker[1] <= 0xD7) || marker[1] == 0x01 {
+			if _, err := w.Write(marker); err != nil {
+				return err
+			}
+			if marker[1] == 0xD9 {
+				_, err := io.Copy(w, br)
+				return err
+			}
+			continue
+		}
+
+		lengthBytes := make([]byte, 2)
+		if _, err := io.ReadFull(br, lengthBytes); err != nil {
+			return err
+		}
+		length := int64(binary.BigEndian.Uint16(lengthBytes))
+		if length < 2 {
+			return errors.New("invalid JPEG segment length")
+		}
+
+		if marker[1] == 0xE1 {
+			payload, err := br.Peek(6)
+			if err == nil && bytes.Equal(payload, []byte("Exif\x00\x00")) {
+				if _, err := br.Discard(int(length - 2)); err != nil {
+					return err
+				}
+				continue
+			}
+		}
+
+		if _, err := w.Write(marker); err != nil {
+			return err
+		}
+		if _, err := w.Write(lengthBytes); err != nil {
+			return err
+		}
+		if _, err := io.CopyN(w, br, length-2); err != nil {
+			return err
+		}
+
+		// SOS之后是熵编码数据，直接复制剩余内容
+		if marker[1] == 0xDA {
+			_, err := io.Copy(w, br)
+			return err
+		}
+	}
+}
+
+// stripPNGExif 去除PNG中的eXIf块，其他块原样复制
+// 参数:
+//
+//	w: 输出
+//	br: 以PNG签名开头的输入
+//
+// 返回值:
+//
+//	error: 错误信息
+func stripPNGExif(w io.Writer, br *bufio.Reader) error {
+	if _, err := io.CopyN(w, br, 8); err != nil {
+		return err
+	}
+
+	header := make([]byte, 8)
+	for {
+		if _, err := io.ReadFull(br, header); err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+		// 块长度不含类型和CRC
+		length := int64(binary.BigEndian.Uint32(header[:4])) + 4
+		if string(header[4:8]) == "eXIf" {
+			if _, err := io.CopyN(io.Discard, br, length); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if _, err := w.Write(header); err != nil {
+			return err
+		}
+		if _, err := io.CopyN(w, br, length); err != nil {
+			return err
+		}
+	}
+}
+
+// minifyJSON 去除JSON中的空白
+// 依次读取输入中的每个JSON值（支持NDJSON等多个值首尾相连的输入），每个值单独压缩；
+// 单个值需要完整读入内存
+// 参数:
+//
+//	w: 输出
+//	r: 输入
+//
+// 返回值:
+//
+//	error: 输入不是合法JSON时返回错误
+func minifyJSON(w io.Writer, r io.Reader) error {
+	dec := json.NewDecoder(r)
+	var buf bytes.Buffer
+	for first := true; ; first = false {
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+
+		buf.Reset()
+		if !first {
+			buf.WriteByte('\n')
+		}
+		if err := json.Compact(&buf, raw); err != nil {
+			return err
+		}
+		if _, err := w.Write(buf.Bytes()); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,219 @@
+// Package s3 提供S3服务相关功能
+// 作者: KO
+// 创建时间: 2026-10-15
+// 修改时间: 2026-10-15
+package s3
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// ObjectVersion 对象的一个版本
+type ObjectVersion struct {
+	VersionID      string     `json:"versionId"`      // 版本ID（未启用版本控制时为null）
+	Size           int64      `json:"size"`           // 文件大小（删除标记为0）
+	LastModified   *time.Time `json:"lastModified"`   // 最后修改时间
+	IsLatest       bool       `json:"isLatest"`       // 是否为最新版本
+	IsDeleteMarker bool       `json:"isDeleteMarker"` // 是否为删除标记
+}
+
+// VersionedFile 带全部版本的文件
+type VersionedFile struct {
+	Key      string          `json:"key"`      // 文件键
+	Versions []ObjectVersion `json:"versions"` // 版本列表（从新到旧）
+}
+
+// ListFileVersions 分页列举前缀下全部对象的版本（包括删除标记），按键分组
+// 参数:
+//
+//	ctx: 上下文
+//	bucket: 存储桶名称（为空时使用默认存储桶）
+//	prefix: 键前缀（为空时列举全部对象）
+//
+// 返回值:
+//
+//	[]VersionedFile: 按键排序的文件及其版本
+//	error: 错误信息
+func (s *Service) ListFileVersions(ctx context.Context, bucket, prefix string) ([]VersionedFile, error) {
+	if bucket == "" {
+		bucket = s.defaultBucket
+	}
+
+	input := &s3.ListObjectVersionsInput{
+		Bucket: aws.String(bucket),
+	}
+	if prefix != "" {
+		input.Prefix = aws.String(prefix)
+	}
+
+	files := make([]VersionedFile, 0)
+	index := make(map[string]int)
+	add := func(key string, version ObjectVersion) {
+		i, ok := index[key]
+		if !ok {
+			i = len(files)
+			index[key] = i
+			files = append(files, VersionedFile{Key: key})
+		}
+		files[i].Versions = append(files[i].Versions, version)
+	}
+
+	for {
+		output, err := s.client.ListObjectVersions(ctx, input)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, v := range output.Versions {
+			add(aws.ToString(v.Key), ObjectVersion{
+				VersionID:    aws.ToString(v.VersionId),
+				Size:         aws.ToInt64(v.Size),
+				LastModified: v.LastModified,
+				IsLatest:     aws.ToBool(v.IsLatest),
+			})
+		}
+		for _, m := range output.DeleteMarkers {
+			add(aws.ToString(m.Key), ObjectVersion{
+				VersionID:      aws.ToString(m.VersionId),
+				LastModified:   m.LastModified,
+				IsLatest:       aws.ToBool(m.IsLatest),
+				IsDeleteMarker: true,
+			})
+		}
+
+		if !aws.ToBool(output.IsTruncated) {
+			break
+		}
+		input.KeyMarker = output.NextKeyMarker
+		input.VersionIdMarker = output.NextVersionIdMarker
+	}
+
+	// 版本和删除标记在响应中分开返回，合并后重新按键及时间（从新到旧）排序
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].Key < files[j].Key
+	})
+	for _, file := range files {
+		sort.SliceStable(file.Versions, func(i, j int) bool {
+			a, b := file.Versions[i].LastModified, file.Versions[j].LastModified
+			return a != nil && b != nil && a.After(*b)
+		})
+	}
+
+	return files, nil
+}
+
+// DownloadVersionStream 以流的方式下载文件的指定版本，不在内存中缓冲整个对象
+// 调用方必须关闭返回的io.ReadCloser（包括中途出错或客户端断开时）
+// 参数:
+//
+//	ctx: 上下文
+//	bucket: 存储桶名称（为空时使用默认存储桶）
+//	key: 文件键
+//	versionID: 版本ID
+//
+// 返回值:
+//
+//	io.ReadCloser: 该版本的内容
+//	int64: 内容长度（字节）
+//	string: 该版本的内容类型（S3未返回时为空）
+//	error: 错误信息，该版本是客户端加密的时返回ErrEncryptionKeyRequired
+func (s *Service) DownloadVersionStream(ctx context.Context, bucket, key, versionID string) (io.ReadCloser, int64, string, error) {
+	if bucket == "" {
+		bucket = s.defaultBucket
+	}
+
+	output, err := s.getObjectVersion(ctx, bucket, key, versionID)
+	if err != nil {
+		return nil, 0, "", err
+	}
+
+	if output.Metadata[MetaClientEncryption] != "" {
+		output.Body.Close()
+		return nil, 0, "", ErrEncryptionKeyRequired
+	}
+
+	return output.Body, aws.ToInt64(output.ContentLength), aws.ToString(output.ContentType), nil
+}
+
+// getObjectVersion 下载文件的指定版本
+// 参数:
+//
+//	ctx: 上下文
+//	bucket: 存储桶名称
+//	key: 文件键
+//	versionID: 版本ID
+//
+// 返回值:
+//
+//	*s3.GetObjectOutput: S3的响应，调用方负责关闭Body
+//	error: 错误信息
+func (s *Service) getObjectVersion(ctx context.Context, bucket, key, versionID string) (*s3.GetObjectOutput, error) {
+	return s.client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket:    aws.String(bucket),
+		Key:       aws.String(key),
+		VersionId: aws.String(versionID),
+	})
+}
+
+// DeleteFileVersion 永久删除文件的指定版本（或删除标记）
+// 参数:
+//
+//	ctx: 上下文
+//	bucket: 存储桶名称（为空时使用默认存储桶）
+//	key: 文件键
+//	versionID: 版本ID
+//
+// 返回值:
+//
+//	error: 错误信息
+func (s *Service) DeleteFileVersion(ctx context.Context, bucket, key, versionID string) error {
+	if bucket == "" {
+		bucket = s.defaultBucket
+	}
+
+	_, err := s.client.DeleteObject(ctx, &s3.DeleteObjectInput{
+		Bucket:    aws.String(bucket),
+		Key:       aws.String(key),
+		VersionId: aws.String(versionID),
+	})
+	return err
+}
+
+// FileVersionExists 检查文件的指定版本是否存在
+// 参数:
+//
+//	ctx: 上下文
+//	bucket: 存储桶名称（为空时使用默认存储桶）
+//	key: 文件键
+//	versionID: 版本ID
+//
+// 返回值:
+//
+//	bool: 版本是否存在（版本不存在或是删除标记时为false）
+//	error: 无法确定是否存在时的错误信息
+func (s *Service) FileVersionExists(ctx context.Context, bucket, key, versionID string) (bool, error) {
+	if bucket == "" {
+		bucket = s.defaultBucket
+	}
+
+	_, err := s.client.HeadObject(ctx, &s3.HeadObjectInput{
+		Bucket:    aws.String(bucket),
+		Key:       aws.String(key),
+		VersionId: aws.String(versionID),
+	})
+	// 指定的版本是删除标记时HeadObject返回405
+	var respErr *awshttp.ResponseError
+	if IsNotFound(err) || (errors.As(err, &respErr) && respErr.HTTPStatusCode() == http.StatusMethodNotAllowed) {
+		return false, nil
+	}
+	return err == nil, err
+}
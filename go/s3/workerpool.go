@@ -0,0 +1,43 @@
+// Package s3 提供S3服务相关功能
+// 作者: KO
+// 创建时间: 2026-10-15
+// 修改时间: 2026-10-15
+package s3
+
+import (
+	"context"
+	"sync"
+)
+
+// forEachConcurrent 以有限并发对[0, n)中的每个下标执行fn
+// 上下文取消后不再启动新的任务，但会等待已启动的任务结束
+// 参数:
+//
+//	ctx: 上下文
+//	concurrency: 最大并发数（小于1时按1处理）
+//	n: 任务数量
+//	fn: 任务函数
+func forEachConcurrent(ctx context.Context, concurrency, n int, fn func(ctx context.Context, i int)) {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		select {
+		case <-ctx.Done():
+			wg.Wait()
+			return
+		case sem <- struct{}{}:
+		}
+
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			fn(ctx, i)
+		}(i)
+	}
+	wg.Wait()
+}